@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BacktestConfig configures the simulated account.
+type BacktestConfig struct {
+	InitialCash    float64
+	Commission     CommissionModel
+	SlippageBps    float64 // slippage in basis points of the bar price
+	PeriodsPerYear float64 // bars per year for annualising, defaults to 252
+}
+
+// EquityPoint is the account value at the close of a bar.
+type EquityPoint struct {
+	Date   time.Time
+	Equity float64
+}
+
+// BacktestMetrics summarises a backtest.
+type BacktestMetrics struct {
+	TotalReturn     float64
+	CAGR            float64
+	MaxDrawdown     float64 // largest peak-to-trough loss as a fraction of the peak
+	Sharpe          float64 // annualised, zero risk-free rate
+	WinRate         float64
+	Trades          int
+	TotalCommission float64
+	TotalSlippage   float64
+}
+
+// BacktestReport is the outcome of running a strategy over a price series.
+type BacktestReport struct {
+	Strategy    string
+	EquityCurve []EquityPoint
+	Fills       []Fill
+	Rejections  []Rejection
+	Trades      []Trade // closed round trips only
+	FinalCash   float64
+	Position    float64 // shares still held at the end
+	Metrics     BacktestMetrics
+}
+
+// RunBacktest feeds prices to strategy one bar at a time. Orders placed
+// on the last bar are never filled.
+func RunBacktest(ctx context.Context, prices []StockPrice, strategy Strategy, cfg BacktestConfig) (*BacktestReport, error) {
+	if len(prices) == 0 {
+		return nil, errors.New("backtest: no prices")
+	}
+	if cfg.PeriodsPerYear <= 0 {
+		cfg.PeriodsPerYear = 252
+	}
+
+	broker := NewBroker(cfg.InitialCash, cfg.Commission, cfg.SlippageBps)
+	curve := make([]EquityPoint, 0, len(prices))
+	for i, bar := range prices {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		if i > 0 && !bar.Date.After(prices[i-1].Date) {
+			return nil, fmt.Errorf("backtest: bar %d at %s is not after %s", i, bar.Date.Format(time.DateOnly), prices[i-1].Date.Format(time.DateOnly))
+		}
+		broker.processBar(bar)
+		strategy.OnBar(ctx, bar, broker)
+		curve = append(curve, EquityPoint{Date: bar.Date, Equity: broker.Equity()})
+	}
+
+	report := &BacktestReport{
+		Strategy:    strategy.Name(),
+		EquityCurve: curve,
+		Fills:       broker.fills,
+		Rejections:  broker.rejected,
+		Trades:      broker.trades,
+		FinalCash:   broker.cash,
+		Position:    broker.position,
+	}
+	report.Metrics = computeMetrics(cfg, curve, broker.fills, broker.trades)
+	return report, nil
+}
+
+func computeMetrics(cfg BacktestConfig, curve []EquityPoint, fills []Fill, trades []Trade) BacktestMetrics {
+	var m BacktestMetrics
+	first, last := curve[0], curve[len(curve)-1]
+	if cfg.InitialCash > 0 {
+		m.TotalReturn = last.Equity/cfg.InitialCash - 1
+		years := last.Date.Sub(first.Date).Hours() / 24 / 365.25
+		if years > 0 && last.Equity > 0 {
+			m.CAGR = math.Pow(last.Equity/cfg.InitialCash, 1/years) - 1
+		}
+	}
+
+	peak := curve[0].Equity
+	for _, p := range curve {
+		peak = max(peak, p.Equity)
+		if peak > 0 {
+			m.MaxDrawdown = max(m.MaxDrawdown, (peak-p.Equity)/peak)
+		}
+	}
+
+	if len(curve) > 2 {
+		returns := make([]float64, 0, len(curve)-1)
+		for i := 1; i < len(curve); i++ {
+			if prev := curve[i-1].Equity; prev != 0 {
+				returns = append(returns, curve[i].Equity/prev-1)
+			}
+		}
+		mean, std := meanStd(returns)
+		if std > 0 {
+			m.Sharpe = mean / std * math.Sqrt(cfg.PeriodsPerYear)
+		}
+	}
+
+	m.Trades = len(trades)
+	wins := 0
+	for _, t := range trades {
+		if t.PnL() > 0 {
+			wins++
+		}
+	}
+	if len(trades) > 0 {
+		m.WinRate = float64(wins) / float64(len(trades))
+	}
+	for _, f := range fills {
+		m.TotalCommission += f.Commission
+		m.TotalSlippage += f.Slippage
+	}
+	return m
+}
+
+// meanStd returns the mean and sample standard deviation of xs.
+func meanStd(xs []float64) (mean, std float64) {
+	if len(xs) == 0 {
+		return 0, 0
+	}
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	if len(xs) < 2 {
+		return mean, 0
+	}
+	var ss float64
+	for _, x := range xs {
+		ss += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(ss / float64(len(xs)-1))
+}
+
+// LoadPricesCSV reads a date,price CSV such as the one written by
+// gendata -kind prices. Dates may be RFC 3339 or YYYY-MM-DD.
+func LoadPricesCSV(r io.Reader) ([]StockPrice, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 && strings.EqualFold(records[0][0], "date") {
+		records = records[1:]
+	}
+	prices := make([]StockPrice, 0, len(records))
+	for i, rec := range records {
+		date, err := time.Parse(time.RFC3339, rec[0])
+		if err != nil {
+			if date, err = time.Parse(time.DateOnly, rec[0]); err != nil {
+				return nil, fmt.Errorf("row %d: invalid date %q", i+1, rec[0])
+			}
+		}
+		price, err := strconv.ParseFloat(rec[1], 64)
+		if err != nil || price <= 0 {
+			return nil, fmt.Errorf("row %d: invalid price %q", i+1, rec[1])
+		}
+		prices = append(prices, StockPrice{Date: date, Price: price})
+	}
+	return prices, nil
+}
+
+// WriteEquityCSV writes the equity curve as date,equity rows.
+func WriteEquityCSV(w io.Writer, curve []EquityPoint) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"date", "equity"}); err != nil {
+		return err
+	}
+	for _, p := range curve {
+		if err := cw.Write([]string{p.Date.Format(time.RFC3339), strconv.FormatFloat(p.Equity, 'f', 2, 64)}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Print writes a human-readable summary of the report.
+func (r *BacktestReport) Print(w io.Writer) {
+	m := r.Metrics
+	fmt.Fprintf(w, "Strategy: %s\n", r.Strategy)
+	fmt.Fprintf(w, "Bars: %d (%s to %s)\n", len(r.EquityCurve), r.EquityCurve[0].Date.Format(time.DateOnly), r.EquityCurve[len(r.EquityCurve)-1].Date.Format(time.DateOnly))
+	fmt.Fprintf(w, "Final equity: %.2f (cash %.2f, %v shares)\n", r.EquityCurve[len(r.EquityCurve)-1].Equity, r.FinalCash, r.Position)
+	fmt.Fprintf(w, "Total return: %.2f%%\n", m.TotalReturn*100)
+	fmt.Fprintf(w, "CAGR: %.2f%%\n", m.CAGR*100)
+	fmt.Fprintf(w, "Max drawdown: %.2f%%\n", m.MaxDrawdown*100)
+	fmt.Fprintf(w, "Sharpe ratio: %.2f\n", m.Sharpe)
+	fmt.Fprintf(w, "Trades: %d (win rate %.1f%%)\n", m.Trades, m.WinRate*100)
+	fmt.Fprintf(w, "Commission paid: %.2f, slippage cost: %.2f\n", m.TotalCommission, m.TotalSlippage)
+	if len(r.Rejections) > 0 {
+		fmt.Fprintf(w, "Rejected orders: %d\n", len(r.Rejections))
+	}
+	for i, t := range r.Trades {
+		fmt.Fprintf(w, "  #%d %s -> %s qty %v pnl %.2f (%.2f%%)\n", i+1, t.EntryDate.Format(time.DateOnly), t.ExitDate.Format(time.DateOnly), t.Quantity, t.PnL(), t.Return()*100)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"example.com/464826/ideal2/generator"
+)
+
+// pricesFrom builds a daily series from raw prices.
+func pricesFrom(values ...float64) []StockPrice {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	prices := make([]StockPrice, len(values))
+	for i, v := range values {
+		prices[i] = StockPrice{Date: start.AddDate(0, 0, i), Price: v}
+	}
+	return prices
+}
+
+// scripted places a fixed order on given bar indexes.
+type scripted struct {
+	bar    int
+	orders map[int]func(*Broker)
+}
+
+func (s *scripted) Name() string { return "scripted" }
+
+func (s *scripted) OnBar(ctx context.Context, bar StockPrice, broker *Broker) {
+	if place, ok := s.orders[s.bar]; ok {
+		place(broker)
+	}
+	s.bar++
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// TestBrokerFillsNextBar tests that orders fill on the next bar with slippage and commission applied
+func TestBrokerFillsNextBar(t *testing.T) {
+	prices := pricesFrom(100, 110, 120, 90)
+	strategy := &scripted{orders: map[int]func(*Broker){
+		0: func(b *Broker) { b.Buy(10) },
+		2: func(b *Broker) { b.Sell(10) },
+	}}
+	cfg := BacktestConfig{InitialCash: 10_000, Commission: CommissionModel{PerOrder: 1, Rate: 0.001}, SlippageBps: 100}
+
+	report, err := RunBacktest(context.Background(), prices, strategy, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(report.Fills) != 2 {
+		t.Fatalf("Expected 2 fills, got %d", len(report.Fills))
+	}
+
+	buy, sell := report.Fills[0], report.Fills[1]
+	// Buy at bar 1 (110) plus 1% slippage; sell at bar 3 (90) minus 1%.
+	if !almostEqual(buy.Price, 111.1) || !buy.Date.Equal(prices[1].Date) {
+		t.Errorf("Expected buy at 111.1 on %v, got %+v", prices[1].Date, buy)
+	}
+	if !almostEqual(sell.Price, 89.1) || !sell.Date.Equal(prices[3].Date) {
+		t.Errorf("Expected sell at 89.1 on %v, got %+v", prices[3].Date, sell)
+	}
+	if !almostEqual(buy.Commission, 1+1.111) || !almostEqual(sell.Commission, 1+0.891) {
+		t.Errorf("Unexpected commissions %.4f and %.4f", buy.Commission, sell.Commission)
+	}
+
+	if len(report.Trades) != 1 {
+		t.Fatalf("Expected 1 trade, got %d", len(report.Trades))
+	}
+	wantPnL := (891 - 1.891) - (1111 + 2.111)
+	if pnl := report.Trades[0].PnL(); !almostEqual(pnl, wantPnL) {
+		t.Errorf("Expected trade PnL %.4f, got %.4f", wantPnL, pnl)
+	}
+	if !almostEqual(report.FinalCash, 10_000+wantPnL) || report.Position != 0 {
+		t.Errorf("Expected flat account with cash %.4f, got %.4f and %v shares", 10_000+wantPnL, report.FinalCash, report.Position)
+	}
+	if report.Metrics.WinRate != 0 || report.Metrics.Trades != 1 {
+		t.Errorf("Unexpected trade metrics %+v", report.Metrics)
+	}
+	if !almostEqual(report.Metrics.TotalSlippage, 11+9) {
+		t.Errorf("Expected slippage cost 20, got %.4f", report.Metrics.TotalSlippage)
+	}
+}
+
+// TestBrokerRejections tests that unaffordable buys and oversized sells are rejected
+func TestBrokerRejections(t *testing.T) {
+	strategy := &scripted{orders: map[int]func(*Broker){
+		0: func(b *Broker) { b.Buy(1000) },
+		1: func(b *Broker) { b.Sell(1) },
+		2: func(b *Broker) { b.Buy(0) },
+	}}
+	report, err := RunBacktest(context.Background(), pricesFrom(100, 100, 100, 100), strategy, BacktestConfig{InitialCash: 500})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(report.Rejections) != 3 || len(report.Fills) != 0 {
+		t.Fatalf("Expected 3 rejections and no fills, got %d and %d", len(report.Rejections), len(report.Fills))
+	}
+	if !strings.Contains(report.Rejections[0].Reason, "insufficient cash") {
+		t.Errorf("Unexpected rejection reason %q", report.Rejections[0].Reason)
+	}
+	if report.FinalCash != 500 {
+		t.Errorf("Expected cash to be untouched, got %.2f", report.FinalCash)
+	}
+}
+
+// TestBacktestMetrics tests return and drawdown on a known equity path
+func TestBacktestMetrics(t *testing.T) {
+	prices := pricesFrom(100, 100, 200, 100, 150)
+	report, err := RunBacktest(context.Background(), prices, &BuyAndHold{}, BacktestConfig{InitialCash: 1000})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// 10 shares bought at 100 on bar 1; equity 1000, 1000, 2000, 1000, 1500.
+	if report.Position != 9 {
+		t.Fatalf("Expected 9 shares after the 1%% headroom, got %v", report.Position)
+	}
+	last := report.EquityCurve[len(report.EquityCurve)-1].Equity
+	if !almostEqual(last, 100+9*150) {
+		t.Errorf("Expected final equity 1450, got %.2f", last)
+	}
+	if !almostEqual(report.Metrics.TotalReturn, 0.45) {
+		t.Errorf("Expected total return 0.45, got %.4f", report.Metrics.TotalReturn)
+	}
+	if !almostEqual(report.Metrics.MaxDrawdown, 900.0/1900) {
+		t.Errorf("Expected max drawdown %.4f, got %.4f", 900.0/1900, report.Metrics.MaxDrawdown)
+	}
+	if report.Metrics.Sharpe <= 0 {
+		t.Errorf("Expected a positive Sharpe ratio, got %.4f", report.Metrics.Sharpe)
+	}
+}
+
+// TestBacktestRejectsUnorderedBars tests that bars must be in increasing date order
+func TestBacktestRejectsUnorderedBars(t *testing.T) {
+	prices := pricesFrom(100, 101)
+	prices[1].Date = prices[0].Date
+	if _, err := RunBacktest(context.Background(), prices, &BuyAndHold{}, BacktestConfig{InitialCash: 1000}); err == nil {
+		t.Errorf("Expected an error for duplicate dates")
+	}
+	if _, err := RunBacktest(context.Background(), nil, &BuyAndHold{}, BacktestConfig{InitialCash: 1000}); err == nil {
+		t.Errorf("Expected an error for an empty series")
+	}
+}
+
+// TestBacktestFromGeneratedCSV tests an SMA crossover run over a generated CSV end to end
+func TestBacktestFromGeneratedCSV(t *testing.T) {
+	var buf strings.Builder
+	points, err := generator.Prices(generator.DefaultPriceConfig(3, 400))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := generator.WritePricesCSV(&buf, points); err != nil {
+		t.Fatal(err)
+	}
+	prices, err := LoadPricesCSV(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("Unexpected error loading CSV: %v", err)
+	}
+	if len(prices) != 400 {
+		t.Fatalf("Expected 400 prices, got %d", len(prices))
+	}
+
+	cfg := BacktestConfig{InitialCash: 10_000, Commission: CommissionModel{PerOrder: 1}, SlippageBps: 5}
+	first, err := RunBacktest(context.Background(), prices, mustSMA(t, 5, 20), cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	second, _ := RunBacktest(context.Background(), prices, mustSMA(t, 5, 20), cfg)
+	if first.Metrics != second.Metrics {
+		t.Errorf("Expected identical runs to give identical metrics")
+	}
+	if first.Metrics.Trades == 0 {
+		t.Errorf("Expected the crossover strategy to trade")
+	}
+	for _, f := range first.Fills {
+		if f.Side == Buy && f.Date.Equal(prices[0].Date) {
+			t.Errorf("Expected no fill on the first bar")
+		}
+	}
+}
+
+// TestLoadPricesCSVErrors tests that malformed rows are reported
+func TestLoadPricesCSVErrors(t *testing.T) {
+	for _, input := range []string{
+		"date,price\n2024-01-01,abc\n",
+		"date,price\nyesterday,100\n",
+		"date,price\n2024-01-01,-5\n",
+	} {
+		if _, err := LoadPricesCSV(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+	prices, err := LoadPricesCSV(strings.NewReader("2024-01-01,100\n2024-01-02,101.5\n"))
+	if err != nil || len(prices) != 2 || prices[1].Price != 101.5 {
+		t.Errorf("Expected headerless CSV to load, got %v, %v", prices, err)
+	}
+}
+
+// mustSMA returns a crossover strategy and fails the test on error.
+func mustSMA(t *testing.T, short, long int) *SMACrossover {
+	t.Helper()
+	s, err := NewSMACrossover(short, long)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// TestNewSMACrossoverRejectsWindows tests that windows outside 0 < short < long are rejected
+func TestNewSMACrossoverRejectsWindows(t *testing.T) {
+	for _, w := range [][2]int{{60, 20}, {20, 20}, {0, 20}, {-5, 20}, {5, 0}} {
+		if _, err := NewSMACrossover(w[0], w[1]); err == nil {
+			t.Errorf("Expected an error for short %d and long %d", w[0], w[1])
+		}
+	}
+	if _, err := NewSMACrossover(1, 2); err != nil {
+		t.Errorf("Expected short 1 and long 2 to be accepted, got %v", err)
+	}
+}
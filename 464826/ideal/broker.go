@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Side is the direction of an order.
+type Side int
+
+const (
+	Buy Side = iota
+	Sell
+)
+
+func (s Side) String() string {
+	if s == Buy {
+		return "buy"
+	}
+	return "sell"
+}
+
+// Order is a market order for a number of shares. Orders submitted while
+// a bar is processed are filled at the price of the next bar.
+type Order struct {
+	ID        int
+	Side      Side
+	Quantity  float64
+	Submitted time.Time
+}
+
+// Fill records the execution of an order.
+type Fill struct {
+	OrderID    int
+	Date       time.Time
+	Side       Side
+	Quantity   float64
+	Price      float64 // execution price after slippage
+	Commission float64
+	Slippage   float64 // cost of slippage versus the bar price
+}
+
+// Rejection records an order the broker refused to fill.
+type Rejection struct {
+	Order  Order
+	Date   time.Time
+	Reason string
+}
+
+// Trade is a round trip from a flat position back to flat.
+type Trade struct {
+	EntryDate, ExitDate time.Time
+	Quantity            float64 // largest position held during the trade
+	Cost                float64 // cash paid for buys, including commissions
+	Proceeds            float64 // cash received for sells, net of commissions
+}
+
+// PnL returns the profit or loss of the trade.
+func (t Trade) PnL() float64 {
+	return t.Proceeds - t.Cost
+}
+
+// Return returns the PnL relative to the cash invested.
+func (t Trade) Return() float64 {
+	if t.Cost == 0 {
+		return 0
+	}
+	return t.PnL() / t.Cost
+}
+
+// CommissionModel charges a fixed fee per order plus a rate on traded value.
+type CommissionModel struct {
+	PerOrder float64
+	Rate     float64 // fraction of traded value, e.g. 0.001 for 10bp
+}
+
+// Charge returns the commission for trading value.
+func (c CommissionModel) Charge(value float64) float64 {
+	return c.PerOrder + c.Rate*value
+}
+
+// Broker simulates a cash account trading a single instrument. It does
+// not allow short selling or buying on margin.
+type Broker struct {
+	cash        float64
+	position    float64
+	commission  CommissionModel
+	slippageBps float64
+
+	last     StockPrice
+	nextID   int
+	pending  []Order
+	fills    []Fill
+	rejected []Rejection
+	trades   []Trade
+	open     *Trade
+}
+
+// NewBroker creates a broker holding cash.
+func NewBroker(cash float64, commission CommissionModel, slippageBps float64) *Broker {
+	return &Broker{cash: cash, commission: commission, slippageBps: slippageBps, nextID: 1}
+}
+
+// Cash returns the uninvested cash.
+func (b *Broker) Cash() float64 {
+	return b.cash
+}
+
+// Position returns the number of shares held.
+func (b *Broker) Position() float64 {
+	return b.position
+}
+
+// Equity returns cash plus the position marked at the latest bar.
+func (b *Broker) Equity() float64 {
+	return b.cash + b.position*b.last.Price
+}
+
+// Pending returns the orders waiting for the next bar.
+func (b *Broker) Pending() []Order {
+	return append([]Order(nil), b.pending...)
+}
+
+// Buy submits a market buy order and returns its ID.
+func (b *Broker) Buy(quantity float64) int {
+	return b.submit(Buy, quantity)
+}
+
+// Sell submits a market sell order and returns its ID.
+func (b *Broker) Sell(quantity float64) int {
+	return b.submit(Sell, quantity)
+}
+
+func (b *Broker) submit(side Side, quantity float64) int {
+	order := Order{ID: b.nextID, Side: side, Quantity: quantity, Submitted: b.last.Date}
+	b.nextID++
+	b.pending = append(b.pending, order)
+	return order.ID
+}
+
+// executionPrice applies slippage against the order's direction.
+func (b *Broker) executionPrice(side Side, price float64) float64 {
+	slip := price * b.slippageBps / 10_000
+	if side == Buy {
+		return price + slip
+	}
+	return price - slip
+}
+
+// processBar fills the pending orders at bar and then marks the account
+// to it. Orders that cannot be filled are rejected, never retried.
+func (b *Broker) processBar(bar StockPrice) {
+	b.last = bar
+	pending := b.pending
+	b.pending = nil
+	for _, order := range pending {
+		if err := b.fill(order, bar); err != nil {
+			b.rejected = append(b.rejected, Rejection{Order: order, Date: bar.Date, Reason: err.Error()})
+		}
+	}
+}
+
+func (b *Broker) fill(order Order, bar StockPrice) error {
+	if order.Quantity <= 0 {
+		return fmt.Errorf("order %d: quantity %v must be positive", order.ID, order.Quantity)
+	}
+	price := b.executionPrice(order.Side, bar.Price)
+	value := order.Quantity * price
+	commission := b.commission.Charge(value)
+
+	switch order.Side {
+	case Buy:
+		if value+commission > b.cash {
+			return fmt.Errorf("order %d: insufficient cash %.2f for %.2f", order.ID, b.cash, value+commission)
+		}
+		b.cash -= value + commission
+		b.position += order.Quantity
+		if b.open == nil {
+			b.open = &Trade{EntryDate: bar.Date}
+		}
+		b.open.Cost += value + commission
+		b.open.Quantity = max(b.open.Quantity, b.position)
+	case Sell:
+		if order.Quantity > b.position {
+			return fmt.Errorf("order %d: cannot sell %v shares, holding %v", order.ID, order.Quantity, b.position)
+		}
+		b.cash += value - commission
+		b.position -= order.Quantity
+		b.open.Proceeds += value - commission
+		if b.position == 0 {
+			b.open.ExitDate = bar.Date
+			b.trades = append(b.trades, *b.open)
+			b.open = nil
+		}
+	}
+
+	b.fills = append(b.fills, Fill{
+		OrderID:    order.ID,
+		Date:       bar.Date,
+		Side:       order.Side,
+		Quantity:   order.Quantity,
+		Price:      price,
+		Commission: commission,
+		Slippage:   order.Quantity * abs(price-bar.Price),
+	})
+	return nil
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
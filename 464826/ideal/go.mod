@@ -0,0 +1,7 @@
+module example.com/464826/ideal
+
+go 1.23.4
+
+require example.com/464826/ideal2 v0.0.0
+
+replace example.com/464826/ideal2 => ../ideal2
@@ -2,8 +2,12 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"os"
 	"time"
+
+	"example.com/464826/ideal2/generator"
 )
 
 // StockPrice represents a stock price entry.
@@ -34,8 +38,9 @@ func CalculateStats(ctx context.Context, prices []StockPrice) (sum, average floa
 	return totalPrice, totalPrice / float64(count)
 }
 
-// FetchData simulates fetching stock price data.
-func FetchData(ctx context.Context) ([]StockPrice, error) {
+// FetchData simulates fetching stock price data. The series is a seeded
+// random walk, so the same seed always returns the same prices.
+func FetchData(ctx context.Context, seed int64) ([]StockPrice, error) {
 	// Simulate fetching data from a database or API
 	// Delay for demonstration purposes
 	select {
@@ -44,14 +49,15 @@ func FetchData(ctx context.Context) ([]StockPrice, error) {
 	case <-time.After(2 * time.Second):
 	}
 
-	// Example data
-	dates := []time.Time{
-		time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC),
-		time.Date(2023, 10, 2, 0, 0, 0, 0, time.UTC),
-		time.Date(2023, 10, 3, 0, 0, 0, 0, time.UTC),
+	points, err := generator.Prices(generator.DefaultPriceConfig(seed, 3))
+	if err != nil {
+		return nil, err
+	}
+	dates := make([]time.Time, len(points))
+	prices := make([]float64, len(points))
+	for i, p := range points {
+		dates[i], prices[i] = p.Date, p.Price
 	}
-
-	prices := []float64{100.0, 101.0, 102.0}
 
 	return zipData(dates, prices), nil
 }
@@ -68,11 +74,50 @@ func zipData(dates []time.Time, prices []float64) []StockPrice {
 }
 
 func main() {
+	csvPath := flag.String("backtest", "", "run a backtest over a date,price CSV instead of the demo")
+	strategyName := flag.String("strategy", "sma", "backtest strategy: sma or hold")
+	short := flag.Int("short", 20, "short moving average window")
+	long := flag.Int("long", 50, "long moving average window")
+	cash := flag.Float64("cash", 10_000, "initial cash")
+	perOrder := flag.Float64("commission", 1, "commission per order")
+	rate := flag.Float64("commission-rate", 0.0005, "commission as a fraction of traded value")
+	slippage := flag.Float64("slippage-bps", 5, "slippage in basis points")
+	equityPath := flag.String("equity", "", "write the equity curve CSV to this file")
+	flag.Parse()
+
+	if *csvPath != "" {
+		var strategy Strategy
+		switch *strategyName {
+		case "sma":
+			sma, err := NewSMACrossover(*short, *long)
+			if err != nil {
+				fmt.Printf("Error running backtest: %v\n", err)
+				os.Exit(1)
+			}
+			strategy = sma
+		case "hold":
+			strategy = &BuyAndHold{}
+		default:
+			fmt.Printf("Error running backtest: unknown strategy %q, want sma or hold\n", *strategyName)
+			os.Exit(1)
+		}
+		cfg := BacktestConfig{
+			InitialCash: *cash,
+			Commission:  CommissionModel{PerOrder: *perOrder, Rate: *rate},
+			SlippageBps: *slippage,
+		}
+		if err := runBacktestFile(*csvPath, *equityPath, strategy, cfg); err != nil {
+			fmt.Printf("Error running backtest: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	// Simulate fetching stock price data
-	prices, err := FetchData(ctx)
+	prices, err := FetchData(ctx, 1)
 	if err != nil {
 		fmt.Printf("Error fetching data: %v\n", err)
 		return
@@ -84,4 +129,37 @@ func main() {
 	// Display results
 	fmt.Printf("Sum of prices: %.2f\n", sum)
 	fmt.Printf("Average price: %.2f\n", average)
-}
\ No newline at end of file
+}
+
+// runBacktestFile loads prices from csvPath, runs strategy over them and
+// prints the report, optionally saving the equity curve.
+func runBacktestFile(csvPath, equityPath string, strategy Strategy, cfg BacktestConfig) error {
+	f, err := os.Open(csvPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	prices, err := LoadPricesCSV(f)
+	if err != nil {
+		return err
+	}
+
+	report, err := RunBacktest(context.Background(), prices, strategy, cfg)
+	if err != nil {
+		return err
+	}
+	report.Print(os.Stdout)
+
+	if equityPath == "" {
+		return nil
+	}
+	out, err := os.Create(equityPath)
+	if err != nil {
+		return err
+	}
+	if err := WriteEquityCSV(out, report.EquityCurve); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
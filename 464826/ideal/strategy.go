@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Strategy receives bars one at a time, in date order, and may place
+// orders with the broker. Orders fill on the following bar, so a
+// strategy can never trade at a price it has not yet seen.
+type Strategy interface {
+	Name() string
+	OnBar(ctx context.Context, bar StockPrice, broker *Broker)
+}
+
+// BuyAndHold invests all cash on the first bar and never sells.
+type BuyAndHold struct {
+	invested bool
+}
+
+func (s *BuyAndHold) Name() string { return "buy-and-hold" }
+
+func (s *BuyAndHold) OnBar(ctx context.Context, bar StockPrice, broker *Broker) {
+	if s.invested {
+		return
+	}
+	if qty := affordable(broker, bar.Price); qty > 0 {
+		broker.Buy(qty)
+		s.invested = true
+	}
+}
+
+// SMACrossover buys when the short moving average crosses above the long
+// one and sells the whole position when it crosses back below. The windows
+// must satisfy 0 < Short < Long; use NewSMACrossover to check them.
+type SMACrossover struct {
+	Short, Long int
+
+	window    []StockPrice
+	prevAbove bool
+	primed    bool
+}
+
+// NewSMACrossover returns a crossover strategy over the given windows, or an
+// error unless 0 < short < long.
+func NewSMACrossover(short, long int) (*SMACrossover, error) {
+	if short <= 0 || short >= long {
+		return nil, fmt.Errorf("sma crossover needs 0 < short < long, got short %d and long %d", short, long)
+	}
+	return &SMACrossover{Short: short, Long: long}, nil
+}
+
+func (s *SMACrossover) Name() string { return "sma-crossover" }
+
+func (s *SMACrossover) OnBar(ctx context.Context, bar StockPrice, broker *Broker) {
+	s.window = append(s.window, bar)
+	if len(s.window) > s.Long {
+		s.window = s.window[1:]
+	}
+	if len(s.window) < s.Long {
+		return
+	}
+
+	_, shortAvg := CalculateStats(ctx, s.window[len(s.window)-s.Short:])
+	_, longAvg := CalculateStats(ctx, s.window)
+	above := shortAvg > longAvg
+	if s.primed && above != s.prevAbove {
+		switch {
+		case above && broker.Position() == 0 && len(broker.Pending()) == 0:
+			if qty := affordable(broker, bar.Price); qty > 0 {
+				broker.Buy(qty)
+			}
+		case !above && broker.Position() > 0:
+			broker.Sell(broker.Position())
+		}
+	}
+	s.prevAbove, s.primed = above, true
+}
+
+// affordable returns the whole number of shares that can be bought at
+// price, leaving headroom for slippage and commission.
+func affordable(broker *Broker, price float64) float64 {
+	budget := broker.Cash() - broker.commission.PerOrder
+	unit := broker.executionPrice(Buy, price) * 1.01 * (1 + broker.commission.Rate)
+	if budget <= 0 || unit <= 0 {
+		return 0
+	}
+	return math.Floor(budget / unit)
+}
@@ -0,0 +1,119 @@
+// Command gendata writes reproducible synthetic transactions or prices
+// as CSV or JSONL.
+//
+//	gendata -kind transactions -n 1000000 -seed 1 -o transactions.csv
+//	gendata -kind transactions -dist lognormal -mu 5 -sigma 1
+//	gendata -kind prices -n 500 -drift 0.0005 -volatility 0.02 -format jsonl
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"example.com/464826/ideal2/generator"
+)
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run generates the requested data. It returns rather than exiting so the
+// output file is always flushed and closed.
+func run() (err error) {
+	kind := flag.String("kind", "transactions", "data to generate: transactions or prices")
+	format := flag.String("format", "csv", "output format: csv or jsonl")
+	out := flag.String("o", "", "output file (default stdout)")
+	n := flag.Int("n", 1000, "number of records")
+	seed := flag.Int64("seed", 1, "random seed")
+
+	txDefaults := generator.DefaultTransactionConfig(0, 0)
+	dist := flag.String("dist", "uniform", "amount distribution: uniform or lognormal")
+	minAmount := flag.Float64("min", txDefaults.AmountMin, "lower bound of uniform amounts")
+	maxAmount := flag.Float64("max", txDefaults.AmountMax, "upper bound of uniform amounts")
+	mu := flag.Float64("mu", txDefaults.AmountMu, "mean of log(amount) for lognormal amounts")
+	sigma := flag.Float64("sigma", txDefaults.AmountSigma, "standard deviation of log(amount) for lognormal amounts")
+	creditRatio := flag.Float64("credit-ratio", txDefaults.CreditRatio, "probability of a credit transaction")
+
+	priceDefaults := generator.DefaultPriceConfig(0, 0)
+	initial := flag.Float64("initial", priceDefaults.InitialPrice, "initial price")
+	drift := flag.Float64("drift", priceDefaults.Drift, "per-step drift")
+	volatility := flag.Float64("volatility", priceDefaults.Volatility, "per-step volatility")
+	interval := flag.Duration("interval", priceDefaults.Interval, "time between price points")
+	flag.Parse()
+
+	// Generate before creating the output file so a bad configuration
+	// does not leave an empty file behind.
+	var encode func(io.Writer) error
+	switch *kind {
+	case "transactions":
+		cfg := generator.TransactionConfig{
+			Seed: *seed, Count: *n,
+			AmountMin: *minAmount, AmountMax: *maxAmount,
+			AmountMu: *mu, AmountSigma: *sigma,
+			CreditRatio: *creditRatio,
+		}
+		switch *dist {
+		case "uniform":
+			cfg.Distribution = generator.Uniform
+		case "lognormal":
+			cfg.Distribution = generator.LogNormal
+		default:
+			return fmt.Errorf("unknown distribution %q", *dist)
+		}
+		transactions, err := generator.Transactions(cfg)
+		if err != nil {
+			return err
+		}
+		encode = func(w io.Writer) error { return write(w, *format, transactions, generator.WriteTransactionsCSV) }
+	case "prices":
+		cfg := generator.DefaultPriceConfig(*seed, *n)
+		cfg.InitialPrice, cfg.Drift, cfg.Volatility, cfg.Interval = *initial, *drift, *volatility, *interval
+		points, err := generator.Prices(cfg)
+		if err != nil {
+			return err
+		}
+		encode = func(w io.Writer) error { return write(w, *format, points, generator.WritePricesCSV) }
+	default:
+		return fmt.Errorf("unknown kind %q", *kind)
+	}
+	if *format != "csv" && *format != "jsonl" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	if err := encode(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// write encodes records in the requested format.
+func write[T any](w io.Writer, format string, records []T, writeCSV func(io.Writer, []T) error) error {
+	switch format {
+	case "csv":
+		return writeCSV(w, records)
+	case "jsonl":
+		return generator.WriteJSONL(w, records)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import "math/bits"
+
+// TransactionColumns stores transactions column by column. Type is
+// dictionary-encoded so that filters compare small integers instead of
+// strings, and each column is a flat slice the filter kernels can stream.
+type TransactionColumns struct {
+	IDs     []int
+	Amounts []float64
+	Types   []uint32 // index into Dict
+	Dict    []string // distinct Type values, in first-seen order
+}
+
+// toColumns converts row-form transactions to the columnar form. Type is
+// free text, so codes are 32 bits wide to hold any number of distinct types.
+func toColumns(transactions []Transaction) *TransactionColumns {
+	cols := &TransactionColumns{
+		IDs:     make([]int, len(transactions)),
+		Amounts: make([]float64, len(transactions)),
+		Types:   make([]uint32, len(transactions)),
+	}
+	codes := make(map[string]uint32)
+	for i, t := range transactions {
+		code, ok := codes[t.Type]
+		if !ok {
+			code = uint32(len(cols.Dict))
+			codes[t.Type] = code
+			cols.Dict = append(cols.Dict, t.Type)
+		}
+		cols.IDs[i] = t.ID
+		cols.Amounts[i] = t.Amount
+		cols.Types[i] = code
+	}
+	return cols
+}
+
+// Len returns the number of transactions stored.
+func (c *TransactionColumns) Len() int {
+	return len(c.IDs)
+}
+
+// Row reconstructs the i-th transaction.
+func (c *TransactionColumns) Row(i int) Transaction {
+	return Transaction{ID: c.IDs[i], Amount: c.Amounts[i], Type: c.Dict[c.Types[i]]}
+}
+
+// Rows converts the columns back to row form.
+func (c *TransactionColumns) Rows() []Transaction {
+	transactions := make([]Transaction, c.Len())
+	for i := range transactions {
+		transactions[i] = c.Row(i)
+	}
+	return transactions
+}
+
+// typeCode returns the dictionary code for typ.
+func (c *TransactionColumns) typeCode(typ string) (uint32, bool) {
+	for code, v := range c.Dict {
+		if v == typ {
+			return uint32(code), true
+		}
+	}
+	return 0, false
+}
+
+// Bitmap marks selected rows, one bit per row, 64 rows per word.
+type Bitmap []uint64
+
+// newBitmap returns a cleared bitmap able to hold n rows.
+func newBitmap(n int) Bitmap {
+	return make(Bitmap, (n+63)/64)
+}
+
+// Test reports whether row i is selected.
+func (b Bitmap) Test(i int) bool {
+	return b[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+// Count returns the number of selected rows.
+func (b Bitmap) Count() int {
+	n := 0
+	for _, w := range b {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// And intersects b with other in place.
+func (b Bitmap) And(other Bitmap) {
+	for i := range b {
+		b[i] &= other[i]
+	}
+}
+
+// Each calls fn for every selected row in ascending order.
+func (b Bitmap) Each(fn func(i int)) {
+	for w, word := range b {
+		for word != 0 {
+			fn(w*64 + bits.TrailingZeros64(word))
+			word &= word - 1
+		}
+	}
+}
+
+// b2u converts a comparison result to 0 or 1 without a branch.
+func b2u(v bool) uint64 {
+	var u uint64
+	if v {
+		u = 1
+	}
+	return u
+}
+
+// amountAtLeast sets bit i of out when amounts[i] >= threshold. The kernel
+// works on whole 64-row blocks with branch-free bit packing so the
+// inner loop stays tight regardless of selectivity.
+func amountAtLeast(amounts []float64, threshold float64, out Bitmap) {
+	full := len(amounts) / 64
+	for w := 0; w < full; w++ {
+		block := amounts[w*64 : w*64+64]
+		var word uint64
+		for j, a := range block {
+			word |= b2u(a >= threshold) << uint(j)
+		}
+		out[w] = word
+	}
+	if rest := amounts[full*64:]; len(rest) > 0 {
+		var word uint64
+		for j, a := range rest {
+			word |= b2u(a >= threshold) << uint(j)
+		}
+		out[full] = word
+	}
+}
+
+// typeEquals sets bit i of out when types[i] == code.
+func typeEquals(types []uint32, code uint32, out Bitmap) {
+	full := len(types) / 64
+	for w := 0; w < full; w++ {
+		block := types[w*64 : w*64+64]
+		var word uint64
+		for j, t := range block {
+			word |= b2u(t == code) << uint(j)
+		}
+		out[w] = word
+	}
+	if rest := types[full*64:]; len(rest) > 0 {
+		var word uint64
+		for j, t := range rest {
+			word |= b2u(t == code) << uint(j)
+		}
+		out[full] = word
+	}
+}
+
+// Filter returns a bitmap of the rows matching criteria.
+func (c *TransactionColumns) Filter(criteria FilterCriteria) Bitmap {
+	selected := newBitmap(c.Len())
+	code, ok := c.typeCode(criteria.Type)
+	if !ok {
+		return selected
+	}
+	amountAtLeast(c.Amounts, criteria.MinAmount, selected)
+	byType := newBitmap(c.Len())
+	typeEquals(c.Types, code, byType)
+	selected.And(byType)
+	return selected
+}
+
+// Select materialises the selected rows in row form.
+func (c *TransactionColumns) Select(selected Bitmap) []Transaction {
+	transactions := make([]Transaction, 0, selected.Count())
+	selected.Each(func(i int) {
+		transactions = append(transactions, c.Row(i))
+	})
+	return transactions
+}
+
+// SumAmount totals the amounts of the selected rows without
+// materialising them.
+func (c *TransactionColumns) SumAmount(selected Bitmap) float64 {
+	var total float64
+	selected.Each(func(i int) {
+		total += c.Amounts[i]
+	})
+	return total
+}
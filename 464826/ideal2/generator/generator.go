@@ -0,0 +1,205 @@
+// Package generator produces reproducible synthetic transactions and
+// stock prices. Every generator takes an explicit seed, so the same
+// configuration always yields the same data.
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Transaction mirrors the transaction record used by the filtering program.
+type Transaction struct {
+	ID     int     `json:"id"`
+	Amount float64 `json:"amount"`
+	Type   string  `json:"type"`
+}
+
+// PricePoint is a single observation of a price series.
+type PricePoint struct {
+	Date  time.Time `json:"date"`
+	Price float64   `json:"price"`
+}
+
+// AmountDistribution selects how transaction amounts are drawn. Amounts are
+// rounded to whole cents and are never zero.
+type AmountDistribution int
+
+const (
+	// Uniform draws amounts uniformly from [AmountMin, AmountMax). After
+	// rounding they are clamped back into the range.
+	Uniform AmountDistribution = iota
+	// LogNormal draws amounts as exp(N(AmountMu, AmountSigma²)).
+	LogNormal
+)
+
+// TransactionConfig describes the distribution of generated transactions.
+type TransactionConfig struct {
+	Seed         int64
+	Count        int
+	Distribution AmountDistribution
+	AmountMin    float64 // lower bound of uniform amounts
+	AmountMax    float64 // upper bound of uniform amounts
+	AmountMu     float64 // mean of log(amount) for log-normal amounts
+	AmountSigma  float64 // standard deviation of log(amount) for log-normal amounts
+	CreditRatio  float64 // probability that a transaction is a credit, in [0, 1]
+}
+
+// DefaultTransactionConfig returns the distribution the filtering program
+// has always used: amounts uniform in [0, 1000) and an even credit/debit
+// split. The log-normal parameters give a median of about 150 when
+// Distribution is set to LogNormal.
+func DefaultTransactionConfig(seed int64, count int) TransactionConfig {
+	return TransactionConfig{
+		Seed:        seed,
+		Count:       count,
+		AmountMin:   0,
+		AmountMax:   1000,
+		AmountMu:    5,
+		AmountSigma: 1,
+		CreditRatio: 0.5,
+	}
+}
+
+// Validate reports every problem with cfg.
+func (cfg TransactionConfig) Validate() error {
+	var errs []error
+	if cfg.Count < 0 {
+		errs = append(errs, fmt.Errorf("count must not be negative, got %d", cfg.Count))
+	}
+	if !(cfg.CreditRatio >= 0 && cfg.CreditRatio <= 1) {
+		errs = append(errs, fmt.Errorf("credit ratio must be in [0, 1], got %v", cfg.CreditRatio))
+	}
+	switch cfg.Distribution {
+	case Uniform:
+		lo, hi := centRange(cfg.AmountMin, cfg.AmountMax)
+		if math.IsInf(cfg.AmountMin, 0) || math.IsInf(cfg.AmountMax, 0) {
+			errs = append(errs, fmt.Errorf("amount range [%v, %v) must be finite", cfg.AmountMin, cfg.AmountMax))
+		} else if !(lo <= hi) || lo == 0 && hi == 0 {
+			errs = append(errs, fmt.Errorf("amount range [%v, %v) holds no nonzero whole cent", cfg.AmountMin, cfg.AmountMax))
+		}
+	case LogNormal:
+		if math.IsNaN(cfg.AmountMu) || math.IsInf(cfg.AmountMu, 0) {
+			errs = append(errs, fmt.Errorf("amount mu must be finite, got %v", cfg.AmountMu))
+		}
+		if !(cfg.AmountSigma >= 0) || math.IsInf(cfg.AmountSigma, 1) {
+			errs = append(errs, fmt.Errorf("amount sigma must be finite and not negative, got %v", cfg.AmountSigma))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown amount distribution %d", cfg.Distribution))
+	}
+	return errors.Join(errs...)
+}
+
+// Transactions generates cfg.Count transactions with IDs starting at 1.
+func Transactions(cfg TransactionConfig) ([]Transaction, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	lo, hi := centRange(cfg.AmountMin, cfg.AmountMax)
+	if cfg.Distribution == LogNormal {
+		lo, hi = 1, math.Inf(1)
+	}
+	transactions := make([]Transaction, cfg.Count)
+	for i := range transactions {
+		var amount float64
+		if cfg.Distribution == LogNormal {
+			amount = math.Exp(cfg.AmountMu + cfg.AmountSigma*rng.NormFloat64())
+		} else {
+			amount = cfg.AmountMin + rng.Float64()*(cfg.AmountMax-cfg.AmountMin)
+		}
+		cents := min(max(math.Round(amount*100), lo), hi)
+		if cents == 0 {
+			// Validate guarantees a nonzero cent on one side of zero.
+			if hi >= 1 {
+				cents = 1
+			} else {
+				cents = -1
+			}
+		}
+		typ := "debit"
+		if rng.Float64() < cfg.CreditRatio {
+			typ = "credit"
+		}
+		transactions[i] = Transaction{
+			ID:     i + 1,
+			Amount: cents / 100,
+			Type:   typ,
+		}
+	}
+	return transactions, nil
+}
+
+// centRange returns the smallest and largest whole number of cents in
+// [lo, hi).
+func centRange(lo, hi float64) (float64, float64) {
+	return math.Ceil(lo * 100), math.Ceil(hi*100) - 1
+}
+
+// PriceConfig describes a geometric random walk. Each step multiplies the
+// price by exp(Drift - Volatility²/2 + Volatility·Z) with Z ~ N(0, 1),
+// so Drift and Volatility are per-step rates.
+type PriceConfig struct {
+	Seed         int64
+	Count        int
+	Start        time.Time
+	Interval     time.Duration
+	InitialPrice float64
+	Drift        float64
+	Volatility   float64
+}
+
+// DefaultPriceConfig returns a daily series starting at 100 on
+// 2023-10-01 with a slight upward drift and 1% daily volatility.
+func DefaultPriceConfig(seed int64, count int) PriceConfig {
+	return PriceConfig{
+		Seed:         seed,
+		Count:        count,
+		Start:        time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC),
+		Interval:     24 * time.Hour,
+		InitialPrice: 100,
+		Drift:        0.0003,
+		Volatility:   0.01,
+	}
+}
+
+// Validate reports every problem with cfg.
+func (cfg PriceConfig) Validate() error {
+	var errs []error
+	if cfg.Count < 0 {
+		errs = append(errs, fmt.Errorf("count must not be negative, got %d", cfg.Count))
+	}
+	if !(cfg.InitialPrice > 0) {
+		errs = append(errs, fmt.Errorf("initial price must be positive, got %v", cfg.InitialPrice))
+	}
+	if !(cfg.Volatility >= 0) {
+		errs = append(errs, fmt.Errorf("volatility must not be negative, got %v", cfg.Volatility))
+	}
+	return errors.Join(errs...)
+}
+
+// Prices generates cfg.Count price points. The first point is the
+// initial price at cfg.Start.
+func Prices(cfg PriceConfig) ([]PricePoint, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	points := make([]PricePoint, cfg.Count)
+	price := cfg.InitialPrice
+	step := cfg.Drift - cfg.Volatility*cfg.Volatility/2
+	for i := range points {
+		if i > 0 {
+			price *= math.Exp(step + cfg.Volatility*rng.NormFloat64())
+		}
+		points[i] = PricePoint{
+			Date:  cfg.Start.Add(time.Duration(i) * cfg.Interval),
+			Price: math.Round(price*10000) / 10000,
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,208 @@
+package generator
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestTransactionsReproducible tests that the same seed yields the same data
+func TestTransactionsReproducible(t *testing.T) {
+	a := mustTransactions(t, DefaultTransactionConfig(42, 1000))
+	b := mustTransactions(t, DefaultTransactionConfig(42, 1000))
+	c := mustTransactions(t, DefaultTransactionConfig(43, 1000))
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("Expected transaction %d to match, got %+v and %+v", i, a[i], b[i])
+		}
+	}
+	same := 0
+	for i := range a {
+		if a[i] == c[i] {
+			same++
+		}
+	}
+	if same == len(a) {
+		t.Errorf("Expected different seeds to produce different data")
+	}
+}
+
+// mustTransactions generates transactions and fails the test on error.
+func mustTransactions(t *testing.T, cfg TransactionConfig) []Transaction {
+	t.Helper()
+	transactions, err := Transactions(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transactions
+}
+
+// mustPrices generates prices and fails the test on error.
+func mustPrices(t *testing.T, cfg PriceConfig) []PricePoint {
+	t.Helper()
+	points, err := Prices(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return points
+}
+
+// TestTransactionsDefaultUniform tests that the default amounts stay uniform in (0, 1000)
+func TestTransactionsDefaultUniform(t *testing.T) {
+	transactions := mustTransactions(t, DefaultTransactionConfig(7, 20_000))
+	var sum float64
+	for _, tx := range transactions {
+		if tx.Amount <= 0 || tx.Amount >= 1000 {
+			t.Fatalf("Expected amounts in (0, 1000), got %v", tx.Amount)
+		}
+		sum += tx.Amount
+	}
+	if mean := sum / float64(len(transactions)); math.Abs(mean-500) > 10 {
+		t.Errorf("Expected mean amount near 500, got %.2f", mean)
+	}
+}
+
+// TestTransactionsRoundedBounds tests that rounding to cents never leaves the range or yields zero
+func TestTransactionsRoundedBounds(t *testing.T) {
+	narrow := DefaultTransactionConfig(3, 1000)
+	narrow.AmountMax = 0.02
+	for _, tx := range mustTransactions(t, narrow) {
+		if tx.Amount != 0.01 {
+			t.Fatalf("Expected 0.01 in [0, 0.02), got %v", tx.Amount)
+		}
+	}
+	upper := DefaultTransactionConfig(3, 1000)
+	upper.AmountMin, upper.AmountMax = 5, 5.005
+	for _, tx := range mustTransactions(t, upper) {
+		if tx.Amount != 5 {
+			t.Fatalf("Expected 5 in [5, 5.005), got %v", tx.Amount)
+		}
+	}
+	tiny := DefaultTransactionConfig(3, 1000)
+	tiny.Distribution = LogNormal
+	tiny.AmountMu = -10
+	for _, tx := range mustTransactions(t, tiny) {
+		if tx.Amount != 0.01 {
+			t.Fatalf("Expected tiny log-normal amounts to round up to 0.01, got %v", tx.Amount)
+		}
+	}
+}
+
+// TestTransactionsDistribution tests the credit ratio and log-normal median
+func TestTransactionsDistribution(t *testing.T) {
+	cfg := DefaultTransactionConfig(7, 20_000)
+	cfg.Distribution = LogNormal
+	cfg.CreditRatio = 0.8
+	transactions := mustTransactions(t, cfg)
+
+	credits := 0
+	amounts := make([]float64, len(transactions))
+	for i, tx := range transactions {
+		if tx.Type == "credit" {
+			credits++
+		}
+		if tx.Amount <= 0 {
+			t.Fatalf("Expected positive amounts, got %v", tx.Amount)
+		}
+		amounts[i] = tx.Amount
+	}
+	if ratio := float64(credits) / float64(len(transactions)); math.Abs(ratio-0.8) > 0.02 {
+		t.Errorf("Expected credit ratio near 0.8, got %.3f", ratio)
+	}
+	sort.Float64s(amounts)
+	median := amounts[len(amounts)/2]
+	if want := math.Exp(cfg.AmountMu); math.Abs(median-want)/want > 0.05 {
+		t.Errorf("Expected median amount near %.2f, got %.2f", want, median)
+	}
+}
+
+// TestPricesRandomWalk tests dates, the starting price and reproducibility of the price walk
+func TestPricesRandomWalk(t *testing.T) {
+	cfg := DefaultPriceConfig(1, 250)
+	points := mustPrices(t, cfg)
+	if len(points) != 250 || points[0].Price != cfg.InitialPrice || !points[0].Date.Equal(cfg.Start) {
+		t.Fatalf("Unexpected first point %+v", points[0])
+	}
+	for i := 1; i < len(points); i++ {
+		if got := points[i].Date.Sub(points[i-1].Date); got != cfg.Interval {
+			t.Fatalf("Expected interval %v at %d, got %v", cfg.Interval, i, got)
+		}
+		if points[i].Price <= 0 {
+			t.Fatalf("Expected positive prices, got %v", points[i].Price)
+		}
+	}
+	again := mustPrices(t, cfg)
+	if again[len(again)-1] != points[len(points)-1] {
+		t.Errorf("Expected the same seed to give the same last price")
+	}
+
+	cfg.Volatility = 0
+	cfg.Drift = 0.01
+	flat := mustPrices(t, cfg)
+	if want := cfg.InitialPrice * math.Exp(0.01*249); math.Abs(flat[249].Price-want) > 1e-3 {
+		t.Errorf("Expected pure drift to reach %.4f, got %.4f", want, flat[249].Price)
+	}
+}
+
+// TestInvalidConfigs tests that bad configurations return errors instead of panicking
+func TestInvalidConfigs(t *testing.T) {
+	negative := DefaultTransactionConfig(1, -1)
+	ratio := DefaultTransactionConfig(1, 10)
+	ratio.CreditRatio = 1.5
+	nanRatio := DefaultTransactionConfig(1, 10)
+	nanRatio.CreditRatio = math.NaN()
+	emptyRange := DefaultTransactionConfig(1, 10)
+	emptyRange.AmountMin = 10
+	emptyRange.AmountMax = 5
+	zeroRange := DefaultTransactionConfig(1, 10)
+	zeroRange.AmountMax = 0.01
+	nanMu := DefaultTransactionConfig(1, 10)
+	nanMu.Distribution = LogNormal
+	nanMu.AmountMu = math.NaN()
+	infMu := nanMu
+	infMu.AmountMu = math.Inf(1)
+	for name, cfg := range map[string]TransactionConfig{
+		"negative count": negative, "credit ratio": ratio, "NaN credit ratio": nanRatio, "empty range": emptyRange,
+		"zero-only range": zeroRange, "NaN mu": nanMu, "infinite mu": infMu,
+	} {
+		if _, err := Transactions(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := Prices(DefaultPriceConfig(1, -1)); err == nil {
+		t.Errorf("Expected an error for a negative price count")
+	}
+	if got := mustTransactions(t, DefaultTransactionConfig(1, 0)); len(got) != 0 {
+		t.Errorf("Expected no transactions for a zero count, got %d", len(got))
+	}
+}
+
+// TestWriters tests the CSV and JSONL encodings
+func TestWriters(t *testing.T) {
+	transactions := []Transaction{{ID: 1, Amount: 12.5, Type: "credit"}, {ID: 2, Amount: 3, Type: "debit"}}
+	var buf bytes.Buffer
+	if err := WriteTransactionsCSV(&buf, transactions); err != nil {
+		t.Fatal(err)
+	}
+	if want := "id,amount,type\n1,12.5,credit\n2,3,debit\n"; buf.String() != want {
+		t.Errorf("Expected CSV %q, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := WriteJSONL(&buf, transactions); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"id":1,"amount":12.5,"type":"credit"}`; !strings.HasPrefix(buf.String(), want+"\n") {
+		t.Errorf("Expected JSONL to start with %s, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := WritePricesCSV(&buf, mustPrices(t, DefaultPriceConfig(1, 2))); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "date,price\n2023-10-01T00:00:00Z,100\n") {
+		t.Errorf("Unexpected price CSV %q", buf.String())
+	}
+}
@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// WriteTransactionsCSV writes transactions as CSV with an id,amount,type header.
+func WriteTransactionsCSV(w io.Writer, transactions []Transaction) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "amount", "type"}); err != nil {
+		return err
+	}
+	for _, t := range transactions {
+		record := []string{
+			strconv.Itoa(t.ID),
+			strconv.FormatFloat(t.Amount, 'f', -1, 64),
+			t.Type,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WritePricesCSV writes prices as CSV with a date,price header. Dates are RFC 3339.
+func WritePricesCSV(w io.Writer, points []PricePoint) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"date", "price"}); err != nil {
+		return err
+	}
+	for _, p := range points {
+		record := []string{
+			p.Date.Format(time.RFC3339),
+			strconv.FormatFloat(p.Price, 'f', -1, 64),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSONL writes one JSON object per line.
+func WriteJSONL[T any](w io.Writer, records []T) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
@@ -2,10 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"math/rand"
+	"io"
+	"math"
+	"os"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"example.com/464826/ideal2/generator"
 )
 
 // Transaction represents a financial transaction
@@ -22,8 +30,17 @@ type FilterCriteria struct {
 }
 
 func main() {
+	columnar := flag.Bool("columnar", false, "filter a columnar copy of the data instead of the row form")
+	seed := flag.Int64("seed", 1, "seed for the generated transactions")
+	ledger := flag.Bool("ledger", false, "post the transactions to a ledger and filter its cash postings")
+	flag.Parse()
+
 	// Generate a large dataset of transactions
-	transactions := generateTransactions(1_000_000)
+	transactions, err := generateTransactions(1_000_000, *seed)
+	if err != nil {
+		fmt.Printf("Error generating transactions: %v\n", err)
+		return
+	}
 
 	// Define filter criteria
 	criteria := FilterCriteria{
@@ -31,15 +48,35 @@ func main() {
 		Type:      "credit",
 	}
 
+	if *columnar {
+		cols := toColumns(transactions)
+		selected := cols.Filter(criteria)
+		fmt.Printf("Processed %d transactions meeting criteria.\n", selected.Count())
+		fmt.Printf("Total amount: %.2f\n", cols.SumAmount(selected))
+		return
+	}
+
+	if *ledger {
+		if err := runLedger(os.Stdout, transactions, criteria); err != nil {
+			fmt.Printf("Error building ledger: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Create a context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	// Process the transactions concurrently
-	filteredTransactions, err := processTransactions(ctx, transactions, criteria)
+	// Process the transactions concurrently, keeping whatever completed
+	filteredTransactions, err := processTransactions(ctx, transactions, criteria, ProcessOptions{Mode: BestEffort})
 	if err != nil {
 		fmt.Printf("Error processing transactions: %v\n", err)
-		return
+		var perr *ProcessError
+		if !errors.As(err, &perr) {
+			return
+		}
+		fmt.Printf("Reporting partial results from %d of %d chunks.\n", perr.Completed, perr.Total)
 	}
 
 	// Display results
@@ -47,72 +84,268 @@ func main() {
 	fmt.Printf("Total amount: %.2f\n", aggregateAmount(filteredTransactions))
 }
 
-// generateTransactions generates a dataset of random transactions
-func generateTransactions(n int) []Transaction {
-	rand.Seed(time.Now().UnixNano())
-	types := []string{"credit", "debit"}
-	transactions := make([]Transaction, n)
-	for i := range transactions {
-		transactions[i] = Transaction{
-			ID:     i + 1,
-			Amount: rand.Float64() * 1000,
-			Type:   types[rand.Intn(len(types))],
+// runLedger posts one transaction per minute to a ledger and runs the
+// filter over the cash account's postings, writing the results to w.
+// Zero-amount transactions are skipped and counted.
+func runLedger(w io.Writer, transactions []Transaction, criteria FilterCriteria) error {
+	l := NewLedger()
+	for _, a := range []Account{
+		{Code: "cash", Name: "Cash", Type: Asset},
+		{Code: "income", Name: "Income", Type: Income},
+		{Code: "expense", Name: "Expenses", Type: Expense},
+	} {
+		if err := l.OpenAccount(a); err != nil {
+			return err
 		}
 	}
-	return transactions
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	skipped := 0
+	for i, t := range transactions {
+		posted, err := recordTransaction(l, t, start.Add(time.Duration(i)*time.Minute), "cash", "income", "expense")
+		if err != nil {
+			return err
+		}
+		if !posted {
+			skipped++
+		}
+	}
+	if skipped > 0 {
+		fmt.Fprintf(w, "Skipped %d zero-amount transactions.\n", skipped)
+	}
+
+	balance, err := l.Balance("cash", start.Add(time.Duration(len(transactions))*time.Minute))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Cash balance: %.2f\n", balance)
+
+	// Cash postings keep the row meaning, so this selects the same
+	// transactions as the row path.
+	filtered, err := processTransactions(context.Background(), postingsAsTransactions(l.AccountPostings("cash")), criteria, ProcessOptions{})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Processed %d cash postings meeting criteria.\n", len(filtered))
+	fmt.Fprintf(w, "Total amount: %.2f\n", aggregateAmount(filtered))
+	return nil
 }
 
-// processTransactions filters and transforms transactions concurrently
-func processTransactions(ctx context.Context, transactions []Transaction, criteria FilterCriteria) ([]Transaction, error) {
-	var wg sync.WaitGroup
-	results := make(chan Transaction, len(transactions))
-	errCh := make(chan error, 1)
-
-	// Divide transactions into chunks for concurrent processing
-	chunkSize := 10_000
-	for start := 0; start < len(transactions); start += chunkSize {
-		end := start + chunkSize
-		if end > len(transactions) {
-			end = len(transactions)
-		}
+// generateTransactions generates a reproducible dataset of random transactions
+func generateTransactions(n int, seed int64) ([]Transaction, error) {
+	generated, err := generator.Transactions(generator.DefaultTransactionConfig(seed, n))
+	if err != nil {
+		return nil, err
+	}
+	transactions := make([]Transaction, n)
+	for i, t := range generated {
+		transactions[i] = Transaction{ID: t.ID, Amount: t.Amount, Type: t.Type}
+	}
+	return transactions, nil
+}
+
+// ProcessMode selects how processTransactions reacts to a failing chunk.
+type ProcessMode int
+
+const (
+	// FailFast stops scheduling chunks as soon as one chunk fails.
+	FailFast ProcessMode = iota
+	// BestEffort keeps processing the remaining chunks and reports every failure.
+	BestEffort
+)
 
-		wg.Add(1)
-		go func(chunk []Transaction) {
+// ProcessOptions configures processTransactions.
+type ProcessOptions struct {
+	Mode      ProcessMode
+	ChunkSize int // transactions per chunk, defaults to 10_000
+	Workers   int // concurrent workers, defaults to runtime.NumCPU()
+	// Validate, if set, is called on every transaction before it is
+	// filtered; an error fails the transaction's chunk. By default nothing
+	// is rejected, so refunds, negative amounts and other type labels are
+	// filtered like any other transaction.
+	Validate func(Transaction) error
+}
+
+// ChunkError records why a single chunk did not complete.
+type ChunkError struct {
+	Index      int // chunk index, in input order
+	Start, End int // transaction range [Start, End) covered by the chunk
+	Err        error
+}
+
+func (e ChunkError) Error() string {
+	return fmt.Sprintf("chunk %d [%d:%d]: %v", e.Index, e.Start, e.End, e.Err)
+}
+
+func (e ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// ProcessError describes a run in which not every chunk completed.
+// The results returned alongside it contain the matches from the
+// Completed chunks only, still in input order.
+type ProcessError struct {
+	Total     int          // number of chunks the input was split into
+	Completed int          // chunks whose matches are in the partial result
+	Skipped   int          // chunks that were never started
+	Failed    []ChunkError // chunks that started but failed, ordered by Index
+	Cause     error        // context error that stopped the run, if any
+}
+
+func (e *ProcessError) Error() string {
+	msg := fmt.Sprintf("processed %d of %d chunks (%d failed, %d skipped)", e.Completed, e.Total, len(e.Failed), e.Skipped)
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	} else if len(e.Failed) > 0 {
+		msg += ": " + e.Failed[0].Error()
+	}
+	return msg
+}
+
+// Unwrap exposes the cause and every chunk error to errors.Is and errors.As.
+func (e *ProcessError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed)+1)
+	if e.Cause != nil {
+		errs = append(errs, e.Cause)
+	}
+	for _, f := range e.Failed {
+		errs = append(errs, f)
+	}
+	return errs
+}
+
+// errInvalidTransaction is reported for transactions that cannot be filtered.
+var errInvalidTransaction = errors.New("invalid transaction")
+
+// validateTransaction is a strict Validate option for data that should only
+// hold non-negative, finite credits and debits.
+func validateTransaction(t Transaction) error {
+	if math.IsNaN(t.Amount) || math.IsInf(t.Amount, 0) || t.Amount < 0 {
+		return fmt.Errorf("%w: id %d has amount %v", errInvalidTransaction, t.ID, t.Amount)
+	}
+	if t.Type != "credit" && t.Type != "debit" {
+		return fmt.Errorf("%w: id %d has type %q", errInvalidTransaction, t.ID, t.Type)
+	}
+	return nil
+}
+
+// chunk states tracked by processTransactions.
+const (
+	chunkPending = iota
+	chunkDone
+	chunkFailed
+)
+
+// ctxCheckInterval is how many transactions a worker filters between
+// cancellation checks.
+const ctxCheckInterval = 1024
+
+// processTransactions filters transactions concurrently and returns the
+// matches in input order. It never returns before every worker has
+// exited. If any chunk does not complete, the matches from the chunks
+// that did are returned together with a *ProcessError.
+func processTransactions(ctx context.Context, transactions []Transaction, criteria FilterCriteria, opts ProcessOptions) ([]Transaction, error) {
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = 10_000
+	}
+	numChunks := (len(transactions) + chunkSize - 1) / chunkSize
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > numChunks {
+		workers = numChunks
+	}
+
+	// In fail-fast mode the first failure cancels runCtx so that the
+	// other workers stop early; the caller's ctx is left untouched.
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Each chunk owns its slot in these slices, so workers never
+	// communicate through channels and can never block on a send.
+	matches := make([][]Transaction, numChunks)
+	states := make([]int, numChunks)
+	errs := make([]error, numChunks)
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
 			defer wg.Done()
-			for _, t := range chunk {
-				select {
-				case <-ctx.Done():
-					errCh <- ctx.Err()
+			for {
+				idx := int(next.Add(1) - 1)
+				if idx >= numChunks || runCtx.Err() != nil {
 					return
-				default:
-					if t.Amount >= criteria.MinAmount && t.Type == criteria.Type {
-						results <- t
+				}
+				start := idx * chunkSize
+				end := min(start+chunkSize, len(transactions))
+				matched, err := filterChunk(runCtx, transactions[start:end], criteria, opts.Validate)
+				if err != nil {
+					states[idx], errs[idx] = chunkFailed, err
+					if opts.Mode == FailFast {
+						cancel()
 					}
+					continue
 				}
+				states[idx], matches[idx] = chunkDone, matched
 			}
-		}(transactions[start:end])
+		}()
 	}
+	wg.Wait()
 
-	// Wait for all goroutines to finish
-	go func() {
-		wg.Wait()
-		close(results)
-		close(errCh)
-	}()
-
-	// Collect results or handle errors
 	var filtered []Transaction
-	for {
-		select {
-		case t, ok := <-results:
-			if !ok {
-				return filtered, nil
+	perr := &ProcessError{Total: numChunks, Cause: ctx.Err()}
+	for idx, state := range states {
+		switch state {
+		case chunkDone:
+			perr.Completed++
+			filtered = append(filtered, matches[idx]...)
+		case chunkFailed:
+			// Chunks interrupted by our own fail-fast cancellation are
+			// reported as skipped; only real failures are listed.
+			if ctx.Err() == nil && errors.Is(errs[idx], context.Canceled) {
+				perr.Skipped++
+				continue
 			}
-			filtered = append(filtered, t)
-		case err := <-errCh:
-			return nil, err
+			start := idx * chunkSize
+			perr.Failed = append(perr.Failed, ChunkError{
+				Index: idx,
+				Start: start,
+				End:   min(start+chunkSize, len(transactions)),
+				Err:   errs[idx],
+			})
+		default:
+			perr.Skipped++
+		}
+	}
+	if perr.Completed == numChunks {
+		return filtered, nil
+	}
+	return filtered, perr
+}
+
+// filterChunk returns the transactions in chunk that match criteria. If
+// validate is not nil, the first transaction it rejects fails the chunk.
+func filterChunk(ctx context.Context, chunk []Transaction, criteria FilterCriteria, validate func(Transaction) error) ([]Transaction, error) {
+	var matched []Transaction
+	for i, t := range chunk {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if validate != nil {
+			if err := validate(t); err != nil {
+				return nil, err
+			}
+		}
+		if t.Amount >= criteria.MinAmount && t.Type == criteria.Type {
+			matched = append(matched, t)
 		}
 	}
+	return matched, nil
 }
 
 // aggregateAmount calculates the total amount of filtered transactions
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// verifyNoLeaks fails the test if goroutines started during the test are
+// still running shortly after it finishes, in the spirit of goleak.
+func verifyNoLeaks(t *testing.T) {
+	t.Helper()
+	before := runtime.NumGoroutine()
+	t.Cleanup(func() {
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > before {
+			if time.Now().After(deadline) {
+				buf := make([]byte, 1<<16)
+				n := runtime.Stack(buf, true)
+				t.Errorf("leaked goroutines: %d before, %d after\n%s", before, runtime.NumGoroutine(), buf[:n])
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
+
+// sequentialTransactions builds n valid transactions alternating credit and debit.
+func sequentialTransactions(n int) []Transaction {
+	transactions := make([]Transaction, n)
+	for i := range transactions {
+		typ := "credit"
+		if i%2 == 1 {
+			typ = "debit"
+		}
+		transactions[i] = Transaction{ID: i + 1, Amount: float64(i % 200), Type: typ}
+	}
+	return transactions
+}
+
+// TestProcessTransactionsComplete tests that a full run returns every match in input order
+func TestProcessTransactionsComplete(t *testing.T) {
+	verifyNoLeaks(t)
+	transactions := sequentialTransactions(25_000)
+	criteria := FilterCriteria{MinAmount: 100, Type: "credit"}
+
+	got, err := processTransactions(context.Background(), transactions, criteria, ProcessOptions{ChunkSize: 1000, Workers: 8})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var want []Transaction
+	for _, tx := range transactions {
+		if tx.Amount >= criteria.MinAmount && tx.Type == criteria.Type {
+			want = append(want, tx)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d matches, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected match %d to be %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+// TestProcessTransactionsFailFast tests that an invalid chunk stops the run and keeps completed chunks
+func TestProcessTransactionsFailFast(t *testing.T) {
+	verifyNoLeaks(t)
+	transactions := sequentialTransactions(10_000)
+	transactions[5_500].Type = "refund"
+
+	got, err := processTransactions(context.Background(), transactions, FilterCriteria{Type: "credit"}, ProcessOptions{Mode: FailFast, ChunkSize: 1000, Workers: 1, Validate: validateTransaction})
+	var perr *ProcessError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Expected *ProcessError, got %v", err)
+	}
+	if !errors.Is(err, errInvalidTransaction) {
+		t.Errorf("Expected error to wrap errInvalidTransaction, got %v", err)
+	}
+	// With a single worker chunks run in order: 0-4 complete, 5 fails, 6-9 are skipped.
+	if perr.Total != 10 || perr.Completed != 5 || perr.Skipped != 4 || len(perr.Failed) != 1 {
+		t.Fatalf("Unexpected chunk accounting: %+v", perr)
+	}
+	if perr.Failed[0].Index != 5 || perr.Failed[0].Start != 5000 || perr.Failed[0].End != 6000 {
+		t.Errorf("Unexpected failed chunk: %+v", perr.Failed[0])
+	}
+	if len(got) != 2500 {
+		t.Errorf("Expected 2500 partial matches, got %d", len(got))
+	}
+}
+
+// TestProcessTransactionsBestEffort tests that every failing chunk is reported while the rest complete
+func TestProcessTransactionsBestEffort(t *testing.T) {
+	verifyNoLeaks(t)
+	transactions := sequentialTransactions(10_000)
+	transactions[1_200].Amount = -1
+	transactions[7_900].Type = ""
+
+	got, err := processTransactions(context.Background(), transactions, FilterCriteria{Type: "debit"}, ProcessOptions{Mode: BestEffort, ChunkSize: 1000, Workers: 4, Validate: validateTransaction})
+	var perr *ProcessError
+	if !errors.As(err, &perr) {
+		t.Fatalf("Expected *ProcessError, got %v", err)
+	}
+	if perr.Completed != 8 || perr.Skipped != 0 || len(perr.Failed) != 2 {
+		t.Fatalf("Unexpected chunk accounting: %+v", perr)
+	}
+	if perr.Failed[0].Index != 1 || perr.Failed[1].Index != 7 {
+		t.Errorf("Expected failed chunks 1 and 7, got %d and %d", perr.Failed[0].Index, perr.Failed[1].Index)
+	}
+	if len(got) != 4000 {
+		t.Errorf("Expected 4000 partial matches, got %d", len(got))
+	}
+	if !strings.Contains(err.Error(), "processed 8 of 10 chunks") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+// TestProcessTransactionsNoValidation tests that without a Validate option
+// refunds, negative amounts and other type labels are filtered, not rejected
+func TestProcessTransactionsNoValidation(t *testing.T) {
+	transactions := []Transaction{
+		{ID: 1, Amount: 150, Type: "refund"},
+		{ID: 2, Amount: -20, Type: "credit"},
+		{ID: 3, Amount: 120, Type: "credit"},
+	}
+	got, err := processTransactions(context.Background(), transactions, FilterCriteria{MinAmount: -50, Type: "credit"}, ProcessOptions{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(got) != 2 || got[0].ID != 2 || got[1].ID != 3 {
+		t.Errorf("Expected transactions 2 and 3, got %+v", got)
+	}
+	got, err = processTransactions(context.Background(), transactions, FilterCriteria{Type: "refund"}, ProcessOptions{})
+	if err != nil || len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("Expected the refund to match, got %+v, %v", got, err)
+	}
+}
+
+// TestProcessTransactionsCanceled tests that cancellation returns promptly without leaking workers
+func TestProcessTransactionsCanceled(t *testing.T) {
+	verifyNoLeaks(t)
+	transactions := sequentialTransactions(200_000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	got, err := processTransactions(ctx, transactions, FilterCriteria{Type: "credit"}, ProcessOptions{ChunkSize: 100, Workers: 16})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Expected no matches from a pre-canceled run, got %d", len(got))
+	}
+
+	for _, mode := range []ProcessMode{FailFast, BestEffort} {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		got, err := processTransactions(ctx, transactions, FilterCriteria{Type: "credit"}, ProcessOptions{Mode: mode, ChunkSize: 100, Workers: 16})
+		cancel()
+		if err == nil {
+			// The machine was fast enough to finish; nothing to check.
+			continue
+		}
+		var perr *ProcessError
+		if !errors.As(err, &perr) || !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected *ProcessError wrapping DeadlineExceeded, got %v", err)
+		}
+		if perr.Completed+perr.Skipped+len(perr.Failed) != perr.Total {
+			t.Errorf("Chunk accounting does not add up: %+v", perr)
+		}
+		if len(got) > perr.Completed*50 {
+			t.Errorf("Expected at most %d partial matches, got %d", perr.Completed*50, len(got))
+		}
+	}
+}
+
+// TestProcessTransactionsEmpty tests that an empty input is not an error
+func TestProcessTransactionsEmpty(t *testing.T) {
+	got, err := processTransactions(context.Background(), nil, FilterCriteria{Type: "credit"}, ProcessOptions{})
+	if err != nil || len(got) != 0 {
+		t.Errorf("Expected no matches and no error, got %d, %v", len(got), err)
+	}
+}
+
+// TestColumnarRoundTrip tests conversion between row and columnar form
+func TestColumnarRoundTrip(t *testing.T) {
+	transactions := sequentialTransactions(1000)
+	transactions[10].Type = "fee"
+
+	cols := toColumns(transactions)
+	if cols.Len() != len(transactions) || len(cols.Dict) != 3 {
+		t.Fatalf("Expected %d rows and 3 types, got %d rows and %v", len(transactions), cols.Len(), cols.Dict)
+	}
+	rows := cols.Rows()
+	for i := range transactions {
+		if rows[i] != transactions[i] {
+			t.Fatalf("Expected row %d to be %+v, got %+v", i, transactions[i], rows[i])
+		}
+	}
+}
+
+// TestColumnarManyTypes tests that free-text types beyond 256 distinct values are encoded
+func TestColumnarManyTypes(t *testing.T) {
+	transactions := make([]Transaction, 1000)
+	for i := range transactions {
+		transactions[i] = Transaction{ID: i, Amount: float64(i), Type: fmt.Sprintf("type-%d", i%300)}
+	}
+	cols := toColumns(transactions)
+	if len(cols.Dict) != 300 {
+		t.Fatalf("Expected 300 types, got %d", len(cols.Dict))
+	}
+	if got := cols.Filter(FilterCriteria{Type: "type-299"}).Count(); got != 3 {
+		t.Errorf("Expected 3 rows of type-299, got %d", got)
+	}
+	if row := cols.Row(599); row != transactions[599] {
+		t.Errorf("Expected row 599 to be %+v, got %+v", transactions[599], row)
+	}
+}
+
+// TestColumnarFilter tests that the columnar kernels select the same rows as the row path
+func TestColumnarFilter(t *testing.T) {
+	// 1000 is not a multiple of 64, so the tail block is exercised too.
+	transactions := sequentialTransactions(1000)
+	cols := toColumns(transactions)
+
+	for _, criteria := range []FilterCriteria{
+		{MinAmount: 100, Type: "credit"},
+		{MinAmount: 0, Type: "debit"},
+		{MinAmount: 150.5, Type: "debit"},
+		{MinAmount: 0, Type: "refund"},
+	} {
+		want, err := processTransactions(context.Background(), transactions, criteria, ProcessOptions{ChunkSize: 64})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		selected := cols.Filter(criteria)
+		got := cols.Select(selected)
+		if len(got) != len(want) || selected.Count() != len(want) {
+			t.Fatalf("%+v: expected %d matches, got %d", criteria, len(want), len(got))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%+v: expected match %d to be %+v, got %+v", criteria, i, want[i], got[i])
+			}
+		}
+		if sum := cols.SumAmount(selected); sum != aggregateAmount(want) {
+			t.Errorf("%+v: expected total %.2f, got %.2f", criteria, aggregateAmount(want), sum)
+		}
+	}
+}
+
+// benchmarkSize is the number of generated transactions used by the filter benchmarks.
+const benchmarkSize = 1_000_000
+
+// benchmarkTransactions generates the benchmark dataset.
+func benchmarkTransactions(b *testing.B) []Transaction {
+	b.Helper()
+	transactions, err := generateTransactions(benchmarkSize, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return transactions
+}
+
+// BenchmarkFilterRows benchmarks the concurrent row-form filter
+func BenchmarkFilterRows(b *testing.B) {
+	transactions := benchmarkTransactions(b)
+	criteria := FilterCriteria{MinAmount: 100, Type: "credit"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := processTransactions(context.Background(), transactions, criteria, ProcessOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkFilterRowsSequential benchmarks a single-goroutine scan of the row form
+func BenchmarkFilterRowsSequential(b *testing.B) {
+	transactions := benchmarkTransactions(b)
+	criteria := FilterCriteria{MinAmount: 100, Type: "credit"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := filterChunk(context.Background(), transactions, criteria, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkFilterColumns benchmarks the columnar kernels producing a bitmap
+func BenchmarkFilterColumns(b *testing.B) {
+	cols := toColumns(benchmarkTransactions(b))
+	criteria := FilterCriteria{MinAmount: 100, Type: "credit"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = cols.Filter(criteria)
+	}
+}
+
+// BenchmarkFilterColumnsSelect benchmarks the columnar filter including materialising the rows
+func BenchmarkFilterColumnsSelect(b *testing.B) {
+	cols := toColumns(benchmarkTransactions(b))
+	criteria := FilterCriteria{MinAmount: 100, Type: "credit"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = cols.Select(cols.Filter(criteria))
+	}
+}
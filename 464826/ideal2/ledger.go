@@ -0,0 +1,233 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AccountType classifies a ledger account.
+type AccountType int
+
+const (
+	Asset AccountType = iota
+	Liability
+	Equity
+	Income
+	Expense
+)
+
+// Account is a named bucket that postings are made against.
+type Account struct {
+	Code string
+	Name string
+	Type AccountType
+}
+
+// PostingLine is one side of a ledger entry. Positive amounts are debits,
+// negative amounts are credits.
+type PostingLine struct {
+	Account string
+	Amount  float64
+}
+
+// LedgerEntry is a double-entry journal entry. Its lines must sum to zero.
+type LedgerEntry struct {
+	ID          int // transaction ID, unique within the ledger
+	Date        time.Time
+	Description string
+	Lines       []PostingLine
+}
+
+// Posting is a posted line, flattened with its entry's ID and date.
+type Posting struct {
+	TxID    int
+	Date    time.Time
+	Account string
+	Amount  float64
+}
+
+var (
+	ErrUnknownAccount = errors.New("unknown account")
+	ErrUnbalanced     = errors.New("unbalanced entry")
+	ErrDuplicateEntry = errors.New("duplicate entry")
+	ErrInvalidEntry   = errors.New("invalid entry")
+)
+
+// Ledger is a thread-safe double-entry ledger. Entries are immutable once
+// posted.
+type Ledger struct {
+	mu       sync.RWMutex
+	accounts map[string]Account
+	entries  map[int]LedgerEntry
+	postings []Posting // ordered by date, then insertion
+}
+
+// NewLedger creates an empty ledger.
+func NewLedger() *Ledger {
+	return &Ledger{
+		accounts: make(map[string]Account),
+		entries:  make(map[int]LedgerEntry),
+	}
+}
+
+// OpenAccount registers an account. Opening an existing code again is an error.
+func (l *Ledger) OpenAccount(a Account) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if a.Code == "" {
+		return fmt.Errorf("%w: account code is empty", ErrInvalidEntry)
+	}
+	if _, exists := l.accounts[a.Code]; exists {
+		return fmt.Errorf("account %q already exists", a.Code)
+	}
+	l.accounts[a.Code] = a
+	return nil
+}
+
+// toCents converts an amount to integer cents so balances add up exactly.
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// Post validates and records entry. Posting an entry whose ID is already
+// in the ledger is a no-op when the entry is identical, which makes
+// retries safe, and fails with ErrDuplicateEntry otherwise.
+func (l *Ledger) Post(entry LedgerEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if existing, ok := l.entries[entry.ID]; ok {
+		if sameEntry(existing, entry) {
+			return nil
+		}
+		return fmt.Errorf("%w: transaction %d was already posted with different content", ErrDuplicateEntry, entry.ID)
+	}
+	if err := l.validate(entry); err != nil {
+		return err
+	}
+
+	entry.Lines = slices.Clone(entry.Lines)
+	l.entries[entry.ID] = entry
+	posted := make([]Posting, len(entry.Lines))
+	for i, line := range entry.Lines {
+		posted[i] = Posting{TxID: entry.ID, Date: entry.Date, Account: line.Account, Amount: line.Amount}
+	}
+	// Keep postings ordered by date so point-in-time queries can stop
+	// early. Entries usually arrive in date order, making this an append.
+	at := sort.Search(len(l.postings), func(i int) bool {
+		return l.postings[i].Date.After(entry.Date)
+	})
+	l.postings = slices.Insert(l.postings, at, posted...)
+	return nil
+}
+
+func (l *Ledger) validate(entry LedgerEntry) error {
+	if len(entry.Lines) < 2 {
+		return fmt.Errorf("%w: transaction %d needs at least two lines", ErrInvalidEntry, entry.ID)
+	}
+	if entry.Date.IsZero() {
+		return fmt.Errorf("%w: transaction %d has no date", ErrInvalidEntry, entry.ID)
+	}
+	var total int64
+	for _, line := range entry.Lines {
+		if _, ok := l.accounts[line.Account]; !ok {
+			return fmt.Errorf("%w: %q in transaction %d", ErrUnknownAccount, line.Account, entry.ID)
+		}
+		if math.IsNaN(line.Amount) || math.IsInf(line.Amount, 0) || toCents(line.Amount) == 0 {
+			return fmt.Errorf("%w: transaction %d has amount %v on %q", ErrInvalidEntry, entry.ID, line.Amount, line.Account)
+		}
+		total += toCents(line.Amount)
+	}
+	if total != 0 {
+		return fmt.Errorf("%w: transaction %d is off by %.2f", ErrUnbalanced, entry.ID, float64(total)/100)
+	}
+	return nil
+}
+
+func sameEntry(a, b LedgerEntry) bool {
+	return a.ID == b.ID && a.Date.Equal(b.Date) && a.Description == b.Description && slices.Equal(a.Lines, b.Lines)
+}
+
+// Balance returns the net debit balance of account including every
+// posting dated at or before at.
+func (l *Ledger) Balance(account string, at time.Time) (float64, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if _, ok := l.accounts[account]; !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownAccount, account)
+	}
+	var cents int64
+	for _, p := range l.postings {
+		if p.Date.After(at) {
+			break
+		}
+		if p.Account == account {
+			cents += toCents(p.Amount)
+		}
+	}
+	return float64(cents) / 100, nil
+}
+
+// Postings returns a copy of every posting, ordered by date.
+func (l *Ledger) Postings() []Posting {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return slices.Clone(l.postings)
+}
+
+// AccountPostings returns the postings made against account, ordered by date.
+func (l *Ledger) AccountPostings(account string) []Posting {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var postings []Posting
+	for _, p := range l.postings {
+		if p.Account == account {
+			postings = append(postings, p)
+		}
+	}
+	return postings
+}
+
+// postingsAsTransactions views the postings of an asset account such as
+// cash as transactions, so that processTransactions and aggregateAmount can
+// run over them. Types keep the row path's meaning, as in
+// recordTransaction: money coming in is a "credit" and money going out a
+// "debit", both with positive amounts. So the same criteria select the same
+// transactions on either path, even though in ledger terms the inflow is a
+// debit to the account.
+func postingsAsTransactions(postings []Posting) []Transaction {
+	transactions := make([]Transaction, len(postings))
+	for i, p := range postings {
+		t := Transaction{ID: p.TxID, Amount: p.Amount, Type: "credit"}
+		if p.Amount < 0 {
+			t.Amount, t.Type = -p.Amount, "debit"
+		}
+		transactions[i] = t
+	}
+	return transactions
+}
+
+// recordTransaction posts t against cash: credits are income received
+// into cash, debits are expenses paid from cash. A zero amount moves no
+// money and cannot be a ledger line, so it is skipped and posted reports
+// false.
+func recordTransaction(l *Ledger, t Transaction, date time.Time, cash, income, expense string) (posted bool, err error) {
+	entry := LedgerEntry{ID: t.ID, Date: date, Description: t.Type}
+	switch t.Type {
+	case "credit":
+		entry.Lines = []PostingLine{{Account: cash, Amount: t.Amount}, {Account: income, Amount: -t.Amount}}
+	case "debit":
+		entry.Lines = []PostingLine{{Account: expense, Amount: t.Amount}, {Account: cash, Amount: -t.Amount}}
+	default:
+		return false, fmt.Errorf("%w: transaction %d has type %q", ErrInvalidEntry, t.ID, t.Type)
+	}
+	if toCents(t.Amount) == 0 && !math.IsNaN(t.Amount) {
+		return false, nil
+	}
+	return true, l.Post(entry)
+}
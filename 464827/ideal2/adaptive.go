@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+// AdaptiveOptions controls when an adaptive session stops.
+type AdaptiveOptions struct {
+	MaxItems int     // stop after this many questions, defaults to all
+	TargetSE float64 // stop once the ability standard error drops below this
+}
+
+// AdaptiveSession administers questions one at a time, each time picking
+// the unanswered question that is most informative at the respondent's
+// current ability estimate.
+type AdaptiveSession struct {
+	quiz      *Quiz
+	items     []ItemParams // aligned with quiz.Questions
+	opts      AdaptiveOptions
+	asked     map[int]bool // question index -> administered
+	responses []itemResponse
+	theta, se float64
+}
+
+// NewAdaptiveSession starts a session over quiz using fitted parameters.
+// Questions without parameters in fit are never administered.
+func NewAdaptiveSession(quiz *Quiz, fit *IRTFit, opts AdaptiveOptions) *AdaptiveSession {
+	s := &AdaptiveSession{quiz: quiz, opts: opts, asked: make(map[int]bool)}
+	s.items = make([]ItemParams, len(quiz.Questions))
+	for i, question := range quiz.Questions {
+		params, ok := fit.Item(question.ID)
+		if !ok {
+			s.asked[i] = true
+		}
+		s.items[i] = params
+	}
+	if s.opts.MaxItems <= 0 || s.opts.MaxItems > len(quiz.Questions) {
+		s.opts.MaxItems = len(quiz.Questions)
+	}
+	s.theta, s.se = EstimateAbility(s.items, nil)
+	return s
+}
+
+// Ability returns the current ability estimate and its standard error.
+func (s *AdaptiveSession) Ability() (theta, se float64) {
+	return s.theta, s.se
+}
+
+// Administered returns the number of questions answered so far.
+func (s *AdaptiveSession) Administered() int {
+	return len(s.responses)
+}
+
+// Done reports whether the session has met a stopping rule.
+func (s *AdaptiveSession) Done() bool {
+	if len(s.responses) >= s.opts.MaxItems || len(s.asked) == len(s.items) {
+		return true
+	}
+	return s.opts.TargetSE > 0 && s.se <= s.opts.TargetSE
+}
+
+// Next returns the question that maximises information at the current
+// ability estimate, or false when the session is done.
+func (s *AdaptiveSession) Next() (Question, bool) {
+	if s.Done() {
+		return Question{}, false
+	}
+	best, bestInfo := -1, -1.0
+	for i, item := range s.items {
+		if s.asked[i] {
+			continue
+		}
+		if info := item.Information(s.theta); info > bestInfo {
+			best, bestInfo = i, info
+		}
+	}
+	return s.quiz.Questions[best], true
+}
+
+// Record scores an answer to questionID and updates the ability estimate.
+func (s *AdaptiveSession) Record(questionID int, answer string) (bool, error) {
+	i, ok := s.quiz.byID[questionID]
+	if !ok {
+		return false, fmt.Errorf("adaptive: unknown question %d", questionID)
+	}
+	if s.asked[i] {
+		return false, fmt.Errorf("adaptive: question %d was already administered", questionID)
+	}
+	correct := s.quiz.isCorrect(s.quiz.Questions[i], ResponseRecord{QuestionID: questionID, Answer: answer})
+	s.asked[i] = true
+	s.responses = append(s.responses, itemResponse{item: i, correct: correct})
+	s.theta, s.se = EstimateAbility(s.items, s.responses)
+	return correct, nil
+}
+
+// SimulatedRespondent answers according to the IRT model at a known ability.
+type SimulatedRespondent struct {
+	Theta float64
+	rng   *rand.Rand
+}
+
+// NewSimulatedRespondent creates a respondent with a reproducible random source.
+func NewSimulatedRespondent(theta float64, seed int64) *SimulatedRespondent {
+	return &SimulatedRespondent{Theta: theta, rng: rand.New(rand.NewSource(seed))}
+}
+
+// Answer returns the correct answer with the model probability and a
+// wrong answer otherwise.
+func (r *SimulatedRespondent) Answer(question Question, params ItemParams) string {
+	if r.rng.Float64() < params.Probability(r.Theta) {
+		return question.CorrectAnswer
+	}
+	return "wrong answer"
+}
+
+// simulateSession runs a full adaptive session for respondent and returns
+// the final estimate and the number of questions used.
+func simulateSession(quiz *Quiz, fit *IRTFit, respondent *SimulatedRespondent, opts AdaptiveOptions) (theta, se float64, used int, err error) {
+	session := NewAdaptiveSession(quiz, fit, opts)
+	for {
+		question, ok := session.Next()
+		if !ok {
+			break
+		}
+		params, _ := fit.Item(question.ID)
+		if _, err := session.Record(question.ID, respondent.Answer(question, params)); err != nil {
+			return 0, 0, session.Administered(), err
+		}
+	}
+	theta, se = session.Ability()
+	return theta, se, session.Administered(), nil
+}
+
+// AdaptiveSimulation summarises simulated adaptive sessions.
+type AdaptiveSimulation struct {
+	Runs      int
+	MeanItems float64 // questions administered per session
+	MeanSE    float64 // final standard error
+	MAE       float64 // mean absolute error of the ability estimate
+	RMSE      float64 // root mean squared error of the ability estimate
+}
+
+// simulateAdaptive runs sessions for runs simulated respondents of standard
+// normal ability and compares the estimates with their true abilities.
+func simulateAdaptive(quiz *Quiz, fit *IRTFit, opts AdaptiveOptions, runs int, seed int64) (AdaptiveSimulation, error) {
+	if runs <= 0 {
+		return AdaptiveSimulation{}, fmt.Errorf("adaptive: runs must be positive, got %d", runs)
+	}
+	rng := rand.New(rand.NewSource(seed))
+	sim := AdaptiveSimulation{Runs: runs}
+	var absErr, sqErr float64
+	for r := 0; r < runs; r++ {
+		trueTheta := rng.NormFloat64()
+		theta, se, used, err := simulateSession(quiz, fit, NewSimulatedRespondent(trueTheta, rng.Int63()), opts)
+		if err != nil {
+			return sim, fmt.Errorf("adaptive: run %d: %w", r+1, err)
+		}
+		sim.MeanItems += float64(used)
+		sim.MeanSE += se
+		absErr += math.Abs(theta - trueTheta)
+		sqErr += (theta - trueTheta) * (theta - trueTheta)
+	}
+	n := float64(runs)
+	sim.MeanItems /= n
+	sim.MeanSE /= n
+	sim.MAE = absErr / n
+	sim.RMSE = math.Sqrt(sqErr / n)
+	return sim, nil
+}
+
+// runAdaptiveSession administers a session interactively: it writes each
+// question to out, reads one answer per line from in, and reports the
+// ability estimate after every answer and at the end.
+func runAdaptiveSession(quiz *Quiz, fit *IRTFit, opts AdaptiveOptions, in io.Reader, out io.Writer) error {
+	session := NewAdaptiveSession(quiz, fit, opts)
+	scanner := bufio.NewScanner(in)
+	for {
+		question, ok := session.Next()
+		if !ok {
+			break
+		}
+		fmt.Fprintf(out, "Question %d: %s\n> ", question.ID, question.Text)
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return err
+			}
+			fmt.Fprintln(out)
+			break
+		}
+		correct, err := session.Record(question.ID, strings.TrimSpace(scanner.Text()))
+		if err != nil {
+			return err
+		}
+		verdict := "Incorrect"
+		if correct {
+			verdict = "Correct"
+		}
+		theta, se := session.Ability()
+		fmt.Fprintf(out, "%s. Ability %.2f (SE %.2f)\n", verdict, theta, se)
+	}
+	theta, se := session.Ability()
+	fmt.Fprintf(out, "Estimated ability %.2f (SE %.2f) after %d questions\n", theta, se, session.Administered())
+	return nil
+}
+
+// simulateQuiz generates a quiz with the given item parameters answered
+// in full by n respondents of standard normal ability. It is used to test
+// fitting and to run adaptive sessions offline.
+func simulateQuiz(items []ItemParams, n int, seed int64) (*Quiz, error) {
+	rng := rand.New(rand.NewSource(seed))
+	questions := make([]Question, len(items))
+	for i, item := range items {
+		questions[i] = Question{ID: item.QuestionID, Text: fmt.Sprintf("Question %d", item.QuestionID), CorrectAnswer: "right"}
+	}
+	respondents := make([]Respondent, n)
+	attempts := make([]Attempt, n)
+	for p := 0; p < n; p++ {
+		id := fmt.Sprintf("sim%d", p+1)
+		respondent := NewSimulatedRespondent(rng.NormFloat64(), rng.Int63())
+		respondents[p] = Respondent{ID: id}
+		attempts[p] = Attempt{ID: id + "-1", RespondentID: id}
+		for i, item := range items {
+			attempts[p].Records = append(attempts[p].Records, ResponseRecord{
+				QuestionID: item.QuestionID,
+				Answer:     respondent.Answer(questions[i], item),
+			})
+		}
+	}
+	return NewQuiz(questions, respondents, attempts)
+}
@@ -0,0 +1,5 @@
+module example.com/464827/ideal2
+
+go 1.23.4
+
+require golang.org/x/text v0.21.0
@@ -1,64 +1,86 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"strings"
+	"net/http"
+	"os"
 	"time"
 )
 
-// Define a simple struct to represent a question
+// Question is a single quiz question. Responses to it live in the
+// quiz's attempts, keyed by ID.
 type Question struct {
-	Text     string
-	CorrectAnswer string
-	Responses   []string
-	ResponseTimes []time.Duration
-	Skipped     []bool
+	ID              int
+	Text            string
+	CorrectAnswer   string
+	AcceptedAnswers []string // alternatives also marked correct
+}
+
+// accepted returns every answer that is marked correct.
+func (q Question) accepted() []string {
+	return append([]string{q.CorrectAnswer}, q.AcceptedAnswers...)
 }
 
 // Calculate the total number of correct answers for all questions
-func countCorrectAnswers(questions []Question) int {
+func countCorrectAnswers(quiz *Quiz) int {
 	correctCount := 0
-	for _, question := range questions {
-		correctCount += countCorrectAnswersForQuestion(question)
+	for _, question := range quiz.Questions {
+		correctCount += countCorrectAnswersForQuestion(quiz, question)
 	}
 	return correctCount
 }
 
 // Calculate the number of correct answers for a single question
-func countCorrectAnswersForQuestion(question Question) int {
+func countCorrectAnswersForQuestion(quiz *Quiz, question Question) int {
 	correctCount := 0
-	for i, response := range question.Responses {
-		if !question.Skipped[i] && strings.ToLower(response) == strings.ToLower(question.CorrectAnswer) {
+	for _, r := range quiz.recordsFor(question.ID) {
+		if quiz.isCorrect(question, r) {
 			correctCount++
 		}
 	}
 	return correctCount
 }
 
-// Identify the most common incorrect answers for all questions
-func findMostCommonIncorrectAnswers(questions []Question) map[string]int {
+// Identify the most common incorrect answers for all questions. Near
+// misses of one another are counted under the most frequent spelling.
+func findMostCommonIncorrectAnswers(quiz *Quiz) map[string]int {
 	incorrectAnswers := make(map[string]int)
+	for _, cluster := range clusterIncorrectAnswers(quiz) {
+		incorrectAnswers[cluster.Representative] = cluster.Count
+	}
+	return incorrectAnswers
+}
 
-	for _, question := range questions {
-		for i, response := range question.Responses {
-			if !question.Skipped[i] && strings.ToLower(response) != strings.ToLower(question.CorrectAnswer) {
-				incorrectAnswers[response]++
+// clusterIncorrectAnswers groups the incorrect answers to each question
+// into near-miss clusters and merges clusters with the same
+// representative across questions.
+func clusterIncorrectAnswers(quiz *Quiz) []AnswerCluster {
+	matcher := quiz.matcher()
+	var clusters []AnswerCluster
+	for _, question := range quiz.Questions {
+		counts := make(map[string]int)
+		for _, r := range quiz.recordsFor(question.ID) {
+			if !r.Skipped && !quiz.isCorrect(question, r) {
+				counts[r.Answer]++
 			}
 		}
+		clusters = append(clusters, matcher.Cluster(counts)...)
 	}
-
-	return incorrectAnswers
+	return mergeClusters(clusters)
 }
 
-// Calculate the average response time for all questions
-func calculateAverageResponseTime(questions []Question) time.Duration {
+// Calculate the average response time for all answered questions. Skipped
+// records carry no meaningful time; an answer recorded in zero time
+// still counts, since that is exactly what a rapid guess looks like.
+func calculateAverageResponseTime(quiz *Quiz) time.Duration {
 	totalTime := time.Duration(0)
 	totalCount := 0
 
-	for _, question := range questions {
-		for _, responseTime := range question.ResponseTimes {
-			if responseTime > 0 {
-				totalTime += responseTime
+	for _, a := range quiz.Attempts {
+		for _, r := range a.Records {
+			if !r.Skipped {
+				totalTime += r.Duration
 				totalCount++
 			}
 		}
@@ -71,15 +93,15 @@ func calculateAverageResponseTime(questions []Question) time.Duration {
 	return totalTime / time.Duration(totalCount)
 }
 
-// Identify questions that were most frequently skipped
-func findMostSkippedQuestions(questions []Question) map[int]int {
+// Identify questions that were most frequently skipped, keyed by question ID
+func findMostSkippedQuestions(quiz *Quiz) map[int]int {
 	skippedCounts := make(map[int]int)
 
-	for i, question := range questions {
-		skippedCounts[i] = 0
-		for _, skipped := range question.Skipped {
-			if skipped {
-				skippedCounts[i]++
+	for _, question := range quiz.Questions {
+		skippedCounts[question.ID] = 0
+		for _, r := range quiz.recordsFor(question.ID) {
+			if r.Skipped {
+				skippedCounts[question.ID]++
 			}
 		}
 	}
@@ -88,48 +110,151 @@ func findMostSkippedQuestions(questions []Question) map[int]int {
 }
 
 // Print insights from the data
-func printInsights(questions []Question) {
-	totalQuestions := len(questions)
-	correctAnswers := countCorrectAnswers(questions)
-	fmt.Printf("Total questions: %d\n", totalQuestions)
-	fmt.Printf("Total correct answers: %d\n", correctAnswers)
-	fmt.Printf("Average correct answers per question: %.2f\n", float64(correctAnswers)/float64(totalQuestions))
+func printInsights(quiz *Quiz) {
+	writeTextReport(os.Stdout, buildReport(quiz))
+}
 
-	incorrectAnswers := findMostCommonIncorrectAnswers(questions)
-	fmt.Printf("Most common incorrect answers:\n")
-	for answer, count := range incorrectAnswers {
-		fmt.Printf("%s: %d times\n", answer, count)
+// sampleQuiz returns the demo data: five respondents, one attempt each
+func sampleQuiz() (*Quiz, error) {
+	questions := []Question{
+		{ID: 1, Text: "What is the capital of France?", CorrectAnswer: "Paris"},
+		{ID: 2, Text: "What is 2 + 2?", CorrectAnswer: "4"},
+	}
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	record := func(question int, answer string, seconds int, skipped bool) ResponseRecord {
+		return ResponseRecord{
+			QuestionID: question,
+			Answer:     answer,
+			Duration:   time.Duration(seconds) * time.Second,
+			Skipped:    skipped,
+			Timestamp:  start.Add(time.Duration(seconds) * time.Second),
+		}
 	}
 
-	averageResponseTime := calculateAverageResponseTime(questions)
-	fmt.Printf("Average response time: %s\n", averageResponseTime)
-
-	skippedCounts := findMostSkippedQuestions(questions)
-	fmt.Printf("Most skipped questions:\n")
-	for i, count := range skippedCounts {
-		fmt.Printf("Question %d: %d times\n", i+1, count)
+	var respondents []Respondent
+	var attempts []Attempt
+	for i, records := range [][]ResponseRecord{
+		{record(1, "Paris", 3, false), record(2, "4", 1, false)},
+		{record(1, "Berlin", 5, false), record(2, "2", 3, false)},
+		{record(1, "Madrid", 4, false), record(2, "4", 2, false)},
+		{record(1, "Paris", 2, false), record(2, "5", 4, false)},
+		{record(2, "", 0, true)},
+	} {
+		id := fmt.Sprintf("user%d", i+1)
+		respondents = append(respondents, Respondent{ID: id})
+		attempts = append(attempts, Attempt{ID: id + "-1", RespondentID: id, StartedAt: start, Records: records})
 	}
+	return NewQuiz(questions, respondents, attempts)
 }
 
 func main() {
-	// Sample questions
-	questions := []Question{
-		Question{
-			Text:     "What is the capital of France?",
-			CorrectAnswer: "Paris",
-			Responses:   []string{"Paris", "Berlin", "Madrid", "Paris"},
-			ResponseTimes: []time.Duration{3 * time.Second, 5 * time.Second, 4 * time.Second, 2 * time.Second},
-			Skipped:     []bool{false, false, false, false},
-		},
-		Question{
-			Text:     "What is 2 + 2?",
-			CorrectAnswer: "4",
-			Responses:   []string{"4", "2", "4", "5", "4"},
-			ResponseTimes: []time.Duration{1 * time.Second, 3 * time.Second, 2 * time.Second, 4 * time.Second, 0 * time.Second},
-			Skipped:     []bool{false, false, false, false, true},
-		},
-	}
-
-	// Print insights
-	printInsights(questions)
-}
\ No newline at end of file
+	questionsPath := flag.String("questions", "", "questions file (.csv or .json)")
+	responsesPath := flag.String("responses", "", "responses file (.csv, .json or .jsonl)")
+	format := flag.String("format", "text", "report format: text, json or markdown")
+	out := flag.String("o", "", "write the report to this file instead of stdout")
+	irtModel := flag.String("irt", "", "fit an item response model (1pl or 2pl) instead of reporting")
+	adaptive := flag.String("adaptive", "", "run an adaptive session on the fitted model: interactive (answers from stdin) or simulate")
+	targetSE := flag.Float64("target-se", 0.4, "stop an adaptive session once the ability standard error drops below this; 0 disables")
+	maxItems := flag.Int("max-items", 0, "stop an adaptive session after this many questions; 0 means all")
+	simRuns := flag.Int("sim-runs", 100, "simulated respondents for -adaptive simulate")
+	seed := flag.Int64("seed", 1, "random seed for -adaptive simulate")
+	addr := flag.String("serve", "", "serve live analytics over HTTP on this address, e.g. :8080")
+	flag.Parse()
+
+	var quiz *Quiz
+	var err error
+	if (*questionsPath == "") != (*responsesPath == "") {
+		fmt.Fprintln(os.Stderr, "-questions and -responses must be given together")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *questionsPath != "" {
+		quiz, err = loadQuizFiles(*questionsPath, *responsesPath)
+	} else {
+		quiz, err = sampleQuiz()
+	}
+	if err != nil {
+		fmt.Printf("Error loading quiz: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *addr != "" {
+		store, err := NewQuizStore(quiz)
+		if err != nil {
+			fmt.Printf("Error loading quiz: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Serving quiz analytics on %s\n", *addr)
+		if err := http.ListenAndServe(*addr, newQuizHandler(store)); err != nil {
+			fmt.Printf("Error serving: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *irtModel != "" || *adaptive != "" {
+		name := *irtModel
+		if name == "" {
+			name = "2pl"
+		}
+		model, err := parseIRTModel(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		if *adaptive != "" && *adaptive != "interactive" && *adaptive != "simulate" {
+			fmt.Fprintf(os.Stderr, "unknown adaptive mode %q (want interactive or simulate)\n", *adaptive)
+			os.Exit(2)
+		}
+		fit, err := FitIRT(quiz, IRTOptions{Model: model})
+		if err != nil {
+			fmt.Printf("Error fitting %s model: %v\n", model, err)
+			os.Exit(1)
+		}
+		opts := AdaptiveOptions{MaxItems: *maxItems, TargetSE: *targetSE}
+		switch *adaptive {
+		case "":
+			printIRTFit(fit)
+		case "interactive":
+			err = runAdaptiveSession(quiz, fit, opts, os.Stdin, os.Stdout)
+		case "simulate":
+			var sim AdaptiveSimulation
+			if sim, err = simulateAdaptive(quiz, fit, opts, *simRuns, *seed); err == nil {
+				fmt.Printf("%d simulated %s sessions: %.1f of %d questions on average, mean SE %.2f, MAE %.2f, RMSE %.2f\n",
+					sim.Runs, model, sim.MeanItems, len(quiz.Questions), sim.MeanSE, sim.MAE, sim.RMSE)
+			}
+		}
+		if err != nil {
+			fmt.Printf("Error running adaptive session: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *format == "text" && *out == "" {
+		// Print insights
+		printInsights(quiz)
+		return
+	}
+	if err := exportReport(quiz, *format, *out); err != nil {
+		fmt.Printf("Error writing report: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// exportReport writes the quiz report in format to path, or to stdout
+// when path is empty.
+func exportReport(quiz *Quiz, format, path string) error {
+	if path == "" {
+		return writeReport(os.Stdout, buildReport(quiz), format)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeReport(f, buildReport(quiz), format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
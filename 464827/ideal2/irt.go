@@ -0,0 +1,308 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// IRTModel selects the item response model.
+type IRTModel int
+
+const (
+	// OnePL is the Rasch model: every item has discrimination 1.
+	OnePL IRTModel = iota
+	// TwoPL fits a discrimination per item as well as a difficulty.
+	TwoPL
+)
+
+// parseIRTModel parses "1pl" or "2pl", in any case.
+func parseIRTModel(s string) (IRTModel, error) {
+	switch strings.ToLower(s) {
+	case "1pl":
+		return OnePL, nil
+	case "2pl":
+		return TwoPL, nil
+	}
+	return 0, fmt.Errorf("unknown IRT model %q (want 1pl or 2pl)", s)
+}
+
+func (m IRTModel) String() string {
+	if m == OnePL {
+		return "1PL"
+	}
+	return "2PL"
+}
+
+// ItemParams are the fitted IRT parameters of one question.
+type ItemParams struct {
+	QuestionID     int
+	Discrimination float64 // a
+	Difficulty     float64 // b, on the ability scale
+}
+
+// Probability returns the chance that a respondent of ability theta
+// answers the item correctly: 1 / (1 + exp(-a(θ - b))).
+func (p ItemParams) Probability(theta float64) float64 {
+	return 1 / (1 + math.Exp(-p.Discrimination*(theta-p.Difficulty)))
+}
+
+// Information returns the Fisher information the item gives about theta.
+func (p ItemParams) Information(theta float64) float64 {
+	prob := p.Probability(theta)
+	return p.Discrimination * p.Discrimination * prob * (1 - prob)
+}
+
+// IRTOptions controls fitting.
+type IRTOptions struct {
+	Model         IRTModel
+	MaxIterations int     // EM cycles, defaults to 200
+	Tolerance     float64 // stop when no parameter moves more than this, defaults to 1e-4
+}
+
+// IRTFit is the result of fitting a model to recorded responses.
+type IRTFit struct {
+	Model      IRTModel
+	Items      []ItemParams // in question order
+	Iterations int
+	Converged  bool
+}
+
+// Item returns the parameters of the question with the given ID.
+func (f *IRTFit) Item(questionID int) (ItemParams, bool) {
+	for _, item := range f.Items {
+		if item.QuestionID == questionID {
+			return item, true
+		}
+	}
+	return ItemParams{}, false
+}
+
+// Parameter bounds keep estimates finite when an item is answered
+// correctly (or incorrectly) by everyone.
+const (
+	minDiscrimination = 0.2
+	maxDiscrimination = 4
+	abilityBound      = 4
+)
+
+// quadrature is a fixed grid over the ability scale with standard normal
+// prior weights, used for marginal likelihood and EAP estimates.
+type quadrature struct {
+	nodes, weights []float64
+}
+
+func newQuadrature(points int) quadrature {
+	q := quadrature{nodes: make([]float64, points), weights: make([]float64, points)}
+	var total float64
+	for k := range q.nodes {
+		theta := -abilityBound + 2*abilityBound*float64(k)/float64(points-1)
+		q.nodes[k] = theta
+		q.weights[k] = math.Exp(-theta * theta / 2)
+		total += q.weights[k]
+	}
+	for k := range q.weights {
+		q.weights[k] /= total
+	}
+	return q
+}
+
+var defaultQuadrature = newQuadrature(41)
+
+// itemResponse is one scored response of a respondent to an item.
+type itemResponse struct {
+	item    int // index into the item slice
+	correct bool
+}
+
+// responseMatrix extracts the scored responses of every attempt. Skipped
+// questions score as incorrect; questions never presented are missing.
+func responseMatrix(quiz *Quiz) [][]itemResponse {
+	persons := make([][]itemResponse, 0, len(quiz.Attempts))
+	for _, a := range quiz.Attempts {
+		var responses []itemResponse
+		for _, r := range a.Records {
+			i := quiz.byID[r.QuestionID]
+			responses = append(responses, itemResponse{item: i, correct: quiz.isCorrect(quiz.Questions[i], r)})
+		}
+		if len(responses) > 0 {
+			persons = append(persons, responses)
+		}
+	}
+	return persons
+}
+
+// FitIRT estimates item parameters from the quiz's recorded attempts by
+// marginal maximum likelihood (Bock–Aitkin EM), assuming abilities are
+// standard normal.
+func FitIRT(quiz *Quiz, opts IRTOptions) (*IRTFit, error) {
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = 200
+	}
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = 1e-4
+	}
+	persons := responseMatrix(quiz)
+	if len(persons) == 0 {
+		return nil, errors.New("irt: no responses to fit")
+	}
+
+	fit := &IRTFit{Model: opts.Model, Items: make([]ItemParams, len(quiz.Questions))}
+	for i, question := range quiz.Questions {
+		fit.Items[i] = ItemParams{QuestionID: question.ID, Discrimination: 1}
+	}
+
+	q := defaultQuadrature
+	nodes := len(q.nodes)
+	expectedN := make([][]float64, len(fit.Items)) // expected respondents at each node
+	expectedR := make([][]float64, len(fit.Items)) // expected correct answers at each node
+	for i := range fit.Items {
+		expectedN[i] = make([]float64, nodes)
+		expectedR[i] = make([]float64, nodes)
+	}
+	posterior := make([]float64, nodes)
+
+	for iter := 1; iter <= opts.MaxIterations; iter++ {
+		fit.Iterations = iter
+		for i := range fit.Items {
+			clear(expectedN[i])
+			clear(expectedR[i])
+		}
+
+		// E-step: each respondent's posterior over the grid.
+		for _, responses := range persons {
+			abilityPosterior(fit.Items, responses, q, posterior)
+			for _, resp := range responses {
+				for k, w := range posterior {
+					expectedN[resp.item][k] += w
+					if resp.correct {
+						expectedR[resp.item][k] += w
+					}
+				}
+			}
+		}
+
+		// M-step: refit each item against its expected counts.
+		maxChange := 0.0
+		for i := range fit.Items {
+			updated := fitItem(fit.Items[i], q.nodes, expectedN[i], expectedR[i], opts.Model)
+			maxChange = max(maxChange,
+				math.Abs(updated.Difficulty-fit.Items[i].Difficulty),
+				math.Abs(updated.Discrimination-fit.Items[i].Discrimination))
+			fit.Items[i] = updated
+		}
+		if maxChange < opts.Tolerance {
+			fit.Converged = true
+			break
+		}
+	}
+	return fit, nil
+}
+
+// abilityPosterior fills posterior with the normalised posterior weight
+// of each grid node given the responses.
+func abilityPosterior(items []ItemParams, responses []itemResponse, q quadrature, posterior []float64) {
+	var total float64
+	for k, theta := range q.nodes {
+		// Work in log space; long response strings underflow otherwise.
+		logL := math.Log(q.weights[k])
+		for _, resp := range responses {
+			p := items[resp.item].Probability(theta)
+			if resp.correct {
+				logL += math.Log(p)
+			} else {
+				logL += math.Log1p(-p)
+			}
+		}
+		posterior[k] = logL
+	}
+	peak := posterior[0]
+	for _, v := range posterior {
+		peak = max(peak, v)
+	}
+	for k, v := range posterior {
+		posterior[k] = math.Exp(v - peak)
+		total += posterior[k]
+	}
+	for k := range posterior {
+		posterior[k] /= total
+	}
+}
+
+// fitItem maximises the expected log-likelihood of one item with a few
+// Newton steps in slope-intercept form, P = σ(aθ + c).
+func fitItem(start ItemParams, nodes, n, r []float64, model IRTModel) ItemParams {
+	a := start.Discrimination
+	c := -a * start.Difficulty
+	for step := 0; step < 10; step++ {
+		var ga, gc, haa, hac, hcc float64
+		for k, theta := range nodes {
+			p := 1 / (1 + math.Exp(-(a*theta + c)))
+			resid := r[k] - n[k]*p
+			w := n[k] * p * (1 - p)
+			ga += resid * theta
+			gc += resid
+			haa += w * theta * theta
+			hac += w * theta
+			hcc += w
+		}
+		// A weak prior on c keeps items everyone got right (or wrong)
+		// from drifting to infinity.
+		const ridge = 0.01
+		gc -= ridge * c
+		hcc += ridge
+
+		var da, dc float64
+		if model == OnePL {
+			dc = gc / hcc
+		} else {
+			ga -= ridge * (a - 1)
+			haa += ridge
+			det := haa*hcc - hac*hac
+			if det <= 1e-12 {
+				break
+			}
+			da = (hcc*ga - hac*gc) / det
+			dc = (haa*gc - hac*ga) / det
+		}
+		// Damp large steps; the likelihood is flat far from the data.
+		da = math.Max(-0.5, math.Min(0.5, da))
+		dc = math.Max(-1, math.Min(1, dc))
+		a = math.Max(minDiscrimination, math.Min(maxDiscrimination, a+da))
+		c += dc
+		if math.Abs(da) < 1e-6 && math.Abs(dc) < 1e-6 {
+			break
+		}
+	}
+	b := math.Max(-abilityBound, math.Min(abilityBound, -c/a))
+	return ItemParams{QuestionID: start.QuestionID, Discrimination: a, Difficulty: b}
+}
+
+// EstimateAbility returns the expected a posteriori ability and its
+// standard error given responses to items, under a standard normal prior.
+func EstimateAbility(items []ItemParams, responses []itemResponse) (theta, se float64) {
+	q := defaultQuadrature
+	posterior := make([]float64, len(q.nodes))
+	abilityPosterior(items, responses, q, posterior)
+	for k, w := range posterior {
+		theta += w * q.nodes[k]
+	}
+	var variance float64
+	for k, w := range posterior {
+		d := q.nodes[k] - theta
+		variance += w * d * d
+	}
+	return theta, math.Sqrt(variance)
+}
+
+// printIRTFit prints fitted parameters, hardest item first.
+func printIRTFit(fit *IRTFit) {
+	items := append([]ItemParams(nil), fit.Items...)
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Difficulty > items[j].Difficulty })
+	fmt.Printf("%s fit after %d iterations (converged: %t):\n", fit.Model, fit.Iterations, fit.Converged)
+	for _, item := range items {
+		fmt.Printf("Question %d: difficulty %.2f, discrimination %.2f\n", item.QuestionID, item.Difficulty, item.Discrimination)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// trueItems is a bank spanning easy to hard questions with varied discrimination.
+func trueItems(n int) []ItemParams {
+	items := make([]ItemParams, n)
+	for i := range items {
+		items[i] = ItemParams{
+			QuestionID:     i + 1,
+			Difficulty:     -2 + 4*float64(i)/float64(n-1),
+			Discrimination: 0.8 + 0.8*float64(i%3),
+		}
+	}
+	return items
+}
+
+// TestFitIRTRecoversParameters tests that 1PL and 2PL fits recover simulated item parameters
+func TestFitIRTRecoversParameters(t *testing.T) {
+	truth := trueItems(12)
+	quiz, err := simulateQuiz(truth, 1500, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	fit2, err := FitIRT(quiz, IRTOptions{Model: TwoPL})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !fit2.Converged {
+		t.Errorf("Expected 2PL fit to converge in %d iterations", fit2.Iterations)
+	}
+	for i, item := range fit2.Items {
+		if math.Abs(item.Difficulty-truth[i].Difficulty) > 0.35 {
+			t.Errorf("Question %d: expected difficulty near %.2f, got %.2f", item.QuestionID, truth[i].Difficulty, item.Difficulty)
+		}
+		if math.Abs(item.Discrimination-truth[i].Discrimination) > 0.5 {
+			t.Errorf("Question %d: expected discrimination near %.2f, got %.2f", item.QuestionID, truth[i].Discrimination, item.Discrimination)
+		}
+	}
+
+	fit1, err := FitIRT(quiz, IRTOptions{Model: OnePL})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// Under the Rasch model difficulty is a monotone function of the
+	// proportion correct, so the two must rank items identically.
+	classical := analyzeItems(quiz).Items
+	for i := range fit1.Items {
+		if fit1.Items[i].Discrimination != 1 {
+			t.Fatalf("Expected 1PL discrimination to stay 1, got %.2f", fit1.Items[i].Discrimination)
+		}
+		for j := range fit1.Items {
+			if classical[i].Difficulty > classical[j].Difficulty && fit1.Items[i].Difficulty >= fit1.Items[j].Difficulty {
+				t.Errorf("Question %d is answered correctly more often than %d but got a higher 1PL difficulty", fit1.Items[i].QuestionID, fit1.Items[j].QuestionID)
+			}
+		}
+	}
+}
+
+// TestFitIRTDegenerateItems tests that items everyone gets right or wrong stay finite
+func TestFitIRTDegenerateItems(t *testing.T) {
+	quiz, _ := buildQuiz([]Question{{ID: 1, CorrectAnswer: "a"}, {ID: 2, CorrectAnswer: "b"}}, []UserResponse{
+		{UserID: "u1", QuestionID: 1, Answer: "a"}, {UserID: "u1", QuestionID: 2, Answer: "x"},
+		{UserID: "u2", QuestionID: 1, Answer: "a"}, {UserID: "u2", QuestionID: 2, Answer: "x"},
+	})
+	fit, err := FitIRT(quiz, IRTOptions{Model: TwoPL})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	easy, hard := fit.Items[0], fit.Items[1]
+	if math.IsNaN(easy.Difficulty) || math.IsNaN(hard.Difficulty) || easy.Difficulty >= hard.Difficulty {
+		t.Errorf("Expected finite, ordered difficulties, got %.2f and %.2f", easy.Difficulty, hard.Difficulty)
+	}
+	if _, err := FitIRT(&Quiz{}, IRTOptions{}); err == nil {
+		t.Errorf("Expected an error without responses")
+	}
+}
+
+// TestAdaptiveSession tests item selection and stopping rules
+func TestAdaptiveSession(t *testing.T) {
+	truth := trueItems(20)
+	quiz, _ := simulateQuiz(truth, 1, 1)
+	fit := &IRTFit{Model: TwoPL, Items: truth}
+
+	session := NewAdaptiveSession(quiz, fit, AdaptiveOptions{MaxItems: 5})
+	first, ok := session.Next()
+	if !ok {
+		t.Fatal("Expected a first question")
+	}
+	// At θ = 0 the most informative item is a high-discrimination item near b = 0.
+	if params, _ := fit.Item(first.ID); math.Abs(params.Difficulty) > 0.5 || params.Discrimination < 2 {
+		t.Errorf("Expected a discriminating item near 0 first, got %+v", params)
+	}
+
+	thetaBefore, seBefore := session.Ability()
+	if _, err := session.Record(first.ID, "right"); err != nil {
+		t.Fatal(err)
+	}
+	thetaAfter, seAfter := session.Ability()
+	if thetaAfter <= thetaBefore || seAfter >= seBefore {
+		t.Errorf("Expected a correct answer to raise θ and shrink SE, got %.2f→%.2f and %.2f→%.2f", thetaBefore, thetaAfter, seBefore, seAfter)
+	}
+	if _, err := session.Record(first.ID, "right"); err == nil {
+		t.Errorf("Expected an error when recording a question twice")
+	}
+	for session.Administered() < 5 {
+		q, ok := session.Next()
+		if !ok {
+			t.Fatal("Session ended early")
+		}
+		session.Record(q.ID, "nope")
+	}
+	if _, ok := session.Next(); ok || !session.Done() {
+		t.Errorf("Expected the session to stop after 5 items")
+	}
+}
+
+// TestAdaptiveSimulation tests that simulated sessions recover abilities with fewer items
+func TestAdaptiveSimulation(t *testing.T) {
+	truth := trueItems(40)
+	quiz, _ := simulateQuiz(truth, 1, 2)
+	fit := &IRTFit{Model: TwoPL, Items: truth}
+
+	var absErr float64
+	var used int
+	abilities := []float64{-1.5, -0.5, 0, 0.5, 1.5}
+	const runs = 40
+	for r := 0; r < runs; r++ {
+		trueTheta := abilities[r%len(abilities)]
+		theta, se, n, err := simulateSession(quiz, fit, NewSimulatedRespondent(trueTheta, int64(r)), AdaptiveOptions{TargetSE: 0.4})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n < len(truth) && se > 0.4 {
+			t.Errorf("Run %d stopped with SE %.2f above target", r, se)
+		}
+		absErr += math.Abs(theta - trueTheta)
+		used += n
+	}
+	if mae := absErr / runs; mae > 0.5 {
+		t.Errorf("Expected mean absolute ability error below 0.5, got %.2f", mae)
+	}
+	if avg := float64(used) / runs; avg >= float64(len(truth)) {
+		t.Errorf("Expected the target SE to stop sessions early, used %.1f of %d items", avg, len(truth))
+	}
+}
+
+// TestParseIRTModel tests that only known models are accepted
+func TestParseIRTModel(t *testing.T) {
+	for in, want := range map[string]IRTModel{"1pl": OnePL, "2PL": TwoPL} {
+		if got, err := parseIRTModel(in); err != nil || got != want {
+			t.Errorf("parseIRTModel(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"3pl", "", "rasch"} {
+		if _, err := parseIRTModel(in); err == nil {
+			t.Errorf("Expected an error for %q", in)
+		}
+	}
+}
+
+// TestRunAdaptiveSession tests an interactive session driven by scripted answers
+func TestRunAdaptiveSession(t *testing.T) {
+	truth := trueItems(10)
+	quiz, _ := simulateQuiz(truth, 1, 2)
+	fit := &IRTFit{Model: TwoPL, Items: truth}
+
+	var out strings.Builder
+	in := strings.NewReader("right\nwrong\nright\n")
+	if err := runAdaptiveSession(quiz, fit, AdaptiveOptions{MaxItems: 5}, in, &out); err != nil {
+		t.Fatal(err)
+	}
+	got := out.String()
+	if n := strings.Count(got, "> "); n != 4 {
+		t.Errorf("Expected 3 answered questions and a fourth left unanswered at end of input, got %d prompts:\n%s", n, got)
+	}
+	if !strings.Contains(got, "Correct. Ability") || !strings.Contains(got, "Incorrect. Ability") {
+		t.Errorf("Expected feedback for each answer, got:\n%s", got)
+	}
+	if !strings.Contains(got, "after 3 questions") {
+		t.Errorf("Expected a final estimate after 3 questions, got:\n%s", got)
+	}
+}
+
+// TestSimulateAdaptive tests the summary of simulated sessions
+func TestSimulateAdaptive(t *testing.T) {
+	truth := trueItems(40)
+	quiz, _ := simulateQuiz(truth, 1, 2)
+	fit := &IRTFit{Model: TwoPL, Items: truth}
+
+	sim, err := simulateAdaptive(quiz, fit, AdaptiveOptions{TargetSE: 0.4}, 30, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.Runs != 30 || sim.MeanItems <= 0 || sim.MeanItems >= 40 {
+		t.Errorf("Unexpected simulation summary %+v", sim)
+	}
+	if sim.MAE > 0.6 || sim.RMSE < sim.MAE {
+		t.Errorf("Unexpected ability errors %+v", sim)
+	}
+	if _, err := simulateAdaptive(quiz, fit, AdaptiveOptions{}, 0, 1); err == nil {
+		t.Errorf("Expected an error for zero runs")
+	}
+}
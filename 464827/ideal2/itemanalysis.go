@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// groupFraction is the share of attempts in each of the upper and lower
+// groups used for the discrimination index and distractor analysis.
+const groupFraction = 0.27
+
+// DistractorStats describes how often an incorrect answer was chosen,
+// overall and within the upper and lower scoring groups.
+type DistractorStats struct {
+	Answer     string  `json:"answer"`
+	Count      int     `json:"count"`
+	Proportion float64 `json:"proportion"` // share of all attempts
+	Upper      int     `json:"upper"`      // chosen by attempts in the upper group
+	Lower      int     `json:"lower"`      // chosen by attempts in the lower group
+}
+
+// ItemStats holds the classical test theory statistics for one question.
+type ItemStats struct {
+	QuestionID     int               `json:"question_id"`
+	Difficulty     float64           `json:"difficulty"`     // proportion of attempts answering correctly
+	Discrimination float64           `json:"discrimination"` // upper-group minus lower-group difficulty
+	PointBiserial  float64           `json:"point_biserial"` // correlation of the item with the rest of the test
+	Skipped        int               `json:"skipped"`
+	Distractors    []DistractorStats `json:"distractors"` // most chosen first
+	Flags          []string          `json:"flags"`       // reasons to review the question
+}
+
+// ItemAnalysis is the item analysis of a whole quiz. Every attempt is
+// scored on every question; missing and skipped answers score zero.
+type ItemAnalysis struct {
+	Attempts      int
+	GroupSize     int // attempts in each of the upper and lower groups
+	CronbachAlpha float64
+	Items         []ItemStats // in question order
+}
+
+// analyzeItems computes per-question difficulty, discrimination,
+// point-biserial correlation and distractors, plus Cronbach's alpha.
+// CronbachAlpha is NaN when it is undefined (fewer than two questions
+// or no variance in total scores).
+func analyzeItems(quiz *Quiz) ItemAnalysis {
+	k, n := len(quiz.Questions), len(quiz.Attempts)
+	analysis := ItemAnalysis{Attempts: n, CronbachAlpha: math.NaN()}
+
+	// scores[a][i] is 1 when attempt a answered question i correctly.
+	scores := make([][]float64, n)
+	answers := make([][]string, n)
+	skipped := make([][]bool, n)
+	totals := make([]float64, n)
+	for a, attempt := range quiz.Attempts {
+		scores[a] = make([]float64, k)
+		answers[a] = make([]string, k)
+		skipped[a] = make([]bool, k)
+		for _, r := range attempt.Records {
+			i := quiz.byID[r.QuestionID]
+			skipped[a][i] = r.Skipped
+			if quiz.isCorrect(quiz.Questions[i], r) {
+				scores[a][i] = 1
+				totals[a]++
+			} else if !r.Skipped {
+				answers[a][i] = quiz.matcher().Normalize(r.Answer)
+			}
+		}
+	}
+
+	// Rank attempts by total score; ties keep attempt order.
+	order := make([]int, n)
+	for a := range order {
+		order[a] = a
+	}
+	sort.SliceStable(order, func(x, y int) bool { return totals[order[x]] > totals[order[y]] })
+	g := int(math.Round(groupFraction * float64(n)))
+	if g == 0 && n >= 2 {
+		g = 1
+	}
+	analysis.GroupSize = g
+	inUpper := make([]bool, n)
+	inLower := make([]bool, n)
+	for _, a := range order[:g] {
+		inUpper[a] = true
+	}
+	for _, a := range order[n-g:] {
+		inLower[a] = true
+	}
+
+	itemVariances := 0.0
+	for i, question := range quiz.Questions {
+		stats := ItemStats{QuestionID: question.ID}
+		item := make([]float64, n)
+		rest := make([]float64, n)
+		var upperCorrect, lowerCorrect float64
+		distractors := make(map[string]*DistractorStats)
+		for a := 0; a < n; a++ {
+			item[a] = scores[a][i]
+			rest[a] = totals[a] - scores[a][i]
+			if skipped[a][i] {
+				stats.Skipped++
+			}
+			if inUpper[a] {
+				upperCorrect += item[a]
+			}
+			if inLower[a] {
+				lowerCorrect += item[a]
+			}
+			if ans := answers[a][i]; ans != "" {
+				d, ok := distractors[ans]
+				if !ok {
+					d = &DistractorStats{Answer: ans}
+					distractors[ans] = d
+				}
+				d.Count++
+				if inUpper[a] {
+					d.Upper++
+				}
+				if inLower[a] {
+					d.Lower++
+				}
+			}
+		}
+
+		if n > 0 {
+			stats.Difficulty = mean(item)
+			itemVariances += variance(item)
+		}
+		if g > 0 {
+			stats.Discrimination = (upperCorrect - lowerCorrect) / float64(g)
+		}
+		stats.PointBiserial = correlation(item, rest)
+		for _, d := range distractors {
+			d.Proportion = float64(d.Count) / float64(n)
+			stats.Distractors = append(stats.Distractors, *d)
+		}
+		sort.Slice(stats.Distractors, func(x, y int) bool {
+			dx, dy := stats.Distractors[x], stats.Distractors[y]
+			if dx.Count != dy.Count {
+				return dx.Count > dy.Count
+			}
+			return dx.Answer < dy.Answer
+		})
+		stats.Flags = itemFlags(stats)
+		analysis.Items = append(analysis.Items, stats)
+	}
+
+	if k >= 2 && n > 0 {
+		if totalVariance := variance(totals); totalVariance > 0 {
+			analysis.CronbachAlpha = float64(k) / float64(k-1) * (1 - itemVariances/totalVariance)
+		}
+	}
+	return analysis
+}
+
+// itemFlags lists the conventional warning signs for a question.
+func itemFlags(s ItemStats) []string {
+	var flags []string
+	switch {
+	case s.Difficulty < 0.2:
+		flags = append(flags, "very hard (difficulty < 0.20)")
+	case s.Difficulty > 0.9:
+		flags = append(flags, "very easy (difficulty > 0.90)")
+	}
+	switch {
+	case s.Discrimination < 0:
+		flags = append(flags, "negative discrimination")
+	case s.Discrimination < 0.2:
+		flags = append(flags, "poor discrimination (< 0.20)")
+	}
+	for _, d := range s.Distractors {
+		if d.Upper > d.Lower {
+			flags = append(flags, fmt.Sprintf("distractor %q attracts the upper group", d.Answer))
+		}
+	}
+	return flags
+}
+
+func mean(xs []float64) float64 {
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+// variance returns the population variance of xs.
+func variance(xs []float64) float64 {
+	m := mean(xs)
+	var ss float64
+	for _, x := range xs {
+		ss += (x - m) * (x - m)
+	}
+	return ss / float64(len(xs))
+}
+
+// correlation returns Pearson's r, which for a 0/1 item is the
+// point-biserial correlation. It is 0 when either side has no variance.
+func correlation(xs, ys []float64) float64 {
+	if len(xs) < 2 {
+		return 0
+	}
+	mx, my := mean(xs), mean(ys)
+	var sxy, sxx, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
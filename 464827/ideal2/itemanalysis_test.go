@@ -0,0 +1,93 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// TestAnalyzeItems tests item statistics against values worked out by hand
+func TestAnalyzeItems(t *testing.T) {
+	rec := func(q int, answer string) ResponseRecord { return ResponseRecord{QuestionID: q, Answer: answer} }
+	quiz, err := NewQuiz(
+		[]Question{{ID: 1, CorrectAnswer: "a"}, {ID: 2, CorrectAnswer: "b"}, {ID: 3, CorrectAnswer: "c"}},
+		[]Respondent{{ID: "A"}, {ID: "B"}, {ID: "C"}, {ID: "D"}},
+		[]Attempt{
+			{ID: "1", RespondentID: "A", Records: []ResponseRecord{rec(1, "a"), rec(2, "b"), rec(3, "c")}},
+			{ID: "2", RespondentID: "B", Records: []ResponseRecord{rec(1, "a"), rec(2, "b"), rec(3, "X")}},
+			{ID: "3", RespondentID: "C", Records: []ResponseRecord{rec(1, "a"), rec(2, "z"), rec(3, "x")}},
+			{ID: "4", RespondentID: "D", Records: []ResponseRecord{rec(1, "y"), {QuestionID: 2, Skipped: true}}},
+		},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	analysis := analyzeItems(quiz)
+	if analysis.Attempts != 4 || analysis.GroupSize != 1 {
+		t.Fatalf("Expected 4 attempts and groups of 1, got %d and %d", analysis.Attempts, analysis.GroupSize)
+	}
+	// Item variances 0.1875 + 0.25 + 0.1875, total-score variance 1.25.
+	if !approxEqual(analysis.CronbachAlpha, 0.75) {
+		t.Errorf("Expected alpha 0.75, got %.4f", analysis.CronbachAlpha)
+	}
+
+	wantDifficulty := []float64{0.75, 0.5, 0.25}
+	for i, item := range analysis.Items {
+		if !approxEqual(item.Difficulty, wantDifficulty[i]) {
+			t.Errorf("Question %d: expected difficulty %.2f, got %.4f", item.QuestionID, wantDifficulty[i], item.Difficulty)
+		}
+		if !approxEqual(item.Discrimination, 1) {
+			t.Errorf("Question %d: expected discrimination 1, got %.4f", item.QuestionID, item.Discrimination)
+		}
+	}
+	if want := 0.75 / math.Sqrt(0.75*2.75); !approxEqual(analysis.Items[0].PointBiserial, want) {
+		t.Errorf("Expected point-biserial %.4f, got %.4f", want, analysis.Items[0].PointBiserial)
+	}
+	if analysis.Items[1].Skipped != 1 {
+		t.Errorf("Expected 1 skip on question 2, got %d", analysis.Items[1].Skipped)
+	}
+
+	q1 := analysis.Items[0].Distractors
+	if len(q1) != 1 || q1[0] != (DistractorStats{Answer: "y", Count: 1, Proportion: 0.25, Lower: 1}) {
+		t.Errorf("Unexpected distractors for question 1: %+v", q1)
+	}
+	// "X" and "x" are the same distractor.
+	q3 := analysis.Items[2].Distractors
+	if len(q3) != 1 || q3[0].Answer != "x" || q3[0].Count != 2 {
+		t.Errorf("Unexpected distractors for question 3: %+v", q3)
+	}
+}
+
+// TestItemFlags tests the review flags for problem questions
+func TestItemFlags(t *testing.T) {
+	flags := itemFlags(ItemStats{
+		Difficulty:     0.95,
+		Discrimination: -0.1,
+		Distractors:    []DistractorStats{{Answer: "trap", Upper: 2, Lower: 0}},
+	})
+	want := []string{"very easy (difficulty > 0.90)", "negative discrimination", `distractor "trap" attracts the upper group`}
+	if len(flags) != len(want) {
+		t.Fatalf("Expected flags %v, got %v", want, flags)
+	}
+	for i := range want {
+		if flags[i] != want[i] {
+			t.Errorf("Expected flag %q, got %q", want[i], flags[i])
+		}
+	}
+	if flags := itemFlags(ItemStats{Difficulty: 0.5, Discrimination: 0.4}); len(flags) != 0 {
+		t.Errorf("Expected no flags for a healthy item, got %v", flags)
+	}
+}
+
+// TestCronbachAlphaUndefined tests that alpha is NaN for a single question
+func TestCronbachAlphaUndefined(t *testing.T) {
+	quiz, _ := NewQuiz([]Question{{ID: 1, CorrectAnswer: "a"}}, []Respondent{{ID: "u"}},
+		[]Attempt{{ID: "1", RespondentID: "u", Records: []ResponseRecord{{QuestionID: 1, Answer: "a"}}}})
+	if alpha := analyzeItems(quiz).CronbachAlpha; !math.IsNaN(alpha) {
+		t.Errorf("Expected NaN alpha, got %v", alpha)
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UserResponse is one flat response row, the import format for quiz data.
+// AttemptID defaults to one attempt per user. A zero Timestamp means the
+// time is unknown; JSON has no empty form for it, so it is written as
+// "0001-01-01T00:00:00Z" and reads back as zero.
+type UserResponse struct {
+	UserID     string    `json:"user_id"`
+	AttemptID  string    `json:"attempt_id,omitempty"`
+	QuestionID int       `json:"question_id"`
+	Answer     string    `json:"answer"`
+	DurationMS int64     `json:"duration_ms"`
+	Skipped    bool      `json:"skipped"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// questionFile is the JSON shape accepted for questions.
+type questionFile struct {
+	ID              int      `json:"id"`
+	Text            string   `json:"text"`
+	CorrectAnswer   string   `json:"correct_answer"`
+	AcceptedAnswers []string `json:"accepted_answers"`
+}
+
+// loadQuizFiles reads questions and responses from CSV or JSON files,
+// chosen by file extension, and builds a validated quiz.
+func loadQuizFiles(questionsPath, responsesPath string) (*Quiz, error) {
+	var questions []Question
+	if err := readFile(questionsPath, func(r io.Reader, format string) (err error) {
+		questions, err = readQuestions(r, format)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("reading questions: %w", err)
+	}
+	var responses []UserResponse
+	if err := readFile(responsesPath, func(r io.Reader, format string) (err error) {
+		responses, err = readResponses(r, format)
+		return err
+	}); err != nil {
+		return nil, fmt.Errorf("reading responses: %w", err)
+	}
+	return buildQuiz(questions, responses)
+}
+
+func readFile(path string, read func(io.Reader, string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	return read(f, format)
+}
+
+// readQuestions reads questions as CSV (id,text,correct_answer and an
+// optional accepted_answers column separated by "|") or JSON.
+func readQuestions(r io.Reader, format string) ([]Question, error) {
+	switch format {
+	case "json":
+		var rows []questionFile
+		if err := json.NewDecoder(r).Decode(&rows); err != nil {
+			return nil, err
+		}
+		questions := make([]Question, len(rows))
+		for i, row := range rows {
+			questions[i] = Question{ID: row.ID, Text: row.Text, CorrectAnswer: row.CorrectAnswer, AcceptedAnswers: row.AcceptedAnswers}
+		}
+		return questions, nil
+	case "csv":
+		rows, err := readCSV(r, "id", "text", "correct_answer")
+		if err != nil {
+			return nil, err
+		}
+		questions := make([]Question, len(rows))
+		for i, row := range rows {
+			id, err := strconv.Atoi(row["id"])
+			if err != nil {
+				return nil, fmt.Errorf("row %d: invalid id %q", i+1, row["id"])
+			}
+			questions[i] = Question{ID: id, Text: row["text"], CorrectAnswer: row["correct_answer"]}
+			if v := row["accepted_answers"]; v != "" {
+				questions[i].AcceptedAnswers = strings.Split(v, "|")
+			}
+		}
+		return questions, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// readResponses reads UserResponse rows as CSV, a JSON array or JSON lines.
+// CSV needs user_id, question_id and answer columns; attempt_id,
+// duration_ms, skipped and timestamp (RFC 3339) are optional.
+func readResponses(r io.Reader, format string) ([]UserResponse, error) {
+	switch format {
+	case "json":
+		var responses []UserResponse
+		err := json.NewDecoder(r).Decode(&responses)
+		return responses, err
+	case "jsonl":
+		var responses []UserResponse
+		dec := json.NewDecoder(r)
+		for {
+			var resp UserResponse
+			if err := dec.Decode(&resp); errors.Is(err, io.EOF) {
+				return responses, nil
+			} else if err != nil {
+				return nil, fmt.Errorf("record %d: %w", len(responses)+1, err)
+			}
+			responses = append(responses, resp)
+		}
+	case "csv":
+		rows, err := readCSV(r, "user_id", "question_id", "answer")
+		if err != nil {
+			return nil, err
+		}
+		responses := make([]UserResponse, len(rows))
+		for i, row := range rows {
+			resp, err := parseResponseRow(row)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+			responses[i] = resp
+		}
+		return responses, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func parseResponseRow(row map[string]string) (UserResponse, error) {
+	resp := UserResponse{UserID: row["user_id"], AttemptID: row["attempt_id"], Answer: row["answer"]}
+	var err error
+	if resp.QuestionID, err = strconv.Atoi(row["question_id"]); err != nil {
+		return resp, fmt.Errorf("invalid question_id %q", row["question_id"])
+	}
+	if v := row["duration_ms"]; v != "" {
+		if resp.DurationMS, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return resp, fmt.Errorf("invalid duration_ms %q", v)
+		}
+	}
+	if v := row["skipped"]; v != "" {
+		if resp.Skipped, err = strconv.ParseBool(v); err != nil {
+			return resp, fmt.Errorf("invalid skipped %q", v)
+		}
+	}
+	if v := row["timestamp"]; v != "" {
+		if resp.Timestamp, err = time.Parse(time.RFC3339, v); err != nil {
+			return resp, fmt.Errorf("invalid timestamp %q", v)
+		}
+	}
+	return resp, nil
+}
+
+// readCSV reads a CSV with a header row into one map per record and
+// checks that the required columns are present.
+func readCSV(r io.Reader, required ...string) ([]map[string]string, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("missing header row")
+	}
+	header := records[0]
+	for _, col := range required {
+		found := false
+		for _, h := range header {
+			found = found || strings.TrimSpace(h) == col
+		}
+		if !found {
+			return nil, fmt.Errorf("missing column %q", col)
+		}
+	}
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, h := range header {
+			row[strings.TrimSpace(h)] = rec[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// defaultAttemptID returns the first of userID-1, userID-2, ... for which
+// taken reports false.
+func defaultAttemptID(userID string, taken func(string) bool) string {
+	for n := 1; ; n++ {
+		if id := fmt.Sprintf("%s-%d", userID, n); !taken(id) {
+			return id
+		}
+	}
+}
+
+// buildQuiz groups flat responses into respondents and attempts, in
+// first-seen order, and validates the result. Responses without an attempt
+// ID share one attempt per respondent, named userID-1, or userID-2 and so on
+// if that name is already taken by another attempt.
+func buildQuiz(questions []Question, responses []UserResponse) (*Quiz, error) {
+	var respondents []Respondent
+	var attempts []Attempt
+	seenUser := make(map[string]bool)
+	attemptIndex := make(map[string]int)
+	taken := make(map[string]bool)
+	for _, resp := range responses {
+		if resp.AttemptID != "" {
+			taken[resp.AttemptID] = true
+		}
+	}
+	defaultAttempt := make(map[string]string)
+	for _, resp := range responses {
+		if !seenUser[resp.UserID] {
+			seenUser[resp.UserID] = true
+			respondents = append(respondents, Respondent{ID: resp.UserID})
+		}
+		attemptID := resp.AttemptID
+		if attemptID == "" {
+			attemptID = defaultAttempt[resp.UserID]
+			if attemptID == "" {
+				attemptID = defaultAttemptID(resp.UserID, func(id string) bool { return taken[id] })
+				taken[attemptID] = true
+				defaultAttempt[resp.UserID] = attemptID
+			}
+		}
+		i, ok := attemptIndex[attemptID]
+		if !ok {
+			i = len(attempts)
+			attemptIndex[attemptID] = i
+			attempts = append(attempts, Attempt{ID: attemptID, RespondentID: resp.UserID, StartedAt: resp.Timestamp})
+		}
+		if attempts[i].RespondentID != resp.UserID {
+			return nil, fmt.Errorf("%w: attempt %q is shared by %q and %q", ErrInvalidQuiz, attemptID, attempts[i].RespondentID, resp.UserID)
+		}
+		attempts[i].Records = append(attempts[i].Records, ResponseRecord{
+			QuestionID: resp.QuestionID,
+			Answer:     resp.Answer,
+			Duration:   time.Duration(resp.DurationMS) * time.Millisecond,
+			Skipped:    resp.Skipped,
+			Timestamp:  resp.Timestamp,
+		})
+	}
+	return NewQuiz(questions, respondents, attempts)
+}
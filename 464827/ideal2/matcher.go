@@ -0,0 +1,313 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MatchOptions configures how free-text answers are compared.
+type MatchOptions struct {
+	FoldAccents        bool // treat "París" as "Paris"
+	IgnorePunctuation  bool // treat "paris." as "paris"
+	NumericEquivalence bool // treat "4", "4.0" and "four" as equal
+	MaxEditDistance    int  // Levenshtein distance tolerated when grading; 0 disables typo tolerance
+	MinFuzzyLength     int  // shorter answers must match exactly, so "2" never matches "4"
+	// ClusterEditDistance is the distance tolerated when grouping incorrect
+	// answers in reports. It never affects grading.
+	ClusterEditDistance int
+}
+
+// DefaultMatchOptions enables every normalization but grades without typo
+// tolerance, since a one-letter change can be a different answer ("Iran"
+// and "Iraq"). Incorrect answers of four or more characters that differ by
+// one typo are still grouped together in reports.
+func DefaultMatchOptions() MatchOptions {
+	return MatchOptions{
+		FoldAccents:         true,
+		IgnorePunctuation:   true,
+		NumericEquivalence:  true,
+		MinFuzzyLength:      4,
+		ClusterEditDistance: 1,
+	}
+}
+
+// MatchKind describes how an answer matched.
+type MatchKind int
+
+const (
+	NoMatch MatchKind = iota
+	ExactMatch
+	NormalizedMatch
+	NumericMatch
+	FuzzyMatch
+)
+
+// AnswerMatcher decides whether a free-text answer matches an accepted answer.
+type AnswerMatcher struct {
+	opts MatchOptions
+}
+
+// NewAnswerMatcher creates a matcher with opts.
+func NewAnswerMatcher(opts MatchOptions) *AnswerMatcher {
+	return &AnswerMatcher{opts: opts}
+}
+
+// Normalize returns the canonical form used for comparison: compatibility
+// normalized (NFKC), case folded, optionally without accents and
+// punctuation, and with whitespace collapsed. Numbers keep their
+// punctuation so that "4.0" stays a number.
+func (m *AnswerMatcher) Normalize(s string) string {
+	s = norm.NFKC.String(s)
+	s = cases.Fold().String(s)
+	if m.opts.FoldAccents {
+		// A Transformer is stateful, so build a fresh chain per call.
+		folder := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+		if folded, _, err := transform.String(folder, s); err == nil {
+			s = folded
+		}
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	if m.opts.IgnorePunctuation {
+		if _, ok := parseNumber(s); !ok {
+			s = strings.Map(func(r rune) rune {
+				if unicode.IsPunct(r) {
+					return ' '
+				}
+				return r
+			}, s)
+			s = strings.Join(strings.Fields(s), " ")
+		}
+	}
+	return s
+}
+
+// Match compares answer against each accepted answer and returns the
+// best kind of match found.
+func (m *AnswerMatcher) Match(answer string, accepted ...string) MatchKind {
+	best := NoMatch
+	normalized := m.Normalize(answer)
+	for _, want := range accepted {
+		kind := m.matchOne(answer, normalized, want)
+		if kind != NoMatch && (best == NoMatch || kind < best) {
+			best = kind
+		}
+	}
+	return best
+}
+
+// Matches reports whether answer matches any accepted answer.
+func (m *AnswerMatcher) Matches(answer string, accepted ...string) bool {
+	return m.Match(answer, accepted...) != NoMatch
+}
+
+func (m *AnswerMatcher) matchOne(answer, normalized, want string) MatchKind {
+	if answer == want {
+		return ExactMatch
+	}
+	wantNormalized := m.Normalize(want)
+	if normalized == "" || wantNormalized == "" {
+		return NoMatch
+	}
+	if normalized == wantNormalized {
+		return NormalizedMatch
+	}
+	if m.opts.NumericEquivalence {
+		a, okA := parseNumber(normalized)
+		b, okB := parseNumber(wantNormalized)
+		if okA && okB {
+			if math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b)) {
+				return NumericMatch
+			}
+			// Two different numbers never match by edit distance.
+			return NoMatch
+		}
+	}
+	if m.withinEditDistance(normalized, wantNormalized) {
+		return FuzzyMatch
+	}
+	return NoMatch
+}
+
+// withinEditDistance reports whether two normalized strings are close
+// enough to count as the same answer.
+func (m *AnswerMatcher) withinEditDistance(a, b string) bool {
+	if m.opts.MaxEditDistance <= 0 {
+		return false
+	}
+	ra, rb := []rune(a), []rune(b)
+	if min(len(ra), len(rb)) < m.opts.MinFuzzyLength {
+		return false
+	}
+	return levenshtein(ra, rb) <= m.opts.MaxEditDistance
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+var numberWords = map[string]float64{
+	"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+	"eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14, "fifteen": 15,
+	"sixteen": 16, "seventeen": 17, "eighteen": 18, "nineteen": 19,
+	"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50,
+	"sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+	"hundred": 100,
+}
+
+// parseNumber parses a normalized answer as a number, accepting digits
+// ("4", "4.0", "1,000") and English words up to ninety-nine ("four",
+// "twenty-one") plus "hundred". Commas are only accepted as thousands
+// separators, so "1,5" is not a number.
+func parseNumber(s string) (float64, bool) {
+	if digits, ok := stripThousands(s); ok {
+		if v, err := strconv.ParseFloat(digits, 64); err == nil {
+			return v, true
+		}
+	}
+	words := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == '-' })
+	if len(words) == 0 || len(words) > 2 {
+		return 0, false
+	}
+	var total float64
+	for i, w := range words {
+		v, ok := numberWords[w]
+		if !ok {
+			return 0, false
+		}
+		// Only "twenty-one" style pairs: a tens word followed by a unit.
+		if i == 1 && (total < 20 || math.Mod(total, 10) != 0 || v >= 10) {
+			return 0, false
+		}
+		total += v
+	}
+	return total, true
+}
+
+// stripThousands removes the commas from s if they separate the integer
+// part into groups of three digits, as in "12,345.6". It reports false if
+// s has commas anywhere else.
+func stripThousands(s string) (string, bool) {
+	if !strings.Contains(s, ",") {
+		return s, true
+	}
+	intPart, frac, _ := strings.Cut(s, ".")
+	if strings.Contains(frac, ",") {
+		return "", false
+	}
+	sign := ""
+	if intPart != "" && (intPart[0] == '-' || intPart[0] == '+') {
+		sign, intPart = intPart[:1], intPart[1:]
+	}
+	groups := strings.Split(intPart, ",")
+	for i, g := range groups {
+		if g == "" || len(g) > 3 || (i > 0 && len(g) != 3) {
+			return "", false
+		}
+		for _, r := range g {
+			if r < '0' || r > '9' {
+				return "", false
+			}
+		}
+	}
+	digits := sign + strings.Join(groups, "")
+	if frac != "" || strings.HasSuffix(s, ".") {
+		digits += "." + frac
+	}
+	return digits, true
+}
+
+// AnswerCluster groups incorrect answers that are near misses of one another.
+type AnswerCluster struct {
+	Representative string         // most frequent raw answer in the cluster
+	Count          int            // total answers in the cluster
+	Variants       map[string]int // raw answer -> count
+}
+
+// Cluster groups answers whose normalized forms match each other, including
+// by the larger of MaxEditDistance and ClusterEditDistance. Clusters are
+// returned most frequent first.
+func (m *AnswerMatcher) Cluster(counts map[string]int) []AnswerCluster {
+	opts := m.opts
+	opts.MaxEditDistance = max(opts.MaxEditDistance, opts.ClusterEditDistance)
+	near := NewAnswerMatcher(opts)
+
+	raw := make([]string, 0, len(counts))
+	for answer := range counts {
+		raw = append(raw, answer)
+	}
+	// Visit frequent answers first so they become cluster representatives.
+	sort.Slice(raw, func(i, j int) bool {
+		if counts[raw[i]] != counts[raw[j]] {
+			return counts[raw[i]] > counts[raw[j]]
+		}
+		return raw[i] < raw[j]
+	})
+
+	var clusters []AnswerCluster
+	for _, answer := range raw {
+		placed := false
+		for i := range clusters {
+			if near.Matches(answer, clusters[i].Representative) {
+				clusters[i].Count += counts[answer]
+				clusters[i].Variants[answer] = counts[answer]
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			clusters = append(clusters, AnswerCluster{
+				Representative: answer,
+				Count:          counts[answer],
+				Variants:       map[string]int{answer: counts[answer]},
+			})
+		}
+	}
+	sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].Count > clusters[j].Count })
+	return clusters
+}
+
+// mergeClusters combines clusters that share a representative, keeping
+// the order in which representatives first appear.
+func mergeClusters(clusters []AnswerCluster) []AnswerCluster {
+	byRep := make(map[string]int)
+	var merged []AnswerCluster
+	for _, c := range clusters {
+		i, ok := byRep[c.Representative]
+		if !ok {
+			byRep[c.Representative] = len(merged)
+			merged = append(merged, AnswerCluster{Representative: c.Representative, Variants: make(map[string]int)})
+			i = len(merged) - 1
+		}
+		merged[i].Count += c.Count
+		for v, n := range c.Variants {
+			merged[i].Variants[v] += n
+		}
+	}
+	return merged
+}
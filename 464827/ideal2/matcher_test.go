@@ -0,0 +1,110 @@
+package main
+
+import "testing"
+
+// TestAnswerMatcher tests normalization, numeric equivalence and typo tolerance
+func TestAnswerMatcher(t *testing.T) {
+	m := NewAnswerMatcher(DefaultMatchOptions())
+	tests := []struct {
+		answer   string
+		accepted []string
+		want     MatchKind
+	}{
+		{"Paris", []string{"Paris"}, ExactMatch},
+		{"Paris ", []string{"Paris"}, NormalizedMatch},
+		{"paris.", []string{"Paris"}, NormalizedMatch},
+		{"París", []string{"Paris"}, NormalizedMatch},
+		{"ＰＡＲＩＳ", []string{"Paris"}, NormalizedMatch},
+		{"Pariss", []string{"Paris"}, NoMatch},
+		{"Iran", []string{"Iraq"}, NoMatch},
+		{"Mark", []string{"Mars"}, NoMatch},
+		{"Parsi", []string{"Paris"}, NoMatch},
+		{"4.0", []string{"4"}, NumericMatch},
+		{"four", []string{"4"}, NumericMatch},
+		{" Twenty-One ", []string{"21"}, NumericMatch},
+		{"1,000", []string{"1000"}, NumericMatch},
+		{"-12,345,678.5", []string{"-12345678.5"}, NumericMatch},
+		{"1,5", []string{"15"}, NoMatch},
+		{"1,5", []string{"1.5"}, NoMatch},
+		{"1,0000", []string{"10000"}, NoMatch},
+		{"1.000,5", []string{"1000.5"}, NoMatch},
+		{"5", []string{"4"}, NoMatch},
+		{"40", []string{"4"}, NoMatch},
+		{"", []string{"4"}, NoMatch},
+		{"Lutetia", []string{"Paris", "Lutetia"}, ExactMatch},
+		{"lutece", []string{"Paris", "Lutèce"}, NormalizedMatch},
+		{"New  York", []string{"new york"}, NormalizedMatch},
+	}
+	for _, tt := range tests {
+		if got := m.Match(tt.answer, tt.accepted...); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.answer, tt.accepted, got, tt.want)
+		}
+	}
+}
+
+// TestAnswerMatcherOptions tests that each normalization can be switched off
+func TestAnswerMatcherOptions(t *testing.T) {
+	strict := NewAnswerMatcher(MatchOptions{})
+	for _, answer := range []string{"París", "paris.", "Pariss"} {
+		if strict.Matches(answer, "Paris") {
+			t.Errorf("Expected strict matcher to reject %q", answer)
+		}
+	}
+	if !strict.Matches(" PARIS ", "paris") {
+		t.Errorf("Expected case and surrounding space to be ignored even when strict")
+	}
+	if strict.Matches("four", "4") {
+		t.Errorf("Expected strict matcher to reject number words")
+	}
+
+	fuzzy := DefaultMatchOptions()
+	fuzzy.MaxEditDistance = 1
+	if got := NewAnswerMatcher(fuzzy).Match("Pariss", "Paris"); got != FuzzyMatch {
+		t.Errorf("Expected opt-in typo tolerance to accept \"Pariss\", got %v", got)
+	}
+	if !NewAnswerMatcher(fuzzy).Matches("Iraq", "Iran") {
+		t.Errorf("Expected opt-in typo tolerance to accept a one-letter change")
+	}
+	if NewAnswerMatcher(fuzzy).Matches("two", "tow") {
+		t.Errorf("Expected answers shorter than MinFuzzyLength to match exactly")
+	}
+
+	loose := DefaultMatchOptions()
+	loose.MaxEditDistance = 2
+	if !NewAnswerMatcher(loose).Matches("Parsi", "Paris") {
+		t.Errorf("Expected a distance of 2 to accept a transposition")
+	}
+}
+
+// TestClusterIncorrectAnswers tests that near misses are grouped under the most frequent spelling
+func TestClusterIncorrectAnswers(t *testing.T) {
+	quiz, err := buildQuiz(
+		[]Question{{ID: 1, CorrectAnswer: "Paris", AcceptedAnswers: []string{"Lutetia"}}},
+		[]UserResponse{
+			{UserID: "u1", QuestionID: 1, Answer: "Berlin"},
+			{UserID: "u2", QuestionID: 1, Answer: "berlin."},
+			{UserID: "u3", QuestionID: 1, Answer: "Berln"},
+			{UserID: "u4", QuestionID: 1, Answer: "Berlin"},
+			{UserID: "u5", QuestionID: 1, Answer: "Madrid"},
+			{UserID: "u6", QuestionID: 1, Answer: "París"},
+			{UserID: "u7", QuestionID: 1, Answer: "lutetia"},
+		},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := countCorrectAnswers(quiz); got != 2 {
+		t.Errorf("Expected 2 correct answers, got %d", got)
+	}
+	incorrect := findMostCommonIncorrectAnswers(quiz)
+	if len(incorrect) != 2 || incorrect["Berlin"] != 4 || incorrect["Madrid"] != 1 {
+		t.Errorf("Unexpected clustered answers %v", incorrect)
+	}
+
+	report := buildReport(quiz)
+	top := report.IncorrectAnswers[0]
+	if top.Answer != "Berlin" || len(top.Variants) != 2 || top.Variants[0] != "Berln" || top.Variants[1] != "berlin." {
+		t.Errorf("Unexpected top cluster %+v", top)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Respondent is a person taking the quiz.
+type Respondent struct {
+	ID   string
+	Name string
+}
+
+// ResponseRecord is what a respondent did with one question during an attempt.
+type ResponseRecord struct {
+	QuestionID int
+	Answer     string
+	Duration   time.Duration
+	Skipped    bool
+	Timestamp  time.Time
+}
+
+// Attempt is one sitting of the quiz by a respondent. A respondent may
+// have several attempts, each answering any subset of the questions.
+type Attempt struct {
+	ID           string
+	RespondentID string
+	StartedAt    time.Time
+	Records      []ResponseRecord
+}
+
+// Quiz holds the questions together with every recorded attempt.
+type Quiz struct {
+	Questions   []Question
+	Respondents []Respondent
+	Attempts    []Attempt
+	Matcher     *AnswerMatcher // nil means DefaultMatchOptions
+
+	byID map[int]int // question ID -> index in Questions
+}
+
+// ErrInvalidQuiz is wrapped by every validation failure.
+var ErrInvalidQuiz = errors.New("invalid quiz data")
+
+// NewQuiz validates the data and builds a quiz from it.
+func NewQuiz(questions []Question, respondents []Respondent, attempts []Attempt) (*Quiz, error) {
+	quiz := &Quiz{Questions: questions, Respondents: respondents, Attempts: attempts}
+	if err := quiz.Validate(); err != nil {
+		return nil, err
+	}
+	return quiz, nil
+}
+
+// Validate checks referential integrity and per-record consistency, and
+// indexes the questions. It reports every problem found, not just the first.
+func (q *Quiz) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidQuiz}, args...)...))
+	}
+
+	q.byID = make(map[int]int, len(q.Questions))
+	for i, question := range q.Questions {
+		if _, dup := q.byID[question.ID]; dup {
+			invalid("duplicate question ID %d", question.ID)
+			continue
+		}
+		q.byID[question.ID] = i
+	}
+
+	respondents := make(map[string]bool, len(q.Respondents))
+	for _, r := range q.Respondents {
+		if r.ID == "" {
+			invalid("respondent with empty ID")
+		} else if respondents[r.ID] {
+			invalid("duplicate respondent ID %q", r.ID)
+		}
+		respondents[r.ID] = true
+	}
+
+	attempts := make(map[string]bool, len(q.Attempts))
+	for _, a := range q.Attempts {
+		if attempts[a.ID] {
+			invalid("duplicate attempt ID %q", a.ID)
+		}
+		attempts[a.ID] = true
+		if !respondents[a.RespondentID] {
+			invalid("attempt %q references unknown respondent %q", a.ID, a.RespondentID)
+		}
+		seen := make(map[int]bool, len(a.Records))
+		for _, r := range a.Records {
+			if _, ok := q.byID[r.QuestionID]; !ok {
+				invalid("attempt %q answers unknown question %d", a.ID, r.QuestionID)
+			}
+			if seen[r.QuestionID] {
+				invalid("attempt %q answers question %d more than once", a.ID, r.QuestionID)
+			}
+			seen[r.QuestionID] = true
+			if r.Duration < 0 {
+				invalid("attempt %q has negative duration for question %d", a.ID, r.QuestionID)
+			}
+			if r.Skipped && r.Answer != "" {
+				invalid("attempt %q skipped question %d but recorded answer %q", a.ID, r.QuestionID, r.Answer)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Question returns the question with the given ID.
+func (q *Quiz) Question(id int) (Question, bool) {
+	i, ok := q.byID[id]
+	if !ok {
+		return Question{}, false
+	}
+	return q.Questions[i], true
+}
+
+// matcher returns the quiz's answer matcher.
+func (q *Quiz) matcher() *AnswerMatcher {
+	if q.Matcher == nil {
+		return defaultMatcher
+	}
+	return q.Matcher
+}
+
+var defaultMatcher = NewAnswerMatcher(DefaultMatchOptions())
+
+// isCorrect reports whether the record answers question correctly.
+func (q *Quiz) isCorrect(question Question, r ResponseRecord) bool {
+	return !r.Skipped && q.matcher().Matches(r.Answer, question.accepted()...)
+}
+
+// recordsFor returns every record for questionID across all attempts, in
+// attempt order.
+func (q *Quiz) recordsFor(questionID int) []ResponseRecord {
+	var records []ResponseRecord
+	for _, a := range q.Attempts {
+		for _, r := range a.Records {
+			if r.QuestionID == questionID {
+				records = append(records, r)
+			}
+		}
+	}
+	return records
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestValidateRejectsInconsistentData tests that every integrity problem is reported
+func TestValidateRejectsInconsistentData(t *testing.T) {
+	questions := []Question{{ID: 1, CorrectAnswer: "a"}, {ID: 1, CorrectAnswer: "b"}}
+	respondents := []Respondent{{ID: "u1"}, {ID: "u1"}}
+	attempts := []Attempt{
+		{ID: "a1", RespondentID: "ghost", Records: []ResponseRecord{
+			{QuestionID: 9, Answer: "x"},
+			{QuestionID: 1, Answer: "a", Duration: -time.Second},
+			{QuestionID: 1, Answer: "late", Skipped: true},
+		}},
+	}
+
+	_, err := NewQuiz(questions, respondents, attempts)
+	if !errors.Is(err, ErrInvalidQuiz) {
+		t.Fatalf("Expected ErrInvalidQuiz, got %v", err)
+	}
+	for _, want := range []string{
+		"duplicate question ID 1",
+		`duplicate respondent ID "u1"`,
+		`unknown respondent "ghost"`,
+		"unknown question 9",
+		"negative duration",
+		"answers question 1 more than once",
+		`skipped question 1 but recorded answer "late"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error to mention %q, got:\n%v", want, err)
+		}
+	}
+}
+
+// TestInsightsOnSampleQuiz tests the ported insight functions against the sample data
+func TestInsightsOnSampleQuiz(t *testing.T) {
+	quiz, err := sampleQuiz()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := countCorrectAnswers(quiz); got != 4 {
+		t.Errorf("Expected 4 correct answers, got %d", got)
+	}
+	q2, _ := quiz.Question(2)
+	if got := countCorrectAnswersForQuestion(quiz, q2); got != 2 {
+		t.Errorf("Expected 2 correct answers for question 2, got %d", got)
+	}
+
+	incorrect := findMostCommonIncorrectAnswers(quiz)
+	if len(incorrect) != 4 || incorrect["Berlin"] != 1 || incorrect[""] != 0 {
+		t.Errorf("Unexpected incorrect answers %v", incorrect)
+	}
+	if got := calculateAverageResponseTime(quiz); got != 3*time.Second {
+		t.Errorf("Expected average response time 3s, got %v", got)
+	}
+	if skipped := findMostSkippedQuestions(quiz); skipped[1] != 0 || skipped[2] != 1 {
+		t.Errorf("Unexpected skip counts %v", skipped)
+	}
+}
+
+// TestMultipleAttempts tests that records from repeated attempts are all counted
+func TestMultipleAttempts(t *testing.T) {
+	quiz, err := NewQuiz(
+		[]Question{{ID: 7, CorrectAnswer: "Yes"}},
+		[]Respondent{{ID: "u1"}},
+		[]Attempt{
+			{ID: "a1", RespondentID: "u1", Records: []ResponseRecord{{QuestionID: 7, Answer: "no"}}},
+			{ID: "a2", RespondentID: "u1", Records: []ResponseRecord{{QuestionID: 7, Answer: "yes"}}},
+		},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := countCorrectAnswers(quiz); got != 1 {
+		t.Errorf("Expected 1 correct answer, got %d", got)
+	}
+	if got := findMostCommonIncorrectAnswers(quiz)["no"]; got != 1 {
+		t.Errorf("Expected 1 incorrect 'no', got %d", got)
+	}
+}
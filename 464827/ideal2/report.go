@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AnswerCount is an answer and how many times it was given.
+type AnswerCount struct {
+	Answer   string   `json:"answer"`
+	Count    int      `json:"count"`
+	Variants []string `json:"variants,omitempty"` // near-miss spellings counted with Answer
+}
+
+// QuestionCount is a per-question tally.
+type QuestionCount struct {
+	QuestionID int    `json:"question_id"`
+	Text       string `json:"text"`
+	Count      int    `json:"count"`
+}
+
+// Report gathers every insight about a quiz in a deterministic order.
+type Report struct {
+	TotalQuestions            int             `json:"total_questions"`
+	TotalCorrect              int             `json:"total_correct"`
+	AverageCorrectPerQuestion float64         `json:"average_correct_per_question"`
+	AverageResponseTimeMS     int64           `json:"average_response_time_ms"`
+	IncorrectAnswers          []AnswerCount   `json:"incorrect_answers"` // most common first
+	SkippedQuestions          []QuestionCount `json:"skipped_questions"` // most skipped first
+	Attempts                  int             `json:"attempts"`
+	GroupSize                 int             `json:"group_size"`
+	CronbachAlpha             *float64        `json:"cronbach_alpha"` // nil when undefined
+	Items                     []ItemStats     `json:"items"`
+	ResponseTimes             TimingAnalysis  `json:"response_times"`
+}
+
+// buildReport computes all insights for quiz.
+func buildReport(quiz *Quiz) Report {
+	report := Report{
+		TotalQuestions:        len(quiz.Questions),
+		TotalCorrect:          countCorrectAnswers(quiz),
+		AverageResponseTimeMS: calculateAverageResponseTime(quiz).Milliseconds(),
+		IncorrectAnswers:      sortedClusters(clusterIncorrectAnswers(quiz)),
+		SkippedQuestions:      sortedSkipCounts(quiz, findMostSkippedQuestions(quiz)),
+	}
+	if report.TotalQuestions > 0 {
+		report.AverageCorrectPerQuestion = float64(report.TotalCorrect) / float64(report.TotalQuestions)
+	}
+
+	report.ResponseTimes = analyzeTiming(quiz, DefaultOutlierOptions())
+
+	analysis := analyzeItems(quiz)
+	report.Attempts, report.GroupSize, report.Items = analysis.Attempts, analysis.GroupSize, analysis.Items
+	if !math.IsNaN(analysis.CronbachAlpha) {
+		alpha := analysis.CronbachAlpha
+		report.CronbachAlpha = &alpha
+	}
+	return report
+}
+
+// sortedAnswerCounts orders answers by count, then alphabetically.
+func sortedAnswerCounts(counts map[string]int) []AnswerCount {
+	sorted := make([]AnswerCount, 0, len(counts))
+	for answer, count := range counts {
+		sorted = append(sorted, AnswerCount{Answer: answer, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Answer < sorted[j].Answer
+	})
+	return sorted
+}
+
+// sortedClusters orders clusters like sortedAnswerCounts and lists the
+// other spellings in each cluster alphabetically.
+func sortedClusters(clusters []AnswerCluster) []AnswerCount {
+	counts := make(map[string]int, len(clusters))
+	variants := make(map[string][]string, len(clusters))
+	for _, c := range clusters {
+		counts[c.Representative] = c.Count
+		for v := range c.Variants {
+			if v != c.Representative {
+				variants[c.Representative] = append(variants[c.Representative], v)
+			}
+		}
+		sort.Strings(variants[c.Representative])
+	}
+	sorted := sortedAnswerCounts(counts)
+	for i := range sorted {
+		sorted[i].Variants = variants[sorted[i].Answer]
+	}
+	return sorted
+}
+
+// sortedSkipCounts orders questions by skip count, then by ID.
+func sortedSkipCounts(quiz *Quiz, counts map[int]int) []QuestionCount {
+	sorted := make([]QuestionCount, 0, len(counts))
+	for id, count := range counts {
+		question, _ := quiz.Question(id)
+		sorted = append(sorted, QuestionCount{QuestionID: id, Text: question.Text, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].QuestionID < sorted[j].QuestionID
+	})
+	return sorted
+}
+
+// writeReport writes report as "text", "json" or "markdown".
+func writeReport(w io.Writer, report Report, format string) error {
+	switch format {
+	case "text":
+		return writeTextReport(w, report)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	case "markdown", "md":
+		return writeMarkdownReport(w, report)
+	default:
+		return fmt.Errorf("unknown report format %q", format)
+	}
+}
+
+func writeTextReport(w io.Writer, r Report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Total questions: %d\n", r.TotalQuestions)
+	fmt.Fprintf(&b, "Total correct answers: %d\n", r.TotalCorrect)
+	fmt.Fprintf(&b, "Average correct answers per question: %.2f\n", r.AverageCorrectPerQuestion)
+	fmt.Fprintf(&b, "Most common incorrect answers:\n")
+	for _, a := range r.IncorrectAnswers {
+		fmt.Fprintf(&b, "%s: %d times", a.Answer, a.Count)
+		if len(a.Variants) > 0 {
+			fmt.Fprintf(&b, " (including %s)", strings.Join(a.Variants, ", "))
+		}
+		fmt.Fprintf(&b, "\n")
+	}
+	fmt.Fprintf(&b, "Average response time: %s\n", time.Duration(r.AverageResponseTimeMS)*time.Millisecond)
+	fmt.Fprintf(&b, "Most skipped questions:\n")
+	for _, q := range r.SkippedQuestions {
+		fmt.Fprintf(&b, "Question %d: %d times\n", q.QuestionID, q.Count)
+	}
+
+	fmt.Fprintf(&b, "Item analysis (%d attempts, upper/lower groups of %d):\n", r.Attempts, r.GroupSize)
+	for _, item := range r.Items {
+		fmt.Fprintf(&b, "Question %d: difficulty %.2f, discrimination %.2f, point-biserial %.2f, skipped %d\n",
+			item.QuestionID, item.Difficulty, item.Discrimination, item.PointBiserial, item.Skipped)
+		for _, d := range item.Distractors {
+			fmt.Fprintf(&b, "  distractor %q: %d (%.0f%%), upper %d, lower %d\n", d.Answer, d.Count, d.Proportion*100, d.Upper, d.Lower)
+		}
+		for _, flag := range item.Flags {
+			fmt.Fprintf(&b, "  review: %s\n", flag)
+		}
+	}
+	fmt.Fprintf(&b, "Cronbach's alpha: %s\n", formatAlpha(r.CronbachAlpha))
+
+	t := r.ResponseTimes
+	fmt.Fprintf(&b, "Response times: median %s, p10 %s, p90 %s, time/correctness correlation %.2f\n",
+		t.Overall.Median, t.Overall.P10, t.Overall.P90, t.TimeCorrectness)
+	for _, q := range t.Questions {
+		fmt.Fprintf(&b, "Question %d: median %s, p25 %s, p75 %s, correct %s vs incorrect %s\n",
+			q.QuestionID, q.Distribution.Median, q.Distribution.P25, q.Distribution.P75, q.MeanCorrect, q.MeanIncorrect)
+	}
+	for _, rt := range t.Respondents {
+		if rt.Guesses > 0 || rt.Stalls > 0 {
+			fmt.Fprintf(&b, "Respondent %s: median %s, %d guesses (%.0f%%), %d stalls\n",
+				rt.RespondentID, rt.Distribution.Median, rt.Guesses, rt.GuessRate*100, rt.Stalls)
+		}
+	}
+	for _, o := range t.Outliers {
+		fmt.Fprintf(&b, "  %s: %s on question %d in %s (correct: %t)\n", o.Kind, o.RespondentID, o.QuestionID, o.Duration, o.Correct)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownReport(w io.Writer, r Report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Quiz insights\n\n")
+	fmt.Fprintf(&b, "| Metric | Value |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Total questions | %d |\n", r.TotalQuestions)
+	fmt.Fprintf(&b, "| Total correct answers | %d |\n", r.TotalCorrect)
+	fmt.Fprintf(&b, "| Average correct answers per question | %.2f |\n", r.AverageCorrectPerQuestion)
+	fmt.Fprintf(&b, "| Average response time | %s |\n", time.Duration(r.AverageResponseTimeMS)*time.Millisecond)
+	fmt.Fprintf(&b, "| Attempts | %d |\n", r.Attempts)
+	fmt.Fprintf(&b, "| Cronbach's alpha | %s |\n", formatAlpha(r.CronbachAlpha))
+
+	fmt.Fprintf(&b, "\n## Most common incorrect answers\n\n| Answer | Count | Variants |\n|---|---|---|\n")
+	for _, a := range r.IncorrectAnswers {
+		fmt.Fprintf(&b, "| %s | %d | %s |\n", markdownEscape(a.Answer), a.Count, markdownEscape(strings.Join(a.Variants, ", ")))
+	}
+
+	fmt.Fprintf(&b, "\n## Most skipped questions\n\n| Question | Text | Skipped |\n|---|---|---|\n")
+	for _, q := range r.SkippedQuestions {
+		fmt.Fprintf(&b, "| %d | %s | %d |\n", q.QuestionID, markdownEscape(q.Text), q.Count)
+	}
+
+	fmt.Fprintf(&b, "\n## Item analysis\n\n| Question | Difficulty | Discrimination | Point-biserial | Skipped | Review |\n|---|---|---|---|---|---|\n")
+	for _, item := range r.Items {
+		fmt.Fprintf(&b, "| %d | %.2f | %.2f | %.2f | %d | %s |\n", item.QuestionID, item.Difficulty, item.Discrimination,
+			item.PointBiserial, item.Skipped, markdownEscape(strings.Join(item.Flags, "; ")))
+	}
+
+	fmt.Fprintf(&b, "\n## Response times\n\n| Question | Count | Median | P10 | P90 | Mean correct | Mean incorrect | Time/correctness |\n|---|---|---|---|---|---|---|---|\n")
+	for _, q := range r.ResponseTimes.Questions {
+		d := q.Distribution
+		fmt.Fprintf(&b, "| %d | %d | %s | %s | %s | %s | %s | %.2f |\n", q.QuestionID, d.Count, d.Median, d.P10, d.P90, q.MeanCorrect, q.MeanIncorrect, q.TimeCorrectness)
+	}
+	fmt.Fprintf(&b, "\n| Respondent | Answers | Median | Guesses | Guess rate | Stalls |\n|---|---|---|---|---|---|\n")
+	for _, rt := range r.ResponseTimes.Respondents {
+		fmt.Fprintf(&b, "| %s | %d | %s | %d | %.0f%% | %d |\n", markdownEscape(rt.RespondentID), rt.Distribution.Count, rt.Distribution.Median, rt.Guesses, rt.GuessRate*100, rt.Stalls)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatAlpha(alpha *float64) string {
+	if alpha == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.3f", *alpha)
+}
+
+// markdownEscape keeps table cells from breaking the table layout.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
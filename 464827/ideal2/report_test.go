@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestLoadQuizFiles tests that CSV and JSON inputs load into the same quiz as the sample data
+func TestLoadQuizFiles(t *testing.T) {
+	sample, _ := sampleQuiz()
+	want := buildReport(sample)
+
+	for _, files := range [][2]string{
+		{"testdata/questions.csv", "testdata/responses.csv"},
+		{"testdata/questions.json", "testdata/responses.jsonl"},
+	} {
+		quiz, err := loadQuizFiles(files[0], files[1])
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", files, err)
+		}
+		var got, expected bytes.Buffer
+		writeReport(&got, buildReport(quiz), "json")
+		writeReport(&expected, want, "json")
+		if got.String() != expected.String() {
+			t.Errorf("%v: expected report\n%s\ngot\n%s", files, expected.String(), got.String())
+		}
+	}
+}
+
+// TestReadResponsesErrors tests that malformed input is reported with its row
+func TestReadResponsesErrors(t *testing.T) {
+	for _, tt := range []struct {
+		input, format, want string
+	}{
+		{"user_id,answer\nu1,a\n", "csv", `missing column "question_id"`},
+		{"user_id,question_id,answer\nu1,one,a\n", "csv", `row 1: invalid question_id "one"`},
+		{"user_id,question_id,answer,skipped\nu1,1,a,maybe\n", "csv", `row 1: invalid skipped "maybe"`},
+		{`{"user_id":"u1"}` + "\n{bad\n", "jsonl", "record 2"},
+		{"", "xml", `unsupported format "xml"`},
+	} {
+		_, err := readResponses(strings.NewReader(tt.input), tt.format)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Expected error containing %q, got %v", tt.want, err)
+		}
+	}
+}
+
+// TestBuildQuizValidates tests that imported responses go through quiz validation
+func TestBuildQuizValidates(t *testing.T) {
+	questions := []Question{{ID: 1, CorrectAnswer: "a"}}
+	_, err := buildQuiz(questions, []UserResponse{{UserID: "u1", QuestionID: 2, Answer: "a"}})
+	if !errors.Is(err, ErrInvalidQuiz) {
+		t.Errorf("Expected ErrInvalidQuiz for an unknown question, got %v", err)
+	}
+	_, err = buildQuiz(questions, []UserResponse{
+		{UserID: "u1", AttemptID: "x", QuestionID: 1},
+		{UserID: "u2", AttemptID: "x", QuestionID: 1},
+	})
+	if !errors.Is(err, ErrInvalidQuiz) {
+		t.Errorf("Expected ErrInvalidQuiz for a shared attempt, got %v", err)
+	}
+
+	quiz, err := buildQuiz(questions, []UserResponse{
+		{UserID: "u1", AttemptID: "first", QuestionID: 1, Answer: "b"},
+		{UserID: "u1", AttemptID: "retry", QuestionID: 1, Answer: "a"},
+	})
+	if err != nil || len(quiz.Respondents) != 1 || len(quiz.Attempts) != 2 {
+		t.Fatalf("Expected 1 respondent with 2 attempts, got %+v, %v", quiz, err)
+	}
+}
+
+// TestBuildQuizDefaultAttemptIDs tests that generated attempt IDs never merge with explicit ones
+func TestBuildQuizDefaultAttemptIDs(t *testing.T) {
+	questions := []Question{{ID: 1, CorrectAnswer: "a"}, {ID: 2, CorrectAnswer: "b"}}
+	quiz, err := buildQuiz(questions, []UserResponse{
+		{UserID: "u1", QuestionID: 1, Answer: "a"},
+		{UserID: "u1", AttemptID: "u1-1", QuestionID: 1, Answer: "b"},
+		{UserID: "u1", QuestionID: 2, Answer: "b"},
+		{UserID: "u2", AttemptID: "u2-1", QuestionID: 1, Answer: "a"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string]int)
+	for _, a := range quiz.Attempts {
+		got[a.ID] = len(a.Records)
+	}
+	want := map[string]int{"u1-2": 2, "u1-1": 1, "u2-1": 1}
+	if len(got) != len(want) {
+		t.Fatalf("Expected attempts %v, got %v", want, got)
+	}
+	for id, n := range want {
+		if got[id] != n {
+			t.Errorf("Expected attempt %q to have %d records, got %d", id, n, got[id])
+		}
+	}
+}
+
+// TestReportOrdering tests that report sections are sorted rather than in map order
+func TestReportOrdering(t *testing.T) {
+	sorted := sortedAnswerCounts(map[string]int{"b": 2, "a": 2, "z": 5, "c": 1})
+	want := []AnswerCount{{Answer: "z", Count: 5}, {Answer: "a", Count: 2}, {Answer: "b", Count: 2}, {Answer: "c", Count: 1}}
+	for i := range want {
+		if sorted[i].Answer != want[i].Answer || sorted[i].Count != want[i].Count {
+			t.Fatalf("Expected %v, got %v", want, sorted)
+		}
+	}
+
+	quiz, _ := sampleQuiz()
+	report := buildReport(quiz)
+	if report.SkippedQuestions[0].QuestionID != 2 || report.SkippedQuestions[1].QuestionID != 1 {
+		t.Errorf("Expected question 2 to be listed first, got %+v", report.SkippedQuestions)
+	}
+
+	// Repeated renders must be byte-for-byte identical.
+	var first, second bytes.Buffer
+	writeReport(&first, report, "text")
+	for i := 0; i < 20; i++ {
+		second.Reset()
+		writeReport(&second, buildReport(quiz), "text")
+		if first.String() != second.String() {
+			t.Fatalf("Expected stable text output, got\n%s\nand\n%s", first.String(), second.String())
+		}
+	}
+}
+
+// TestReportFormats tests the JSON and Markdown encodings
+func TestReportFormats(t *testing.T) {
+	quiz, _ := sampleQuiz()
+	report := buildReport(quiz)
+
+	var buf bytes.Buffer
+	if err := writeReport(&buf, report, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if decoded.TotalCorrect != 4 || decoded.AverageResponseTimeMS != 3000 || decoded.CronbachAlpha == nil {
+		t.Errorf("Unexpected decoded report %+v", decoded)
+	}
+
+	buf.Reset()
+	report.IncorrectAnswers = append(report.IncorrectAnswers, AnswerCount{Answer: "a|b", Count: 1})
+	if err := writeReport(&buf, report, "markdown"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"| Total correct answers | 4 |", `| a\|b | 1 |  |`, "| 2 | What is 2 + 2? | 1 |"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected markdown to contain %q, got\n%s", want, buf.String())
+		}
+	}
+
+	if err := writeReport(&buf, report, "pdf"); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QuestionStats are the live counters for one question.
+type QuestionStats struct {
+	QuestionID    int     `json:"question_id"`
+	Text          string  `json:"text"`
+	Responses     int     `json:"responses"` // including skips
+	Correct       int     `json:"correct"`
+	Incorrect     int     `json:"incorrect"`
+	Skipped       int     `json:"skipped"`
+	CorrectRate   float64 `json:"correct_rate"` // share of answered responses
+	AverageTimeMS int64   `json:"average_time_ms"`
+}
+
+// questionCounters is the incrementally maintained state behind QuestionStats.
+type questionCounters struct {
+	correct, incorrect, skipped int
+	totalTime                   time.Duration
+	incorrectAnswers            map[string]int
+	clusters                    []AnswerCluster // of incorrectAnswers, valid if clustered
+	clustered                   bool
+}
+
+// QuizStore holds a quiz that grows as responses are submitted. Every
+// submission updates the per-question counters in constant time, so the
+// stats endpoints never rescan the history. Incorrect-answer clusters are
+// cached per question and rebuilt on read only for questions that received
+// a new incorrect answer since. It is safe for concurrent use.
+type QuizStore struct {
+	mu              sync.RWMutex
+	quiz            *Quiz
+	counters        map[int]*questionCounters
+	respondents     map[string]bool
+	attempts        map[string]int    // attempt ID -> index in quiz.Attempts
+	defaultAttempts map[string]string // user ID -> attempt for responses without one
+}
+
+// NewQuizStore creates a store seeded with the questions and any
+// attempts already recorded in quiz.
+func NewQuizStore(quiz *Quiz) (*QuizStore, error) {
+	empty, err := NewQuiz(quiz.Questions, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	empty.Matcher = quiz.Matcher
+	s := &QuizStore{
+		quiz:        empty,
+		counters:    make(map[int]*questionCounters),
+		respondents: make(map[string]bool),
+		attempts:    make(map[string]int),
+
+		defaultAttempts: make(map[string]string),
+	}
+	for _, q := range quiz.Questions {
+		s.counters[q.ID] = &questionCounters{incorrectAnswers: make(map[string]int)}
+	}
+	for _, a := range quiz.Attempts {
+		for _, r := range a.Records {
+			resp := UserResponse{
+				UserID:     a.RespondentID,
+				AttemptID:  a.ID,
+				QuestionID: r.QuestionID,
+				Answer:     r.Answer,
+				DurationMS: r.Duration.Milliseconds(),
+				Skipped:    r.Skipped,
+				Timestamp:  r.Timestamp,
+			}
+			if err := s.Submit(resp); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+// Submit validates and records one response. Responses without an attempt
+// ID share one attempt per user, named as buildQuiz names them but skipping
+// only names other users' attempts hold, so a seeded user keeps adding to
+// their own userID-1.
+func (s *QuizStore) Submit(resp UserResponse) error {
+	if resp.UserID == "" {
+		return fmt.Errorf("%w: user_id is required", ErrInvalidQuiz)
+	}
+	if resp.DurationMS < 0 {
+		return fmt.Errorf("%w: negative duration_ms", ErrInvalidQuiz)
+	}
+	if resp.Skipped && resp.Answer != "" {
+		return fmt.Errorf("%w: skipped response has answer %q", ErrInvalidQuiz, resp.Answer)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attemptID := resp.AttemptID
+	if attemptID == "" {
+		attemptID = s.defaultAttempts[resp.UserID]
+		if attemptID == "" {
+			attemptID = defaultAttemptID(resp.UserID, func(id string) bool {
+				i, ok := s.attempts[id]
+				return ok && s.quiz.Attempts[i].RespondentID != resp.UserID
+			})
+		}
+	}
+	question, ok := s.quiz.Question(resp.QuestionID)
+	if !ok {
+		return fmt.Errorf("%w: unknown question %d", ErrInvalidQuiz, resp.QuestionID)
+	}
+	i, exists := s.attempts[attemptID]
+	if exists {
+		attempt := s.quiz.Attempts[i]
+		if attempt.RespondentID != resp.UserID {
+			return fmt.Errorf("%w: attempt %q belongs to %q", ErrInvalidQuiz, attemptID, attempt.RespondentID)
+		}
+		for _, r := range attempt.Records {
+			if r.QuestionID == resp.QuestionID {
+				return fmt.Errorf("%w: attempt %q already answered question %d", ErrInvalidQuiz, attemptID, resp.QuestionID)
+			}
+		}
+	}
+
+	if !s.respondents[resp.UserID] {
+		s.respondents[resp.UserID] = true
+		s.quiz.Respondents = append(s.quiz.Respondents, Respondent{ID: resp.UserID})
+	}
+	if !exists {
+		i = len(s.quiz.Attempts)
+		s.attempts[attemptID] = i
+		s.quiz.Attempts = append(s.quiz.Attempts, Attempt{ID: attemptID, RespondentID: resp.UserID, StartedAt: resp.Timestamp})
+	}
+	if resp.AttemptID == "" {
+		s.defaultAttempts[resp.UserID] = attemptID
+	}
+	record := ResponseRecord{
+		QuestionID: resp.QuestionID,
+		Answer:     resp.Answer,
+		Duration:   time.Duration(resp.DurationMS) * time.Millisecond,
+		Skipped:    resp.Skipped,
+		Timestamp:  resp.Timestamp,
+	}
+	s.quiz.Attempts[i].Records = append(s.quiz.Attempts[i].Records, record)
+
+	c := s.counters[resp.QuestionID]
+	switch {
+	case record.Skipped:
+		c.skipped++
+	case s.quiz.isCorrect(question, record):
+		c.correct++
+		c.totalTime += record.Duration
+	default:
+		c.incorrect++
+		c.totalTime += record.Duration
+		c.incorrectAnswers[record.Answer]++
+		c.clustered = false
+	}
+	return nil
+}
+
+// QuestionStats returns the live counters for one question.
+func (s *QuizStore) QuestionStats(id int) (QuestionStats, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	question, ok := s.quiz.Question(id)
+	if !ok {
+		return QuestionStats{}, false
+	}
+	return s.statsLocked(question), true
+}
+
+// AllQuestionStats returns the live counters for every question, in question order.
+func (s *QuizStore) AllQuestionStats() []QuestionStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	stats := make([]QuestionStats, len(s.quiz.Questions))
+	for i, question := range s.quiz.Questions {
+		stats[i] = s.statsLocked(question)
+	}
+	return stats
+}
+
+func (s *QuizStore) statsLocked(question Question) QuestionStats {
+	c := s.counters[question.ID]
+	stats := QuestionStats{
+		QuestionID: question.ID,
+		Text:       question.Text,
+		Responses:  c.correct + c.incorrect + c.skipped,
+		Correct:    c.correct,
+		Incorrect:  c.incorrect,
+		Skipped:    c.skipped,
+	}
+	if answered := c.correct + c.incorrect; answered > 0 {
+		stats.CorrectRate = float64(c.correct) / float64(answered)
+		stats.AverageTimeMS = (c.totalTime / time.Duration(answered)).Milliseconds()
+	}
+	return stats
+}
+
+// TopIncorrectAnswers returns up to limit clustered incorrect answers,
+// most common first. A limit of zero or less returns all of them. Only
+// questions with new incorrect answers since the last call are clustered
+// again; the rest reuse their cached clusters.
+func (s *QuizStore) TopIncorrectAnswers(limit int) []AnswerCount {
+	s.mu.Lock()
+	matcher := s.quiz.matcher()
+	var clusters []AnswerCluster
+	for _, question := range s.quiz.Questions {
+		c := s.counters[question.ID]
+		if !c.clustered {
+			c.clusters = matcher.Cluster(c.incorrectAnswers)
+			c.clustered = true
+		}
+		clusters = append(clusters, c.clusters...)
+	}
+	s.mu.Unlock()
+	return truncate(sortedClusters(mergeClusters(clusters)), limit)
+}
+
+// MostSkippedQuestions returns up to limit questions, most skipped first.
+func (s *QuizStore) MostSkippedQuestions(limit int) []QuestionCount {
+	s.mu.RLock()
+	counts := make(map[int]int, len(s.counters))
+	for id, c := range s.counters {
+		counts[id] = c.skipped
+	}
+	sorted := sortedSkipCounts(s.quiz, counts)
+	s.mu.RUnlock()
+	return truncate(sorted, limit)
+}
+
+// Report builds the full insight report from the current data.
+func (s *QuizStore) Report() Report {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return buildReport(s.quiz)
+}
+
+func truncate[T any](xs []T, limit int) []T {
+	if limit > 0 && len(xs) > limit {
+		return xs[:limit]
+	}
+	return xs
+}
+
+// maxSubmissionBytes bounds the body of a single submission request.
+const maxSubmissionBytes = 1 << 20
+
+// newQuizHandler returns the HTTP API for store:
+//
+//	POST /responses                 submit one UserResponse or an array of them
+//	GET  /questions/stats           live stats for every question
+//	GET  /questions/{id}/stats      live stats for one question
+//	GET  /answers/incorrect?limit=N most common incorrect answers
+//	GET  /questions/skipped?limit=N most skipped questions
+//	GET  /report                    the full insight report
+func newQuizHandler(store *QuizStore) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /responses", func(w http.ResponseWriter, r *http.Request) {
+		responses, err := decodeSubmission(http.MaxBytesReader(w, r.Body, maxSubmissionBytes))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		// Each response is validated and applied on its own; stop at the
+		// first rejection and report how many were accepted before it.
+		for i, resp := range responses {
+			if err := store.Submit(resp); err != nil {
+				status := http.StatusUnprocessableEntity
+				writeJSON(w, status, map[string]any{"accepted": i, "error": err.Error()})
+				return
+			}
+		}
+		writeJSON(w, http.StatusCreated, map[string]any{"accepted": len(responses)})
+	})
+	mux.HandleFunc("GET /questions/stats", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, store.AllQuestionStats())
+	})
+	mux.HandleFunc("GET /questions/{id}/stats", func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid question id %q", r.PathValue("id")))
+			return
+		}
+		stats, ok := store.QuestionStats(id)
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Errorf("question %d not found", id))
+			return
+		}
+		writeJSON(w, http.StatusOK, stats)
+	})
+	mux.HandleFunc("GET /answers/incorrect", func(w http.ResponseWriter, r *http.Request) {
+		limit, err := limitParam(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, store.TopIncorrectAnswers(limit))
+	})
+	mux.HandleFunc("GET /questions/skipped", func(w http.ResponseWriter, r *http.Request) {
+		limit, err := limitParam(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, store.MostSkippedQuestions(limit))
+	})
+	mux.HandleFunc("GET /report", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, store.Report())
+	})
+	return mux
+}
+
+// decodeSubmission accepts either a single JSON object or an array.
+func decodeSubmission(body io.Reader) ([]UserResponse, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		var responses []UserResponse
+		if err := json.Unmarshal(data, &responses); err != nil {
+			return nil, err
+		}
+		return responses, nil
+	}
+	var resp UserResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	return []UserResponse{resp}, nil
+}
+
+func limitParam(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 0 {
+		return 0, errors.New("limit must be a non-negative integer")
+	}
+	return limit, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// newTestServer starts the API over the sample quiz.
+func newTestServer(t *testing.T) (*httptest.Server, *QuizStore) {
+	t.Helper()
+	quiz, err := sampleQuiz()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewQuizStore(quiz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(newQuizHandler(store))
+	t.Cleanup(srv.Close)
+	return srv, store
+}
+
+func getJSON(t *testing.T, url string, v any) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v != nil && resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func postJSON(t *testing.T, url, body string) (int, map[string]any) {
+	t.Helper()
+	resp, err := http.Post(url, "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var result map[string]any
+	json.NewDecoder(resp.Body).Decode(&result)
+	return resp.StatusCode, result
+}
+
+// TestServerSeededStats tests that the stats endpoints reflect the seeded quiz
+func TestServerSeededStats(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	var all []QuestionStats
+	if code := getJSON(t, srv.URL+"/questions/stats", &all); code != http.StatusOK || len(all) != 2 {
+		t.Fatalf("Expected 2 question stats, got %d: %+v", code, all)
+	}
+	want := QuestionStats{QuestionID: 2, Text: "What is 2 + 2?", Responses: 5, Correct: 2, Incorrect: 2, Skipped: 1, CorrectRate: 0.5, AverageTimeMS: 2500}
+	if all[1] != want {
+		t.Errorf("Expected %+v, got %+v", want, all[1])
+	}
+
+	var one QuestionStats
+	if code := getJSON(t, srv.URL+"/questions/1/stats", &one); code != http.StatusOK || one.Correct != 2 {
+		t.Errorf("Unexpected question 1 stats %d: %+v", code, one)
+	}
+	if code := getJSON(t, srv.URL+"/questions/9/stats", nil); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown question, got %d", code)
+	}
+	if code := getJSON(t, srv.URL+"/questions/abc/stats", nil); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a malformed id, got %d", code)
+	}
+
+	var skipped []QuestionCount
+	getJSON(t, srv.URL+"/questions/skipped?limit=1", &skipped)
+	if len(skipped) != 1 || skipped[0].QuestionID != 2 || skipped[0].Count != 1 {
+		t.Errorf("Unexpected most skipped %+v", skipped)
+	}
+	if code := getJSON(t, srv.URL+"/answers/incorrect?limit=-1", nil); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a negative limit, got %d", code)
+	}
+}
+
+// TestServerSubmit tests that submissions update the stats incrementally
+func TestServerSubmit(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	code, body := postJSON(t, srv.URL+"/responses", `[
+		{"user_id": "user6", "question_id": 1, "answer": "berlin", "duration_ms": 1000},
+		{"user_id": "user7", "question_id": 1, "answer": "Berlin.", "duration_ms": 1000},
+		{"user_id": "user6", "question_id": 2, "skipped": true}
+	]`)
+	if code != http.StatusCreated || body["accepted"] != float64(3) {
+		t.Fatalf("Expected 3 accepted, got %d %v", code, body)
+	}
+
+	var top []AnswerCount
+	getJSON(t, srv.URL+"/answers/incorrect?limit=1", &top)
+	if len(top) != 1 || top[0].Answer != "Berlin" || top[0].Count != 3 {
+		t.Errorf("Expected Berlin with 3 near misses on top, got %+v", top)
+	}
+
+	var q1 QuestionStats
+	getJSON(t, srv.URL+"/questions/1/stats", &q1)
+	if q1.Responses != 6 || q1.Incorrect != 4 {
+		t.Errorf("Unexpected question 1 stats after submit %+v", q1)
+	}
+
+	var report Report
+	getJSON(t, srv.URL+"/report", &report)
+	if report.Attempts != 7 || report.SkippedQuestions[0].Count != 2 {
+		t.Errorf("Expected the report to include the new attempts, got %d attempts and %+v", report.Attempts, report.SkippedQuestions)
+	}
+}
+
+// TestServerRejectsInvalidSubmissions tests validation of submitted responses
+func TestServerRejectsInvalidSubmissions(t *testing.T) {
+	srv, store := newTestServer(t)
+	for _, tt := range []struct {
+		body string
+		code int
+	}{
+		{`{"user_id": "u", "question_id": 42, "answer": "x"}`, http.StatusUnprocessableEntity},
+		{`{"user_id": "user1", "question_id": 1, "answer": "Paris"}`, http.StatusUnprocessableEntity},
+		{`{"user_id": "u", "question_id": 1, "answer": "x", "skipped": true}`, http.StatusUnprocessableEntity},
+		{`{"user_id": "u", "question_id": 1, "duration_ms": -5}`, http.StatusUnprocessableEntity},
+		{`{"question_id": 1}`, http.StatusUnprocessableEntity},
+		{`not json`, http.StatusBadRequest},
+	} {
+		if code, body := postJSON(t, srv.URL+"/responses", tt.body); code != tt.code {
+			t.Errorf("%s: expected %d, got %d %v", tt.body, tt.code, code, body)
+		}
+	}
+
+	// A batch stops at the first invalid response.
+	code, body := postJSON(t, srv.URL+"/responses", `[{"user_id": "u9", "question_id": 1, "answer": "Paris"}, {"user_id": "u9", "question_id": 7}]`)
+	if code != http.StatusUnprocessableEntity || body["accepted"] != float64(1) {
+		t.Errorf("Expected 1 accepted before the error, got %d %v", code, body)
+	}
+	if stats, _ := store.QuestionStats(1); stats.Responses != 5 {
+		t.Errorf("Expected 5 responses to question 1, got %d", stats.Responses)
+	}
+}
+
+// TestServerConcurrentSubmissions tests concurrent submissions and reads
+func TestServerConcurrentSubmissions(t *testing.T) {
+	srv, store := newTestServer(t)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				body := fmt.Sprintf(`{"user_id": "w%d-%d", "question_id": 2, "answer": "4", "duration_ms": 100}`, w, i)
+				if code, resp := postJSON(t, srv.URL+"/responses", body); code != http.StatusCreated {
+					t.Errorf("Unexpected status %d %v", code, resp)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				var stats []QuestionStats
+				getJSON(t, srv.URL+"/questions/stats", &stats)
+				getJSON(t, srv.URL+"/report", nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats, _ := store.QuestionStats(2)
+	if stats.Correct != 2+8*25 || stats.Responses != 5+8*25 {
+		t.Errorf("Expected %d correct of %d, got %+v", 2+8*25, 5+8*25, stats)
+	}
+	if got := countCorrectAnswersForQuestion(store.quiz, Question{ID: 2, CorrectAnswer: "4"}); got != stats.Correct {
+		t.Errorf("Expected incremental counters to match a full recount, got %d and %d", stats.Correct, got)
+	}
+}
+
+// TestQuizStoreDefaultAttemptIDs tests that responses without an attempt ID
+// skip attempt names held by other users, as buildQuiz does
+func TestQuizStoreDefaultAttemptIDs(t *testing.T) {
+	_, store := newTestServer(t)
+	for _, resp := range []UserResponse{
+		{UserID: "x", AttemptID: "u-1", QuestionID: 1, Answer: "Paris"},
+		{UserID: "u", QuestionID: 1, Answer: "Paris"},
+		{UserID: "u", QuestionID: 2, Answer: "4"},
+	} {
+		if err := store.Submit(resp); err != nil {
+			t.Fatalf("%+v: %v", resp, err)
+		}
+	}
+	report := store.Report()
+	if report.Attempts != 7 {
+		t.Errorf("Expected attempts u-1 (by x) and u-2 (by u) to be added, got %d attempts", report.Attempts)
+	}
+	if i, ok := store.attempts["u-2"]; !ok || len(store.quiz.Attempts[i].Records) != 2 {
+		t.Errorf("Expected both of u's responses in attempt u-2")
+	}
+}
+
+// TestQuizStoreClusterCache tests that cached clusters pick up answers submitted after a read
+func TestQuizStoreClusterCache(t *testing.T) {
+	_, store := newTestServer(t)
+	before := store.TopIncorrectAnswers(0)
+	for i := range 3 {
+		resp := UserResponse{UserID: fmt.Sprintf("new%d", i), QuestionID: 2, Answer: "22"}
+		if err := store.Submit(resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	after := store.TopIncorrectAnswers(0)
+	if len(after) == 0 || after[0].Answer != "22" || after[0].Count != 3 {
+		t.Errorf("Expected 22 with 3 answers on top after submitting, got %+v (before %+v)", after, before)
+	}
+	if again := store.TopIncorrectAnswers(0); fmt.Sprint(again) != fmt.Sprint(after) {
+		t.Errorf("Expected a repeated read to match, got %+v and %+v", after, again)
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// HistogramBucket counts durations in [Lower, Upper). The last bucket of
+// a histogram has no upper bound and reports Upper as 0.
+type HistogramBucket struct {
+	Lower time.Duration `json:"lower_ns"`
+	Upper time.Duration `json:"upper_ns"`
+	Count int           `json:"count"`
+}
+
+// TimeDistribution summarises a set of response times.
+type TimeDistribution struct {
+	Count     int               `json:"count"`
+	Min       time.Duration     `json:"min_ns"`
+	Max       time.Duration     `json:"max_ns"`
+	Mean      time.Duration     `json:"mean_ns"`
+	Median    time.Duration     `json:"median_ns"`
+	P10       time.Duration     `json:"p10_ns"`
+	P25       time.Duration     `json:"p25_ns"`
+	P75       time.Duration     `json:"p75_ns"`
+	P90       time.Duration     `json:"p90_ns"`
+	P95       time.Duration     `json:"p95_ns"`
+	Histogram []HistogramBucket `json:"histogram"`
+}
+
+// defaultBuckets are the histogram boundaries used by the reports.
+var defaultBuckets = []time.Duration{
+	time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second,
+	30 * time.Second, time.Minute, 2 * time.Minute,
+}
+
+// summarizeDurations computes the distribution of ds, bucketed by the
+// ascending boundaries in buckets.
+func summarizeDurations(ds []time.Duration, buckets []time.Duration) TimeDistribution {
+	dist := TimeDistribution{Count: len(ds)}
+	dist.Histogram = make([]HistogramBucket, len(buckets)+1)
+	var lower time.Duration
+	for i, upper := range buckets {
+		dist.Histogram[i] = HistogramBucket{Lower: lower, Upper: upper}
+		lower = upper
+	}
+	dist.Histogram[len(buckets)] = HistogramBucket{Lower: lower}
+	if len(ds) == 0 {
+		return dist
+	}
+
+	sorted := append([]time.Duration(nil), ds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+		i := sort.Search(len(buckets), func(i int) bool { return d < buckets[i] })
+		dist.Histogram[i].Count++
+	}
+	dist.Min, dist.Max = sorted[0], sorted[len(sorted)-1]
+	dist.Mean = total / time.Duration(len(sorted))
+	dist.Median = percentile(sorted, 50)
+	dist.P10 = percentile(sorted, 10)
+	dist.P25 = percentile(sorted, 25)
+	dist.P75 = percentile(sorted, 75)
+	dist.P90 = percentile(sorted, 90)
+	dist.P95 = percentile(sorted, 95)
+	return dist
+}
+
+// percentile returns the p-th percentile of sorted using linear
+// interpolation between closest ranks.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	frac := rank - float64(lo)
+	return sorted[lo] + time.Duration(math.Round(frac*float64(sorted[hi]-sorted[lo])))
+}
+
+// OutlierKind classifies an unusual response time.
+type OutlierKind string
+
+const (
+	Guessing OutlierKind = "guessing"
+	Stalling OutlierKind = "stalling"
+)
+
+// OutlierOptions sets the thresholds for flagging response times. A
+// response is a guess when it is faster than GuessFraction of the
+// question's median time, and a stall when it is slower than the upper
+// quartile plus StallIQR interquartile ranges. The stall margin above the
+// upper quartile is never less than StallMinMargin, so a question where
+// most answers take the same time (an IQR of zero) does not flag every
+// slightly slower answer.
+type OutlierOptions struct {
+	GuessFraction  float64
+	StallIQR       float64
+	StallMinMargin time.Duration
+}
+
+// DefaultOutlierOptions flags answers faster than 30% of the median and
+// slower than Tukey's far-out fence, and at least 30 seconds slower than
+// the upper quartile.
+func DefaultOutlierOptions() OutlierOptions {
+	return OutlierOptions{GuessFraction: 0.3, StallIQR: 3, StallMinMargin: 30 * time.Second}
+}
+
+// TimingOutlier is a single response with an unusual duration.
+type TimingOutlier struct {
+	AttemptID    string        `json:"attempt_id"`
+	RespondentID string        `json:"respondent_id"`
+	QuestionID   int           `json:"question_id"`
+	Duration     time.Duration `json:"duration_ns"`
+	Kind         OutlierKind   `json:"kind"`
+	Correct      bool          `json:"correct"`
+}
+
+// QuestionTiming is the response-time analysis of one question.
+type QuestionTiming struct {
+	QuestionID      int              `json:"question_id"`
+	Distribution    TimeDistribution `json:"distribution"`
+	MeanCorrect     time.Duration    `json:"mean_correct_ns"`
+	MeanIncorrect   time.Duration    `json:"mean_incorrect_ns"`
+	TimeCorrectness float64          `json:"time_correctness"` // correlation of log time with correctness
+}
+
+// RespondentTiming is the response-time analysis of one respondent.
+type RespondentTiming struct {
+	RespondentID string           `json:"respondent_id"`
+	Distribution TimeDistribution `json:"distribution"`
+	Guesses      int              `json:"guesses"`
+	Stalls       int              `json:"stalls"`
+	GuessRate    float64          `json:"guess_rate"` // share of answers flagged as guesses
+}
+
+// TimingAnalysis is the full response-time analysis of a quiz.
+type TimingAnalysis struct {
+	Overall         TimeDistribution   `json:"overall"`
+	TimeCorrectness float64            `json:"time_correctness"`
+	Questions       []QuestionTiming   `json:"questions"`   // in question order
+	Respondents     []RespondentTiming `json:"respondents"` // highest guess rate first
+	Outliers        []TimingOutlier    `json:"outliers"`    // in attempt order
+}
+
+// timedResponse is an answered record with its context.
+type timedResponse struct {
+	attempt    Attempt
+	questionID int
+	duration   time.Duration
+	correct    bool
+}
+
+// analyzeTiming computes response-time distributions per question and
+// per respondent, flags guessing and stalling, and correlates time with
+// correctness. Skipped records are ignored.
+func analyzeTiming(quiz *Quiz, opts OutlierOptions) TimingAnalysis {
+	var analysis TimingAnalysis
+
+	byQuestion := make(map[int][]timedResponse)
+	var all []timedResponse
+	for _, a := range quiz.Attempts {
+		for _, r := range a.Records {
+			if r.Skipped {
+				continue
+			}
+			question, _ := quiz.Question(r.QuestionID)
+			tr := timedResponse{attempt: a, questionID: r.QuestionID, duration: r.Duration, correct: quiz.isCorrect(question, r)}
+			byQuestion[r.QuestionID] = append(byQuestion[r.QuestionID], tr)
+			all = append(all, tr)
+		}
+	}
+	analysis.Overall = summarizeDurations(durationsOf(all), defaultBuckets)
+	analysis.TimeCorrectness = timeCorrectness(all)
+
+	// Outlier thresholds are relative to each question's own distribution.
+	type fences struct{ guess, stall time.Duration }
+	questionFences := make(map[int]fences)
+	for _, question := range quiz.Questions {
+		responses := byQuestion[question.ID]
+		dist := summarizeDurations(durationsOf(responses), defaultBuckets)
+		qt := QuestionTiming{QuestionID: question.ID, Distribution: dist, TimeCorrectness: timeCorrectness(responses)}
+		qt.MeanCorrect, qt.MeanIncorrect = meanByCorrectness(responses)
+		analysis.Questions = append(analysis.Questions, qt)
+
+		iqr := dist.P75 - dist.P25
+		questionFences[question.ID] = fences{
+			guess: time.Duration(opts.GuessFraction * float64(dist.Median)),
+			stall: dist.P75 + max(time.Duration(opts.StallIQR*float64(iqr)), opts.StallMinMargin),
+		}
+	}
+
+	perRespondent := make(map[string]*RespondentTiming)
+	durations := make(map[string][]time.Duration)
+	var respondentOrder []string
+	for _, tr := range all {
+		id := tr.attempt.RespondentID
+		rt, ok := perRespondent[id]
+		if !ok {
+			rt = &RespondentTiming{RespondentID: id}
+			perRespondent[id] = rt
+			respondentOrder = append(respondentOrder, id)
+		}
+		durations[id] = append(durations[id], tr.duration)
+
+		f := questionFences[tr.questionID]
+		var kind OutlierKind
+		switch {
+		case len(byQuestion[tr.questionID]) < 3:
+			// Too few responses to say what is unusual.
+		case tr.duration < f.guess:
+			kind = Guessing
+			rt.Guesses++
+		case tr.duration > f.stall && f.stall > 0:
+			kind = Stalling
+			rt.Stalls++
+		}
+		if kind != "" {
+			analysis.Outliers = append(analysis.Outliers, TimingOutlier{
+				AttemptID:    tr.attempt.ID,
+				RespondentID: id,
+				QuestionID:   tr.questionID,
+				Duration:     tr.duration,
+				Kind:         kind,
+				Correct:      tr.correct,
+			})
+		}
+	}
+	for _, id := range respondentOrder {
+		rt := perRespondent[id]
+		rt.Distribution = summarizeDurations(durations[id], defaultBuckets)
+		rt.GuessRate = float64(rt.Guesses) / float64(rt.Distribution.Count)
+		analysis.Respondents = append(analysis.Respondents, *rt)
+	}
+	sort.SliceStable(analysis.Respondents, func(i, j int) bool {
+		return analysis.Respondents[i].GuessRate > analysis.Respondents[j].GuessRate
+	})
+	return analysis
+}
+
+func durationsOf(responses []timedResponse) []time.Duration {
+	ds := make([]time.Duration, len(responses))
+	for i, tr := range responses {
+		ds[i] = tr.duration
+	}
+	return ds
+}
+
+// meanByCorrectness returns the mean time of correct and incorrect responses.
+func meanByCorrectness(responses []timedResponse) (correct, incorrect time.Duration) {
+	var sumC, sumI time.Duration
+	var nC, nI int
+	for _, tr := range responses {
+		if tr.correct {
+			sumC += tr.duration
+			nC++
+		} else {
+			sumI += tr.duration
+			nI++
+		}
+	}
+	if nC > 0 {
+		correct = sumC / time.Duration(nC)
+	}
+	if nI > 0 {
+		incorrect = sumI / time.Duration(nI)
+	}
+	return correct, incorrect
+}
+
+// timeCorrectness correlates log response time with correctness. Times
+// are log-scaled because they are heavily right-skewed; a positive value
+// means slower answers tend to be correct.
+func timeCorrectness(responses []timedResponse) float64 {
+	times := make([]float64, len(responses))
+	correct := make([]float64, len(responses))
+	for i, tr := range responses {
+		times[i] = math.Log1p(tr.duration.Seconds())
+		if tr.correct {
+			correct[i] = 1
+		}
+	}
+	return correlation(times, correct)
+}
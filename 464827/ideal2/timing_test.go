@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestSummarizeDurations tests percentiles and histogram buckets
+func TestSummarizeDurations(t *testing.T) {
+	var ds []time.Duration
+	for i := 10; i >= 1; i-- {
+		ds = append(ds, time.Duration(i)*time.Second)
+	}
+	dist := summarizeDurations(ds, []time.Duration{3 * time.Second, 6 * time.Second})
+
+	if dist.Count != 10 || dist.Min != time.Second || dist.Max != 10*time.Second {
+		t.Errorf("Unexpected bounds %+v", dist)
+	}
+	if dist.Median != 5500*time.Millisecond || dist.Mean != 5500*time.Millisecond {
+		t.Errorf("Expected median and mean 5.5s, got %v and %v", dist.Median, dist.Mean)
+	}
+	if dist.P10 != 1900*time.Millisecond || dist.P90 != 9100*time.Millisecond {
+		t.Errorf("Expected p10 1.9s and p90 9.1s, got %v and %v", dist.P10, dist.P90)
+	}
+	// [0,3s): 1,2  [3s,6s): 3,4,5  [6s,∞): 6..10
+	for i, want := range []int{2, 3, 5} {
+		if dist.Histogram[i].Count != want {
+			t.Errorf("Expected bucket %d to hold %d, got %d", i, want, dist.Histogram[i].Count)
+		}
+	}
+	if last := dist.Histogram[2]; last.Lower != 6*time.Second || last.Upper != 0 {
+		t.Errorf("Unexpected open bucket %+v", last)
+	}
+
+	empty := summarizeDurations(nil, defaultBuckets)
+	if empty.Count != 0 || len(empty.Histogram) != len(defaultBuckets)+1 {
+		t.Errorf("Unexpected empty distribution %+v", empty)
+	}
+}
+
+// TestAnalyzeTimingOutliers tests that fast guessers and stallers are flagged
+func TestAnalyzeTimingOutliers(t *testing.T) {
+	var responses []UserResponse
+	for u := 1; u <= 8; u++ {
+		for q := 1; q <= 2; q++ {
+			resp := UserResponse{UserID: fmt.Sprintf("u%d", u), QuestionID: q, Answer: "right", DurationMS: int64(9000 + 250*u)}
+			switch u {
+			case 7: // answers everything instantly and wrongly
+				resp.Answer, resp.DurationMS = "wrong", 0
+			case 8: // sits on question 2 for ten minutes
+				if q == 2 {
+					resp.DurationMS = 600_000
+				}
+			}
+			responses = append(responses, resp)
+		}
+	}
+	quiz, err := buildQuiz([]Question{{ID: 1, CorrectAnswer: "right"}, {ID: 2, CorrectAnswer: "right"}}, responses)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	analysis := analyzeTiming(quiz, DefaultOutlierOptions())
+	if len(analysis.Outliers) != 3 {
+		t.Fatalf("Expected 3 outliers, got %+v", analysis.Outliers)
+	}
+	for _, o := range analysis.Outliers {
+		switch {
+		case o.RespondentID == "u7" && o.Kind == Guessing && !o.Correct:
+		case o.RespondentID == "u8" && o.Kind == Stalling && o.QuestionID == 2:
+		default:
+			t.Errorf("Unexpected outlier %+v", o)
+		}
+	}
+	if top := analysis.Respondents[0]; top.RespondentID != "u7" || top.GuessRate != 1 {
+		t.Errorf("Expected u7 to lead with a guess rate of 1, got %+v", top)
+	}
+	if analysis.TimeCorrectness <= 0 {
+		t.Errorf("Expected slower answers to correlate with correctness, got %.2f", analysis.TimeCorrectness)
+	}
+	if q := analysis.Questions[0]; q.MeanIncorrect != 0 || q.MeanCorrect <= 0 {
+		t.Errorf("Unexpected per-question means %+v", q)
+	}
+}
+
+// TestStallFenceWithZeroIQR tests that identical times do not make every slower answer a stall
+func TestStallFenceWithZeroIQR(t *testing.T) {
+	var responses []UserResponse
+	for u := 1; u <= 12; u++ {
+		ms := int64(10_000)
+		switch u {
+		case 11:
+			ms = 12_000 // slightly slower than everyone else
+		case 12:
+			ms = 120_000 // two minutes on a ten-second question
+		}
+		responses = append(responses, UserResponse{UserID: fmt.Sprintf("u%d", u), QuestionID: 1, Answer: "a", DurationMS: ms})
+	}
+	quiz, err := buildQuiz([]Question{{ID: 1, CorrectAnswer: "a"}}, responses)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	analysis := analyzeTiming(quiz, DefaultOutlierOptions())
+	if q := analysis.Questions[0].Distribution; q.P75 != q.P25 {
+		t.Fatalf("Expected an IQR of zero, got P25 %v and P75 %v", q.P25, q.P75)
+	}
+	if len(analysis.Outliers) != 1 || analysis.Outliers[0].RespondentID != "u12" || analysis.Outliers[0].Kind != Stalling {
+		t.Errorf("Expected only u12 to be flagged as stalling, got %+v", analysis.Outliers)
+	}
+}
+
+// TestAverageResponseTimeCountsZero tests that instant answers are no longer treated as missing
+func TestAverageResponseTimeCountsZero(t *testing.T) {
+	quiz, _ := buildQuiz([]Question{{ID: 1, CorrectAnswer: "a"}}, []UserResponse{
+		{UserID: "u1", QuestionID: 1, Answer: "a", DurationMS: 4000},
+		{UserID: "u2", QuestionID: 1, Answer: "b", DurationMS: 0},
+		{UserID: "u3", QuestionID: 1, Skipped: true},
+	})
+	if got := calculateAverageResponseTime(quiz); got != 2*time.Second {
+		t.Errorf("Expected average 2s, got %v", got)
+	}
+}
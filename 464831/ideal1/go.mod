@@ -0,0 +1,3 @@
+module example.com/464831/ideal1
+
+go 1.23.4
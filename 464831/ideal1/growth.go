@@ -0,0 +1,135 @@
+package main
+
+import "fmt"
+
+// Element types of different sizes, so the cost of copying on reallocation
+// can be compared at 8, 64 and 256 bytes per element.
+type (
+	elem8   [1]int64
+	elem64  [8]int64
+	elem256 [32]int64
+)
+
+// elementSizes lists the element sizes, in bytes, that strategies can be
+// instantiated for.
+var elementSizes = []int{8, 64, 256}
+
+// Strategy is one way of building a slice of n elements. Grow returns the
+// number of times the backing array had to be reallocated.
+type Strategy struct {
+	Name string
+	Grow func(n int) int
+}
+
+// sinkLen keeps the compiler from discarding the slices built by strategies.
+var sinkLen int
+
+// dynamicGrowth appends to a nil slice and lets append pick the capacity.
+func dynamicGrowth[T any](n int) ([]T, int) {
+	var slice []T
+	var v T
+	reallocs := 0
+	for i := 0; i < n; i++ {
+		before := cap(slice)
+		slice = append(slice, v)
+		if cap(slice) != before {
+			reallocs++
+		}
+	}
+	return slice, reallocs
+}
+
+// preallocate reserves the full capacity up front.
+func preallocate[T any](n int) ([]T, int) {
+	slice := make([]T, 0, n)
+	var v T
+	for i := 0; i < n; i++ {
+		slice = append(slice, v)
+	}
+	return slice, 1
+}
+
+// fixedSizeIncrements grows the capacity by a constant number of elements
+// whenever the slice is full, copying the existing contents across.
+func fixedSizeIncrements[T any](n, increment int) ([]T, int) {
+	var slice []T
+	var v T
+	reallocs := 0
+	for i := 0; i < n; i++ {
+		if len(slice) == cap(slice) {
+			grown := make([]T, len(slice), cap(slice)+increment)
+			copy(grown, slice)
+			slice = grown
+			reallocs++
+		}
+		slice = append(slice, v)
+	}
+	return slice, reallocs
+}
+
+// vecGrowth pushes n elements into a Vec that grows according to policy.
+func vecGrowth[T any](n int, policy GrowthPolicy) ([]T, int) {
+	v := NewVec[T](policy, 0)
+	var x T
+	for i := 0; i < n; i++ {
+		v.Push(x)
+	}
+	return v.Items(), v.Reallocs()
+}
+
+// pooledGrowth fills a buffer taken from pool and returns it afterwards, so
+// steady-state runs reuse the same backing array.
+func pooledGrowth[T any](n int, pool *SlabPool[T]) int {
+	misses := pool.Stats().Misses
+	slice := pool.Get(n)
+	var v T
+	for i := 0; i < n; i++ {
+		slice = append(slice, v)
+	}
+	sinkLen += len(slice)
+	pool.Put(slice)
+	return int(pool.Stats().Misses - misses)
+}
+
+// strategiesOf instantiates every strategy for element type T.
+func strategiesOf[T any](increment int) []Strategy {
+	run := func(grow func(int) ([]T, int)) func(int) int {
+		return func(n int) int {
+			slice, reallocs := grow(n)
+			sinkLen += len(slice)
+			return reallocs
+		}
+	}
+	pool := NewSlabPool[T](4)
+	vec := func(policy GrowthPolicy) func(int) int {
+		return run(func(n int) ([]T, int) { return vecGrowth[T](n, policy) })
+	}
+	return []Strategy{
+		{Name: "dynamicGrowth", Grow: run(dynamicGrowth[T])},
+		{Name: "preallocate", Grow: run(preallocate[T])},
+		{Name: fmt.Sprintf("fixedSizeIncrements(%d)", increment), Grow: run(func(n int) ([]T, int) {
+			return fixedSizeIncrements[T](n, increment)
+		})},
+		{Name: "Vec(doubling)", Grow: vec(Doubling{MinCap: 8})},
+		{Name: "Vec(1.25x)", Grow: vec(Factor(1.25))},
+		{Name: fmt.Sprintf("Vec(fixed %d)", increment), Grow: vec(FixedIncrement(increment))},
+		{Name: "Vec(fibonacci)", Grow: vec(Fibonacci{})},
+		{Name: "slabPool", Grow: func(n int) int { return pooledGrowth(n, pool) }},
+	}
+}
+
+// strategiesFor returns the strategies for elements of elemSize bytes.
+func strategiesFor(elemSize, increment int) ([]Strategy, error) {
+	if increment <= 0 {
+		return nil, fmt.Errorf("increment must be positive, got %d", increment)
+	}
+	switch elemSize {
+	case 8:
+		return strategiesOf[elem8](increment), nil
+	case 64:
+		return strategiesOf[elem64](increment), nil
+	case 256:
+		return strategiesOf[elem256](increment), nil
+	}
+	return nil, fmt.Errorf("unsupported element size %d (want one of %v)", elemSize, elementSizes)
+}
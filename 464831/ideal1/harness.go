@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"text/tabwriter"
+	"time"
+)
+
+// ExperimentConfig describes a grid of benchmark scenarios: every strategy is
+// run for every combination of element count and element size.
+type ExperimentConfig struct {
+	Counts    []int
+	Sizes     []int
+	Increment int           // step used by fixedSizeIncrements
+	Runs      int           // independent measurements per scenario
+	MinTime   time.Duration // minimum wall time of a single run
+}
+
+// Sample is one measurement of a strategy, averaged over its iterations.
+type Sample struct {
+	NsPerOp     float64
+	AllocsPerOp float64
+	BytesPerOp  float64
+}
+
+// Summary is the mean of a set of samples with the half-width of its 95%
+// confidence interval.
+type Summary struct {
+	Mean float64
+	CI   float64
+	N    int
+	sd   float64
+}
+
+// Result holds every run of one strategy in one scenario.
+type Result struct {
+	Strategy string
+	Count    int
+	ElemSize int
+	Reallocs int
+	Baseline bool
+	Samples  []Sample
+	Time     Summary
+	Allocs   Summary
+	Bytes    Summary
+	// Delta is the relative change in time against the baseline strategy of
+	// the same scenario; Significant reports whether it passes Welch's t-test.
+	Delta       float64
+	Significant bool
+}
+
+// measure runs grow repeatedly for at least minTime and returns the
+// per-operation cost, together with the reallocation count of one call.
+func measure(grow func(int) int, n int, minTime time.Duration) (Sample, int) {
+	reallocs := grow(n)
+	start := time.Now()
+	grow(n)
+	single := time.Since(start)
+	iters := 1
+	if single > 0 && single < minTime {
+		iters = int(minTime / single)
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start = time.Now()
+	for i := 0; i < iters; i++ {
+		grow(n)
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	ops := float64(iters)
+	return Sample{
+		NsPerOp:     float64(elapsed.Nanoseconds()) / ops,
+		AllocsPerOp: float64(after.Mallocs-before.Mallocs) / ops,
+		BytesPerOp:  float64(after.TotalAlloc-before.TotalAlloc) / ops,
+	}, reallocs
+}
+
+// runExperiment measures every strategy in every scenario of cfg. The first
+// strategy of each scenario is the baseline the others are compared with.
+func runExperiment(cfg ExperimentConfig) ([]Result, error) {
+	if cfg.Runs < 2 {
+		return nil, fmt.Errorf("need at least 2 runs for a confidence interval, got %d", cfg.Runs)
+	}
+	var results []Result
+	for _, size := range cfg.Sizes {
+		strategies, err := strategiesFor(size, cfg.Increment)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range cfg.Counts {
+			if n <= 0 {
+				return nil, fmt.Errorf("element count must be positive, got %d", n)
+			}
+			scenario := make([]Result, len(strategies))
+			for i, s := range strategies {
+				scenario[i] = Result{Strategy: s.Name, Count: n, ElemSize: size, Baseline: i == 0}
+			}
+			// Interleave strategies run by run so drift in machine load
+			// affects all of them alike.
+			for run := 0; run < cfg.Runs; run++ {
+				for i, s := range strategies {
+					sample, reallocs := measure(s.Grow, n, cfg.MinTime)
+					scenario[i].Samples = append(scenario[i].Samples, sample)
+					scenario[i].Reallocs = reallocs
+				}
+			}
+			for i := range scenario {
+				summarizeResult(&scenario[i])
+				if i > 0 {
+					compare(&scenario[i], scenario[0])
+				}
+			}
+			results = append(results, scenario...)
+		}
+	}
+	return results, nil
+}
+
+func summarizeResult(r *Result) {
+	times := make([]float64, len(r.Samples))
+	allocs := make([]float64, len(r.Samples))
+	bytes := make([]float64, len(r.Samples))
+	for i, s := range r.Samples {
+		times[i], allocs[i], bytes[i] = s.NsPerOp, s.AllocsPerOp, s.BytesPerOp
+	}
+	r.Time = summarize(times)
+	r.Allocs = summarize(allocs)
+	r.Bytes = summarize(bytes)
+}
+
+// summarize returns the mean and 95% confidence interval of xs using the
+// Student t distribution.
+func summarize(xs []float64) Summary {
+	n := len(xs)
+	if n == 0 {
+		return Summary{}
+	}
+	mean := 0.0
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(n)
+	if n == 1 {
+		return Summary{Mean: mean, N: 1}
+	}
+	ss := 0.0
+	for _, x := range xs {
+		ss += (x - mean) * (x - mean)
+	}
+	sd := math.Sqrt(ss / float64(n-1))
+	return Summary{
+		Mean: mean,
+		CI:   tCritical(float64(n-1)) * sd / math.Sqrt(float64(n)),
+		N:    n,
+		sd:   sd,
+	}
+}
+
+// compare fills in r's time delta against base using Welch's t-test.
+func compare(r *Result, base Result) {
+	if base.Time.Mean == 0 {
+		return
+	}
+	r.Delta = (r.Time.Mean - base.Time.Mean) / base.Time.Mean
+	va := r.Time.sd * r.Time.sd / float64(r.Time.N)
+	vb := base.Time.sd * base.Time.sd / float64(base.Time.N)
+	if va+vb == 0 {
+		r.Significant = r.Time.Mean != base.Time.Mean
+		return
+	}
+	t := (r.Time.Mean - base.Time.Mean) / math.Sqrt(va+vb)
+	df := (va + vb) * (va + vb) /
+		(va*va/float64(r.Time.N-1) + vb*vb/float64(base.Time.N-1))
+	r.Significant = math.Abs(t) > tCritical(df)
+}
+
+// tTable holds two-sided 95% critical values of the t distribution for 1 to
+// 30 degrees of freedom.
+var tTable = [...]float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// tCritical returns the 95% critical value for df degrees of freedom,
+// rounding df down so the interval errs on the wide side.
+func tCritical(df float64) float64 {
+	d := int(df)
+	switch {
+	case d < 1:
+		return tTable[0]
+	case d <= len(tTable):
+		return tTable[d-1]
+	case d <= 60:
+		return 2.000
+	case d <= 120:
+		return 1.980
+	}
+	return 1.960
+}
+
+// writeResults prints results as a benchstat-style table.
+func writeResults(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "size\tn\tstrategy\ttime/op\tallocs/op\tB/op\treallocs\tvs base")
+	for _, r := range results {
+		delta := "~"
+		switch {
+		case r.Baseline:
+			delta = "base"
+		case r.Significant:
+			delta = fmt.Sprintf("%+.2f%% (p<0.05)", r.Delta*100)
+		}
+		fmt.Fprintf(tw, "%dB\t%d\t%s\t%s ± %s\t%.0f\t%s\t%d\t%s\n",
+			r.ElemSize, r.Count, r.Strategy,
+			time.Duration(r.Time.Mean).Round(time.Microsecond/10), percent(r.Time),
+			r.Allocs.Mean, formatBytes(r.Bytes.Mean), r.Reallocs, delta)
+	}
+	return tw.Flush()
+}
+
+func percent(s Summary) string {
+	if s.Mean == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.0f%%", 100*s.CI/s.Mean)
+}
+
+func formatBytes(b float64) string {
+	switch {
+	case b >= 1<<30:
+		return fmt.Sprintf("%.2fGiB", b/(1<<30))
+	case b >= 1<<20:
+		return fmt.Sprintf("%.2fMiB", b/(1<<20))
+	case b >= 1<<10:
+		return fmt.Sprintf("%.2fKiB", b/(1<<10))
+	}
+	return fmt.Sprintf("%.0fB", b)
+}
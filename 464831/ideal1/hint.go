@@ -0,0 +1,303 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// HintEstimator selects how CapacityHints turns past lengths into a hint.
+type HintEstimator int
+
+const (
+	// PercentileEstimator uses a percentile of the most recent lengths.
+	PercentileEstimator HintEstimator = iota
+	// EWMAEstimator uses an exponentially weighted moving average of the
+	// lengths, scaled by a headroom factor.
+	EWMAEstimator
+)
+
+func (e HintEstimator) String() string {
+	if e == EWMAEstimator {
+		return "ewma"
+	}
+	return "percentile"
+}
+
+// HintOptions configures CapacityHints.
+type HintOptions struct {
+	Estimator  HintEstimator
+	Percentile float64 // in (0, 1], for PercentileEstimator
+	Window     int     // recent lengths kept per site
+	Alpha      float64 // EWMA smoothing factor in (0, 1]
+	Headroom   float64 // multiplier applied to the EWMA
+	Default    int     // hint for sites with no history
+}
+
+// DefaultHintOptions returns a p95 estimator over the last 256 lengths.
+func DefaultHintOptions() HintOptions {
+	return HintOptions{
+		Estimator:  PercentileEstimator,
+		Percentile: 0.95,
+		Window:     256,
+		Alpha:      0.2,
+		Headroom:   1.2,
+	}
+}
+
+// SiteStats reports how well the hints for one site fit the lengths seen.
+type SiteStats struct {
+	Site         string
+	Observations int
+	Hint         int   // current hint
+	Reallocated  int   // observations that outgrew the hint they were given
+	OverAlloc    int64 // unused capacity summed over observations
+	Used         int64 // lengths summed over observations
+}
+
+// WasteRatio returns unused capacity as a fraction of the used length.
+func (s SiteStats) WasteRatio() float64 {
+	if s.Used == 0 {
+		return 0
+	}
+	return float64(s.OverAlloc) / float64(s.Used)
+}
+
+type siteHistory struct {
+	recent   []int // ring buffer of the last Window lengths
+	next     int
+	ewma     float64
+	lastHint int
+	stats    SiteStats
+}
+
+// CapacityHints learns a good initial capacity per call site from the final
+// lengths observed there. Sites are arbitrary names chosen by the caller. A
+// CapacityHints is safe for concurrent use.
+type CapacityHints struct {
+	mu    sync.Mutex
+	opts  HintOptions
+	sites map[string]*siteHistory
+}
+
+// NewCapacityHints returns an estimator with the given options.
+func NewCapacityHints(opts HintOptions) (*CapacityHints, error) {
+	if opts.Window <= 0 {
+		return nil, fmt.Errorf("window must be positive, got %d", opts.Window)
+	}
+	if opts.Default < 0 {
+		return nil, fmt.Errorf("default hint must not be negative, got %d", opts.Default)
+	}
+	switch opts.Estimator {
+	case PercentileEstimator:
+		if !(opts.Percentile > 0 && opts.Percentile <= 1) {
+			return nil, fmt.Errorf("percentile must be in (0, 1], got %v", opts.Percentile)
+		}
+	case EWMAEstimator:
+		if !(opts.Alpha > 0 && opts.Alpha <= 1) || opts.Headroom <= 0 {
+			return nil, fmt.Errorf("invalid EWMA parameters alpha=%v headroom=%v", opts.Alpha, opts.Headroom)
+		}
+	default:
+		return nil, fmt.Errorf("unknown estimator %d", opts.Estimator)
+	}
+	return &CapacityHints{opts: opts, sites: make(map[string]*siteHistory)}, nil
+}
+
+// DefaultHints is the estimator used by MakeWithHint and Observe.
+var DefaultHints, _ = NewCapacityHints(DefaultHintOptions())
+
+// MakeWithHint returns an empty slice with the capacity DefaultHints
+// suggests for site. Pass the filled slice to Observe when done.
+func MakeWithHint[T any](site string) []T {
+	return make([]T, 0, DefaultHints.Hint(site))
+}
+
+// Observe records the final length of a slice created by MakeWithHint.
+func Observe[T any](site string, s []T) {
+	DefaultHints.Observe(site, len(s), cap(s))
+}
+
+// Hint returns the capacity to allocate for site.
+func (h *CapacityHints) Hint(site string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sh := h.site(site)
+	sh.lastHint = h.estimate(sh)
+	return sh.lastHint
+}
+
+// Observe records that a slice at site ended with the given length and
+// capacity. The observation is compared with the hint most recently handed
+// out for site, so with concurrent users of one site the reallocation count
+// is approximate.
+func (h *CapacityHints) Observe(site string, length, capacity int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sh := h.site(site)
+	if len(sh.recent) < h.opts.Window {
+		sh.recent = append(sh.recent, length)
+	} else {
+		sh.recent[sh.next] = length
+		sh.next = (sh.next + 1) % h.opts.Window
+	}
+	if sh.stats.Observations == 0 {
+		sh.ewma = float64(length)
+	} else {
+		sh.ewma += h.opts.Alpha * (float64(length) - sh.ewma)
+	}
+	sh.stats.Observations++
+	sh.stats.Used += int64(length)
+	sh.stats.OverAlloc += int64(max(capacity-length, 0))
+	if length > sh.lastHint {
+		sh.stats.Reallocated++
+	}
+}
+
+// Stats returns the statistics of every site, sorted by name.
+func (h *CapacityHints) Stats() []SiteStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]SiteStats, 0, len(h.sites))
+	for name, sh := range h.sites {
+		s := sh.stats
+		s.Site = name
+		s.Hint = h.estimate(sh)
+		out = append(out, s)
+	}
+	slices.SortFunc(out, func(a, b SiteStats) int { return cmp.Compare(a.Site, b.Site) })
+	return out
+}
+
+func (h *CapacityHints) site(name string) *siteHistory {
+	sh, ok := h.sites[name]
+	if !ok {
+		sh = &siteHistory{lastHint: h.opts.Default}
+		h.sites[name] = sh
+	}
+	return sh
+}
+
+func (h *CapacityHints) estimate(sh *siteHistory) int {
+	if len(sh.recent) == 0 {
+		return h.opts.Default
+	}
+	// Observed lengths are not validated, so clamp the estimate to a
+	// capacity make accepts.
+	if h.opts.Estimator == EWMAEstimator {
+		return max(int(math.Ceil(sh.ewma*h.opts.Headroom)), 0)
+	}
+	sorted := slices.Clone(sh.recent)
+	slices.Sort(sorted)
+	// Nearest-rank percentile.
+	rank := int(math.Ceil(h.opts.Percentile*float64(len(sorted)))) - 1
+	return max(sorted[max(rank, 0)], 0)
+}
+
+// HintComparison is the outcome of one capacity policy over a workload.
+type HintComparison struct {
+	Policy      string
+	Runs        int
+	Reallocs    int   // backing arrays replaced during append
+	Reallocated int   // runs that needed at least one reallocation
+	OverAlloc   int64 // unused capacity left at the end of each run, summed
+	Used        int64
+	Elapsed     time.Duration
+}
+
+// WasteRatio returns unused capacity as a fraction of the used length.
+func (c HintComparison) WasteRatio() float64 {
+	if c.Used == 0 {
+		return 0
+	}
+	return float64(c.OverAlloc) / float64(c.Used)
+}
+
+// hintWorkload returns n slice lengths drawn from a log-normal distribution
+// with the given median, modelling a call site whose output size varies.
+func hintWorkload(n, median int, sigma float64, seed int64) []int {
+	rng := rand.New(rand.NewSource(seed))
+	lengths := make([]int, n)
+	for i := range lengths {
+		lengths[i] = max(int(float64(median)*math.Exp(sigma*rng.NormFloat64())), 1)
+	}
+	return lengths
+}
+
+// runHintPolicy fills one int slice per length, starting from the capacity
+// returned by hint and reporting each final length to observe.
+func runHintPolicy(name string, lengths []int, hint func() int, observe func(length, capacity int)) HintComparison {
+	c := HintComparison{Policy: name, Runs: len(lengths)}
+	start := time.Now()
+	for _, n := range lengths {
+		s := make([]int, 0, hint())
+		reallocs := 0
+		for i := 0; i < n; i++ {
+			before := cap(s)
+			s = append(s, i)
+			if cap(s) != before {
+				reallocs++
+			}
+		}
+		if reallocs > 0 {
+			c.Reallocated++
+		}
+		c.Reallocs += reallocs
+		c.OverAlloc += int64(cap(s) - len(s))
+		c.Used += int64(len(s))
+		sinkLen += len(s)
+		if observe != nil {
+			observe(len(s), cap(s))
+		}
+	}
+	c.Elapsed = time.Since(start)
+	return c
+}
+
+// compareHints runs the workload with no hint, with a fixed capacity and
+// with each learned estimator.
+func compareHints(lengths []int, fixed int, estimators ...HintOptions) ([]HintComparison, error) {
+	results := []HintComparison{
+		runHintPolicy("none", lengths, func() int { return 0 }, nil),
+		runHintPolicy(fmt.Sprintf("fixed %d", fixed), lengths, func() int { return fixed }, nil),
+	}
+	for _, opts := range estimators {
+		h, err := NewCapacityHints(opts)
+		if err != nil {
+			return nil, err
+		}
+		const site = "workload"
+		results = append(results, runHintPolicy("learned "+opts.Estimator.String(), lengths,
+			func() int { return h.Hint(site) },
+			func(length, capacity int) { h.Observe(site, length, capacity) }))
+	}
+	return results, nil
+}
+
+// writeHintComparison prints the comparison as a table.
+func writeHintComparison(w io.Writer, results []HintComparison) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "policy\truns\treallocs\truns reallocated\tunused\twaste\ttime")
+	for _, c := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%.1f%%\t%v\n",
+			c.Policy, c.Runs, c.Reallocs, c.Reallocated,
+			formatBytes(float64(c.OverAlloc*8)), 100*c.WasteRatio(), c.Elapsed.Round(time.Microsecond))
+	}
+	return tw.Flush()
+}
+
+// writeSiteStats prints per-site hint statistics.
+func writeSiteStats(w io.Writer, stats []SiteStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "site\tobservations\thint\treallocated\tunused elements\twaste")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.1f%%\n",
+			s.Site, s.Observations, s.Hint, s.Reallocated, s.OverAlloc, 100*s.WasteRatio())
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestPercentileHint tests the nearest-rank percentile over the window.
+func TestPercentileHint(t *testing.T) {
+	h, err := NewCapacityHints(HintOptions{Estimator: PercentileEstimator, Percentile: 0.9, Window: 10, Default: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Hint("a"); got != 7 {
+		t.Errorf("hint without history = %d, want default 7", got)
+	}
+	for i := 1; i <= 10; i++ {
+		h.Observe("a", i*10, i*10)
+	}
+	if got := h.Hint("a"); got != 90 {
+		t.Errorf("p90 of 10..100 = %d, want 90", got)
+	}
+	// The window drops the oldest lengths.
+	for i := 0; i < 10; i++ {
+		h.Observe("a", 5, 90)
+	}
+	if got := h.Hint("a"); got != 5 {
+		t.Errorf("hint after window turnover = %d, want 5", got)
+	}
+	if got := h.Hint("b"); got != 7 {
+		t.Errorf("sites are not independent: %d", got)
+	}
+}
+
+// TestEWMAHint tests smoothing and headroom.
+func TestEWMAHint(t *testing.T) {
+	h, err := NewCapacityHints(HintOptions{Estimator: EWMAEstimator, Window: 4, Alpha: 0.5, Headroom: 1.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Observe("s", 100, 100)
+	h.Observe("s", 200, 200)
+	// ewma = 150, hint = ceil(150 * 1.5)
+	if got := h.Hint("s"); got != 225 {
+		t.Errorf("hint = %d, want 225", got)
+	}
+}
+
+// TestHintStats tests reallocation and over-allocation accounting.
+func TestHintStats(t *testing.T) {
+	h, _ := NewCapacityHints(HintOptions{Estimator: PercentileEstimator, Percentile: 1, Window: 8, Default: 10})
+	h.Hint("s")
+	h.Observe("s", 25, 32) // outgrew 10
+	h.Hint("s")            // now 25
+	h.Observe("s", 20, 25)
+	stats := h.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("got %d sites", len(stats))
+	}
+	want := SiteStats{Site: "s", Observations: 2, Hint: 25, Reallocated: 1, OverAlloc: 12, Used: 45}
+	if stats[0] != want {
+		t.Errorf("stats = %+v, want %+v", stats[0], want)
+	}
+	var sb strings.Builder
+	if err := writeSiteStats(&sb, stats); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "26.7%") {
+		t.Errorf("unexpected output:\n%s", sb.String())
+	}
+}
+
+// TestMakeWithHint tests the package-level helpers.
+func TestMakeWithHint(t *testing.T) {
+	const site = "TestMakeWithHint"
+	for i := 0; i < 5; i++ {
+		s := MakeWithHint[int](site)
+		for j := 0; j < 300; j++ {
+			s = append(s, j)
+		}
+		Observe(site, s)
+	}
+	if s := MakeWithHint[int](site); cap(s) != 300 {
+		t.Errorf("learned capacity %d, want 300", cap(s))
+	}
+}
+
+// TestNewCapacityHintsValidates tests option validation.
+func TestNewCapacityHintsValidates(t *testing.T) {
+	bad := []HintOptions{
+		{Window: 0, Percentile: 0.5},
+		{Window: 4, Percentile: 0},
+		{Window: 4, Percentile: 1.5},
+		{Window: 4, Estimator: EWMAEstimator, Alpha: 0, Headroom: 1},
+		{Window: 4, Estimator: 9},
+		{Window: 4, Percentile: 0.5, Default: -1},
+	}
+	for _, opts := range bad {
+		if _, err := NewCapacityHints(opts); err == nil {
+			t.Errorf("%+v: expected error", opts)
+		}
+	}
+}
+
+// TestNegativeObservations tests that negative lengths never produce a
+// negative hint, which would make make panic.
+func TestNegativeObservations(t *testing.T) {
+	for _, opts := range []HintOptions{
+		{Estimator: PercentileEstimator, Percentile: 0.5, Window: 4},
+		{Estimator: EWMAEstimator, Window: 4, Alpha: 0.5, Headroom: 1.2},
+	} {
+		h, err := NewCapacityHints(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		h.Observe("site", -10, 0)
+		if hint := h.Hint("site"); hint != 0 {
+			t.Errorf("%v: hint %d, want 0", opts.Estimator, hint)
+		}
+		_ = make([]int, 0, h.Hint("site"))
+	}
+}
+
+// TestCompareHints tests that a learned hint beats both extremes on a
+// workload of steady lengths: fewer reallocations than no hint and less
+// unused capacity than an oversized fixed one.
+func TestCompareHints(t *testing.T) {
+	lengths := hintWorkload(200, 1000, 0.05, 1)
+	results, err := compareHints(lengths, 4000, DefaultHintOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	none, fixed, learned := results[0], results[1], results[2]
+	if learned.Reallocs >= none.Reallocs/10 {
+		t.Errorf("learned reallocs %d vs none %d", learned.Reallocs, none.Reallocs)
+	}
+	if learned.OverAlloc >= fixed.OverAlloc/2 {
+		t.Errorf("learned unused %d vs fixed %d", learned.OverAlloc, fixed.OverAlloc)
+	}
+	if fixed.Reallocs != 0 {
+		t.Errorf("fixed capacity above every length reallocated %d times", fixed.Reallocs)
+	}
+	var sb strings.Builder
+	if err := writeHintComparison(&sb, results); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "learned percentile") {
+		t.Errorf("unexpected output:\n%s", sb.String())
+	}
+}
@@ -1,48 +1,137 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"math/rand"
-	"time"
 	"log"
-	"runtime/pprof"
 	"os"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func dynamicGrowth() {
-	// Simulate a slice growing dynamically
-	var slice []int
-	for i := 0; i < 100000; i++ {
-		slice = append(slice, rand.Int())
+// parseInts parses a comma-separated list of positive integers.
+func parseInts(s string) ([]int, error) {
+	var out []int
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q: %w", field, err)
+		}
+		if v <= 0 {
+			return nil, fmt.Errorf("value must be positive, got %d", v)
+		}
+		out = append(out, v)
 	}
-	fmt.Println("Dynamic Growth: Final length of slice =", len(slice))
+	if len(out) == 0 {
+		return nil, fmt.Errorf("empty list %q", s)
+	}
+	return out, nil
 }
 
-func preallocate() {
-	// Preallocate slice to avoid reallocations
-	slice := make([]int, 0, 100000)
-	for i := 0; i < 100000; i++ {
-		slice = append(slice, rand.Int())
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
 	}
-	fmt.Println("Preallocation: Final length of slice =", len(slice))
 }
 
-func main() {
-	// Profiling setup
-	file, err := os.Create("cpu_profile.prof")
-	if err != nil {
-		log.Fatal("could not create CPU profile: ", err)
+// run parses the flags and runs the selected mode. Errors are returned rather
+// than fatal so that deferred cleanup, such as stopping the CPU profile, runs.
+func run() error {
+	counts := flag.String("n", "1000,100000", "comma-separated element counts")
+	sizes := flag.String("sizes", "8,64,256", "comma-separated element sizes in bytes")
+	increment := flag.Int("increment", 1000, "capacity step for fixedSizeIncrements")
+	runs := flag.Int("runs", 5, "independent runs per scenario")
+	minTime := flag.Duration("mintime", 100*time.Millisecond, "minimum duration of each run")
+	cpuProfile := flag.String("cpuprofile", "", "write a CPU profile to this file")
+	reportPath := flag.String("report", "", "write a Markdown (.md) or HTML (.html) report with memory profiles")
+	profileDir := flag.String("profiledir", "", "write heap and allocs profiles for each scenario to this directory")
+	profileIters := flag.Int("profileiters", 10, "strategy calls per scenario when profiling memory")
+	hintRuns := flag.Int("hints", 0, "compare capacity hint policies over this many runs instead of benchmarking")
+	hintMedian := flag.Int("hint-median", 60000, "median slice length of the hint workload")
+	hintSigma := flag.Float64("hint-sigma", 0.25, "log-normal spread of the hint workload lengths")
+	flag.Parse()
+
+	if *hintRuns > 0 {
+		lengths := hintWorkload(*hintRuns, *hintMedian, *hintSigma, 1)
+		ewma := DefaultHintOptions()
+		ewma.Estimator = EWMAEstimator
+		results, err := compareHints(lengths, 100000, DefaultHintOptions(), ewma)
+		if err != nil {
+			return err
+		}
+		return writeHintComparison(os.Stdout, results)
+	}
+
+	cfg := ExperimentConfig{Increment: *increment, Runs: *runs, MinTime: *minTime}
+	var err error
+	if cfg.Counts, err = parseInts(*counts); err != nil {
+		return fmt.Errorf("-n: %w", err)
+	}
+	if cfg.Sizes, err = parseInts(*sizes); err != nil {
+		return fmt.Errorf("-sizes: %w", err)
 	}
-	pprof.StartCPUProfile(file)
-	defer pprof.StopCPUProfile()
 
-	// Run dynamic growth and measure time
-	start := time.Now()
-	dynamicGrowth()
-	fmt.Println("Time taken for dynamic growth:", time.Since(start))
+	var format string
+	if *reportPath != "" {
+		if format, err = reportFormat(*reportPath); err != nil {
+			return fmt.Errorf("-report: %w", err)
+		}
+	}
+
+	if *cpuProfile != "" {
+		file, err := os.Create(*cpuProfile)
+		if err != nil {
+			return fmt.Errorf("could not create CPU profile: %w", err)
+		}
+		defer file.Close()
+		if err := pprof.StartCPUProfile(file); err != nil {
+			return fmt.Errorf("could not start CPU profile: %w", err)
+		}
+		defer pprof.StopCPUProfile()
+	}
 
-	// Run preallocation and measure time
-	start = time.Now()
-	preallocate()
-	fmt.Println("Time taken for preallocation:", time.Since(start))
-}
\ No newline at end of file
+	results, err := runExperiment(cfg)
+	if err != nil {
+		return err
+	}
+	if err := writeResults(os.Stdout, results); err != nil {
+		return err
+	}
+	if *reportPath == "" && *profileDir == "" {
+		return nil
+	}
+
+	profiles, err := profileExperiment(cfg, ProfileConfig{Iters: *profileIters, Dir: *profileDir})
+	if err != nil {
+		return err
+	}
+	if *reportPath == "" {
+		return nil
+	}
+	report := Report{
+		Generated: time.Now(),
+		Config:    cfg,
+		Results:   results,
+		Profiles:  profiles,
+		Diffs:     diffProfiles(profiles),
+	}
+	file, err := os.Create(*reportPath)
+	if err != nil {
+		return err
+	}
+	if err := writeReport(file, report, format); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	fmt.Println("Report written to", *reportPath)
+	return nil
+}
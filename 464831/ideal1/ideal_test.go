@@ -0,0 +1,145 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	benchCounts = flag.String("counts", "1000,100000", "element counts for BenchmarkGrowth")
+	benchSizes  = flag.String("sizes", "8,64,256", "element sizes for BenchmarkGrowth")
+	benchStep   = flag.Int("increment", 1000, "capacity step for fixedSizeIncrements")
+)
+
+// BenchmarkGrowth runs every strategy for each element count and size. Pass
+// -args -counts=... -sizes=... to change the grid, and feed the output of
+// several -count runs to benchstat for comparison.
+func BenchmarkGrowth(b *testing.B) {
+	counts, err := parseInts(*benchCounts)
+	if err != nil {
+		b.Fatal(err)
+	}
+	sizes, err := parseInts(*benchSizes)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, size := range sizes {
+		strategies, err := strategiesFor(size, *benchStep)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, n := range counts {
+			for _, s := range strategies {
+				name := fmt.Sprintf("size=%d/n=%d/%s", size, n, s.Name)
+				b.Run(name, func(b *testing.B) {
+					b.ReportAllocs()
+					reallocs := 0
+					for i := 0; i < b.N; i++ {
+						reallocs = s.Grow(n)
+					}
+					b.ReportMetric(float64(reallocs), "reallocs/op")
+				})
+			}
+		}
+	}
+}
+
+// TestStrategiesBuildFullSlices tests that every strategy yields n elements
+// and reports the expected number of reallocations.
+func TestStrategiesBuildFullSlices(t *testing.T) {
+	const n = 2500
+	s, r := dynamicGrowth[elem64](n)
+	if len(s) != n || r == 0 {
+		t.Errorf("dynamicGrowth: len %d, reallocs %d", len(s), r)
+	}
+	s, r = preallocate[elem64](n)
+	if len(s) != n || r != 1 {
+		t.Errorf("preallocate: len %d, reallocs %d", len(s), r)
+	}
+	s, r = fixedSizeIncrements[elem64](n, 1000)
+	if len(s) != n || r != 3 || cap(s) != 3000 {
+		t.Errorf("fixedSizeIncrements: len %d, cap %d, reallocs %d", len(s), cap(s), r)
+	}
+}
+
+// TestStrategiesForRejectsUnknownSize tests argument validation.
+func TestStrategiesForRejectsUnknownSize(t *testing.T) {
+	if _, err := strategiesFor(12, 10); err == nil {
+		t.Error("expected error for unsupported element size")
+	}
+	if _, err := strategiesFor(8, 0); err == nil {
+		t.Error("expected error for zero increment")
+	}
+}
+
+// TestSummarize tests the mean and confidence interval.
+func TestSummarize(t *testing.T) {
+	s := summarize([]float64{10, 12, 14})
+	if s.Mean != 12 {
+		t.Errorf("mean = %v, want 12", s.Mean)
+	}
+	// sd = 2, t(2) = 4.303, half-width = 4.303 * 2 / sqrt(3).
+	if want := 4.303 * 2 / math.Sqrt(3); math.Abs(s.CI-want) > 1e-9 {
+		t.Errorf("CI = %v, want %v", s.CI, want)
+	}
+}
+
+// TestCompare tests Welch's t-test on clearly separated and overlapping
+// samples.
+func TestCompare(t *testing.T) {
+	result := func(times ...float64) Result {
+		r := Result{}
+		for _, v := range times {
+			r.Samples = append(r.Samples, Sample{NsPerOp: v})
+		}
+		summarizeResult(&r)
+		return r
+	}
+	base := result(100, 101, 99, 100)
+	fast := result(50, 51, 49, 50)
+	compare(&fast, base)
+	if !fast.Significant || math.Abs(fast.Delta+0.5) > 1e-9 {
+		t.Errorf("fast: delta %v significant %v", fast.Delta, fast.Significant)
+	}
+	noisy := result(80, 120, 95, 110)
+	compare(&noisy, base)
+	if noisy.Significant {
+		t.Errorf("noisy: delta %v reported as significant", noisy.Delta)
+	}
+}
+
+// TestRunExperiment tests a small end-to-end run and its report.
+func TestRunExperiment(t *testing.T) {
+	results, err := runExperiment(ExperimentConfig{
+		Counts: []int{100}, Sizes: []int{8, 256}, Increment: 10, Runs: 2, MinTime: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 16 {
+		t.Fatalf("got %d results, want 16", len(results))
+	}
+	for _, r := range results {
+		if r.Time.Mean <= 0 || len(r.Samples) != 2 {
+			t.Errorf("%s/%dB: time %v samples %d", r.Strategy, r.ElemSize, r.Time.Mean, len(r.Samples))
+		}
+		if r.Strategy == "preallocate" && r.Allocs.Mean > 1.5 {
+			t.Errorf("preallocate made %.1f allocs/op", r.Allocs.Mean)
+		}
+	}
+	var sb strings.Builder
+	if err := writeResults(&sb, results); err != nil {
+		t.Fatal(err)
+	}
+	if out := sb.String(); !strings.Contains(out, "fixedSizeIncrements(10)") || !strings.Contains(out, "base") {
+		t.Errorf("unexpected report:\n%s", out)
+	}
+
+	if _, err := runExperiment(ExperimentConfig{Counts: []int{1}, Sizes: []int{8}, Increment: 1, Runs: 1}); err == nil {
+		t.Error("expected error for a single run")
+	}
+}
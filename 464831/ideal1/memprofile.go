@@ -0,0 +1,296 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"time"
+)
+
+// GCStats is the change in garbage collector counters over one scenario.
+type GCStats struct {
+	Cycles     uint32
+	Pause      time.Duration
+	TotalAlloc uint64
+	Mallocs    uint64
+	HeapInuse  uint64 // after the scenario's final collection
+}
+
+// AllocSite is the allocation volume attributed to one source location: the
+// innermost frame outside the runtime.
+type AllocSite struct {
+	Function string
+	Location string
+	Bytes    int64
+	Objects  int64
+}
+
+// MemoryProfile is the memory behaviour of one strategy in one scenario.
+// Byte and object counts cover all iterations; use the PerOp methods for
+// values comparable with the timing results.
+type MemoryProfile struct {
+	Strategy string
+	Count    int
+	ElemSize int
+	Iters    int
+	GC       GCStats
+	Sites    []AllocSite // sorted by bytes, largest first
+	Files    []string    // pprof files written for the scenario, if any
+}
+
+// BytesPerOp returns the bytes allocated by one call of the strategy.
+func (p MemoryProfile) BytesPerOp() float64 { return float64(p.GC.TotalAlloc) / float64(p.Iters) }
+
+// AllocsPerOp returns the allocations made by one call of the strategy.
+func (p MemoryProfile) AllocsPerOp() float64 { return float64(p.GC.Mallocs) / float64(p.Iters) }
+
+// MemoryDiff compares a strategy's memory use with the scenario baseline.
+type MemoryDiff struct {
+	Strategy   string
+	Baseline   string
+	Count      int
+	ElemSize   int
+	Bytes      float64 // per op, strategy minus baseline
+	Allocs     float64 // per op, strategy minus baseline
+	Cycles     int64
+	Pause      time.Duration
+	BytesRatio float64 // strategy bytes / baseline bytes
+}
+
+// ProfileConfig controls memory profiling of an experiment.
+type ProfileConfig struct {
+	Iters int    // calls of each strategy per scenario
+	Dir   string // if set, heap and allocs profiles are written here
+}
+
+// profileExperiment runs every scenario of cfg once more with every
+// allocation sampled, recording GC counters and allocation sites. The
+// sampling rate is raised only here, so that timing runs are not slowed by
+// it. Samples taken earlier at the default rate stay in the cumulative
+// profiles but cancel out against each scenario's baseline, and the
+// per-scenario sites are diffs of the records, so neither is skewed.
+func profileExperiment(cfg ExperimentConfig, pc ProfileConfig) ([]MemoryProfile, error) {
+	if pc.Iters <= 0 {
+		return nil, fmt.Errorf("profile iterations must be positive, got %d", pc.Iters)
+	}
+	if pc.Dir != "" {
+		if err := os.MkdirAll(pc.Dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	oldRate := runtime.MemProfileRate
+	runtime.MemProfileRate = 1
+	defer func() { runtime.MemProfileRate = oldRate }()
+
+	var profiles []MemoryProfile
+	for _, size := range cfg.Sizes {
+		strategies, err := strategiesFor(size, cfg.Increment)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range cfg.Counts {
+			for _, s := range strategies {
+				p, err := profileScenario(s, n, size, pc)
+				if err != nil {
+					return nil, err
+				}
+				profiles = append(profiles, p)
+			}
+		}
+	}
+	return profiles, nil
+}
+
+// profileScenario profiles n elements of size bytes grown by s. The
+// runtime's heap and allocs profiles are cumulative since the process
+// started, so when pc.Dir is set a baseline of each is written just before
+// the scenario runs; view the scenario's own allocations with
+//
+//	go tool pprof -base 64B-n1000-Vec_1.25x_.allocs.base.pprof 64B-n1000-Vec_1.25x_.allocs.pprof
+func profileScenario(s Strategy, n, size int, pc ProfileConfig) (MemoryProfile, error) {
+	p := MemoryProfile{Strategy: s.Name, Count: n, ElemSize: size, Iters: pc.Iters}
+
+	// The memory profile is published at the end of a GC cycle and may lag
+	// by up to two cycles, so collect twice on both sides.
+	runtime.GC()
+	runtime.GC()
+	var base string
+	if pc.Dir != "" {
+		base = filepath.Join(pc.Dir, scenarioFileName(p))
+		for _, kind := range []string{"heap", "allocs"} {
+			path := base + "." + kind + ".base.pprof"
+			if err := writeProfile(kind, path); err != nil {
+				return p, err
+			}
+			p.Files = append(p.Files, path)
+		}
+		// Publish the baseline writes before taking the snapshot.
+		runtime.GC()
+		runtime.GC()
+	}
+	before := memProfile()
+	var msBefore, msAfter runtime.MemStats
+	runtime.ReadMemStats(&msBefore)
+	for i := 0; i < pc.Iters; i++ {
+		s.Grow(n)
+	}
+	runtime.ReadMemStats(&msAfter)
+	runtime.GC()
+	runtime.GC()
+	after := memProfile()
+
+	var final runtime.MemStats
+	runtime.ReadMemStats(&final)
+	p.GC = GCStats{
+		Cycles:     msAfter.NumGC - msBefore.NumGC,
+		Pause:      time.Duration(msAfter.PauseTotalNs - msBefore.PauseTotalNs),
+		TotalAlloc: msAfter.TotalAlloc - msBefore.TotalAlloc,
+		Mallocs:    msAfter.Mallocs - msBefore.Mallocs,
+		HeapInuse:  final.HeapInuse,
+	}
+	p.Sites = diffSites(before, after)
+
+	if pc.Dir != "" {
+		for _, kind := range []string{"heap", "allocs"} {
+			path := base + "." + kind + ".pprof"
+			if err := writeProfile(kind, path); err != nil {
+				return p, err
+			}
+			p.Files = append(p.Files, path)
+		}
+	}
+	return p, nil
+}
+
+// profileKey identifies a memory profile bucket. The runtime keeps separate
+// buckets per allocation size, so the stack alone is not unique.
+type profileKey struct {
+	stack [32]uintptr
+	size  int64
+}
+
+// memProfile returns every memory profile record, keyed by bucket.
+func memProfile() map[profileKey]runtime.MemProfileRecord {
+	var records []runtime.MemProfileRecord
+	n, _ := runtime.MemProfile(nil, true)
+	for {
+		records = make([]runtime.MemProfileRecord, n+50)
+		var ok bool
+		if n, ok = runtime.MemProfile(records, true); ok {
+			records = records[:n]
+			break
+		}
+	}
+	byKey := make(map[profileKey]runtime.MemProfileRecord, len(records))
+	for _, r := range records {
+		if r.AllocObjects == 0 {
+			continue
+		}
+		byKey[profileKey{r.Stack0, r.AllocBytes / r.AllocObjects}] = r
+	}
+	return byKey
+}
+
+// diffSites attributes the allocations made between two profiles to their
+// source locations.
+func diffSites(before, after map[profileKey]runtime.MemProfileRecord) []AllocSite {
+	bySite := make(map[string]*AllocSite)
+	for key, r := range after {
+		prev := before[key]
+		bytes := r.AllocBytes - prev.AllocBytes
+		objects := r.AllocObjects - prev.AllocObjects
+		if bytes <= 0 {
+			continue
+		}
+		fn, loc := allocSite(r.Stack())
+		if strings.HasSuffix(fn, ".memProfile") {
+			// The profiler's own bookkeeping.
+			continue
+		}
+		key := fn + " " + loc
+		site, ok := bySite[key]
+		if !ok {
+			site = &AllocSite{Function: fn, Location: loc}
+			bySite[key] = site
+		}
+		site.Bytes += bytes
+		site.Objects += objects
+	}
+	sites := make([]AllocSite, 0, len(bySite))
+	for _, s := range bySite {
+		sites = append(sites, *s)
+	}
+	slices.SortFunc(sites, func(a, b AllocSite) int {
+		return cmp.Or(cmp.Compare(b.Bytes, a.Bytes), cmp.Compare(a.Function, b.Function))
+	})
+	return sites
+}
+
+// allocSite returns the innermost frame of stack that is not in the runtime.
+func allocSite(stack []uintptr) (function, location string) {
+	frames := runtime.CallersFrames(stack)
+	for {
+		f, more := frames.Next()
+		internal := strings.HasPrefix(f.Function, "runtime.") || strings.HasPrefix(f.Function, "internal/runtime/")
+		if !internal || !more {
+			return f.Function, fmt.Sprintf("%s:%d", filepath.Base(f.File), f.Line)
+		}
+	}
+}
+
+func writeProfile(kind, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := pprof.Lookup(kind).WriteTo(f, 0); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s profile: %w", kind, err)
+	}
+	return f.Close()
+}
+
+// scenarioFileName returns a file name stem such as "64B-n1000-Vec_1.25x_".
+func scenarioFileName(p MemoryProfile) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, p.Strategy)
+	return fmt.Sprintf("%dB-n%d-%s", p.ElemSize, p.Count, name)
+}
+
+// diffProfiles compares each profile with the first strategy of the same
+// scenario, which is the baseline.
+func diffProfiles(profiles []MemoryProfile) []MemoryDiff {
+	var diffs []MemoryDiff
+	var base MemoryProfile
+	for i, p := range profiles {
+		if i == 0 || p.Count != base.Count || p.ElemSize != base.ElemSize {
+			base = p
+			continue
+		}
+		d := MemoryDiff{
+			Strategy: p.Strategy,
+			Baseline: base.Strategy,
+			Count:    p.Count,
+			ElemSize: p.ElemSize,
+			Bytes:    p.BytesPerOp() - base.BytesPerOp(),
+			Allocs:   p.AllocsPerOp() - base.AllocsPerOp(),
+			Cycles:   int64(p.GC.Cycles) - int64(base.GC.Cycles),
+			Pause:    p.GC.Pause - base.GC.Pause,
+		}
+		if base.GC.TotalAlloc > 0 {
+			d.BytesRatio = float64(p.GC.TotalAlloc) / float64(base.GC.TotalAlloc)
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs
+}
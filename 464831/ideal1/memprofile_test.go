@@ -0,0 +1,142 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestProfileExperiment tests that allocation sites and GC counters are
+// attributed to the strategy that caused them.
+func TestProfileExperiment(t *testing.T) {
+	dir := t.TempDir()
+	rate := runtime.MemProfileRate
+	cfg := ExperimentConfig{Counts: []int{5000}, Sizes: []int{64}, Increment: 1000}
+	profiles, err := profileExperiment(cfg, ProfileConfig{Iters: 3, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.MemProfileRate != rate {
+		t.Errorf("memory profile rate left at %d, want %d", runtime.MemProfileRate, rate)
+	}
+	if len(profiles) != 8 {
+		t.Fatalf("got %d profiles, want 8", len(profiles))
+	}
+	for _, p := range profiles {
+		if len(p.Sites) == 0 {
+			t.Fatalf("%s: no allocation sites", p.Strategy)
+		}
+		top := p.Sites[0]
+		if p.Strategy == "dynamicGrowth" && !strings.Contains(top.Function, "dynamicGrowth") {
+			t.Errorf("%s: top site %s", p.Strategy, top.Function)
+		}
+		if strings.HasPrefix(p.Strategy, "Vec(") && !strings.Contains(top.Function, "realloc") {
+			t.Errorf("%s: top site %s", p.Strategy, top.Function)
+		}
+		if p.Strategy == "slabPool" {
+			checkSlabPoolProfile(t, p)
+		} else if top.Bytes != int64(p.GC.TotalAlloc) {
+			// The strategy's own site accounts for all of its allocations.
+			t.Errorf("%s: top site %d bytes, TotalAlloc %d", p.Strategy, top.Bytes, p.GC.TotalAlloc)
+		}
+		for _, f := range p.Files {
+			if info, err := os.Stat(f); err != nil || info.Size() == 0 {
+				t.Errorf("%s: profile %s missing or empty", p.Strategy, f)
+			}
+		}
+	}
+	if got := profiles[1]; got.AllocsPerOp() != 1 || got.BytesPerOp() < 5000*64 {
+		t.Errorf("preallocate: %.1f allocs/op, %.0f B/op", got.AllocsPerOp(), got.BytesPerOp())
+	}
+	for _, name := range []string{"64B-n5000-Vec_1.25x_.allocs.pprof", "64B-n5000-Vec_1.25x_.allocs.base.pprof"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// checkSlabPoolProfile checks that the slab pool strategy's bytes come from
+// the pool, led by its buffers, and none from the strategy's own loop.
+func checkSlabPoolProfile(t *testing.T, p MemoryProfile) {
+	t.Helper()
+	if top := p.Sites[0]; !strings.Contains(top.Function, "SlabPool") {
+		t.Errorf("%s: top site %s", p.Strategy, top.Function)
+	}
+	var total int64
+	for _, s := range p.Sites {
+		if strings.Contains(s.Function, "pooledGrowth") {
+			t.Errorf("%s: strategy allocated %d bytes at %s", p.Strategy, s.Bytes, s.Location)
+		}
+		total += s.Bytes
+	}
+	if total != int64(p.GC.TotalAlloc) {
+		t.Errorf("%s: sites total %d bytes, TotalAlloc %d", p.Strategy, total, p.GC.TotalAlloc)
+	}
+}
+
+// TestDiffProfiles tests that diffs are taken against the first strategy of
+// each scenario.
+func TestDiffProfiles(t *testing.T) {
+	profile := func(name string, n int, bytes uint64) MemoryProfile {
+		return MemoryProfile{Strategy: name, Count: n, ElemSize: 8, Iters: 2,
+			GC: GCStats{TotalAlloc: bytes, Mallocs: 2, Cycles: 1}}
+	}
+	diffs := diffProfiles([]MemoryProfile{
+		profile("a", 10, 400), profile("b", 10, 200),
+		profile("a", 20, 1000), profile("b", 20, 3000),
+	})
+	if len(diffs) != 2 {
+		t.Fatalf("got %d diffs, want 2", len(diffs))
+	}
+	if d := diffs[0]; d.Baseline != "a" || d.Bytes != -100 || d.BytesRatio != 0.5 {
+		t.Errorf("n=10: %+v", d)
+	}
+	if d := diffs[1]; d.Count != 20 || d.Bytes != 1000 || d.BytesRatio != 3 {
+		t.Errorf("n=20: %+v", d)
+	}
+}
+
+// TestWriteReport tests both report formats.
+func TestWriteReport(t *testing.T) {
+	r := Report{
+		Generated: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Config:    ExperimentConfig{Runs: 3},
+		Results: []Result{
+			{Strategy: "dynamicGrowth", Count: 10, ElemSize: 8, Baseline: true, Time: Summary{Mean: 1500}},
+			{Strategy: "preallocate", Count: 10, ElemSize: 8, Time: Summary{Mean: 500}, Delta: -2.0 / 3, Significant: true},
+		},
+		Profiles: []MemoryProfile{{
+			Strategy: "a<b>", Count: 10, ElemSize: 8, Iters: 1,
+			Sites: []AllocSite{{Function: "main.grow", Location: "growth.go:1", Bytes: 2048, Objects: 1}},
+		}},
+	}
+	var md strings.Builder
+	if err := writeReport(&md, r, "markdown"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Slice growth report", "| 8B | 10 | preallocate | 500ns |", "-66.67%", "`main.grow` | growth.go:1 | 2.00KiB | 1 |"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown missing %q:\n%s", want, md.String())
+		}
+	}
+	var html strings.Builder
+	if err := writeReport(&html, r, "html"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html.String(), "a&lt;b&gt;") || !strings.Contains(html.String(), "<code>main.grow</code>") {
+		t.Errorf("unexpected html:\n%s", html.String())
+	}
+	if err := writeReport(&html, r, "pdf"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+
+	for path, want := range map[string]string{"r.md": "markdown", "R.HTML": "html", "r.txt": ""} {
+		got, err := reportFormat(path)
+		if got != want || (want == "") != (err != nil) {
+			t.Errorf("reportFormat(%q) = %q, %v", path, got, err)
+		}
+	}
+}
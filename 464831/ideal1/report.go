@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// topSites is the number of allocation sites listed per scenario.
+const topSites = 5
+
+// Report gathers everything the growth experiment measured.
+type Report struct {
+	Generated time.Time
+	Config    ExperimentConfig
+	Results   []Result
+	Profiles  []MemoryProfile
+	Diffs     []MemoryDiff
+}
+
+// reportFormat returns "markdown" or "html" based on the file extension.
+func reportFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return "markdown", nil
+	case ".html", ".htm":
+		return "html", nil
+	}
+	return "", fmt.Errorf("unsupported report extension %q (want .md or .html)", filepath.Ext(path))
+}
+
+var reportFuncs = map[string]any{
+	"duration": func(ns float64) string { return time.Duration(ns).Round(100 * time.Nanosecond).String() },
+	"bytes": func(v any) string {
+		switch b := v.(type) {
+		case int64:
+			return formatBytes(float64(b))
+		case uint64:
+			return formatBytes(float64(b))
+		case float64:
+			return formatBytes(b)
+		}
+		return fmt.Sprint(v)
+	},
+	"ci": percent,
+	"delta": func(r Result) string {
+		switch {
+		case r.Baseline:
+			return "base"
+		case r.Significant:
+			return fmt.Sprintf("%+.2f%%", r.Delta*100)
+		}
+		return "~"
+	},
+	"signedBytes": func(b float64) string {
+		if b < 0 {
+			return "-" + formatBytes(-b)
+		}
+		return "+" + formatBytes(b)
+	},
+	"top": func(sites []AllocSite) []AllocSite { return sites[:min(len(sites), topSites)] },
+}
+
+const markdownReport = `# Slice growth report
+
+Generated {{.Generated.Format "2006-01-02 15:04:05 MST"}} with {{.Config.Runs}} runs per scenario.
+Time deltas are against the first strategy of each scenario; "~" means the
+difference is not significant at p<0.05 (Welch's t-test).
+
+## Time
+
+| size | n | strategy | time/op | ±95% | allocs/op | B/op | reallocs | vs base |
+|---:|---:|---|---:|---:|---:|---:|---:|---|
+{{- range .Results}}
+| {{.ElemSize}}B | {{.Count}} | {{.Strategy}} | {{duration .Time.Mean}} | {{ci .Time}} | {{printf "%.0f" .Allocs.Mean}} | {{bytes .Bytes.Mean}} | {{.Reallocs}} | {{delta .}} |
+{{- end}}
+
+## Memory
+
+| size | n | strategy | B/op | allocs/op | GC cycles | GC pause | heap in use |
+|---:|---:|---|---:|---:|---:|---:|---:|
+{{- range .Profiles}}
+| {{.ElemSize}}B | {{.Count}} | {{.Strategy}} | {{bytes .BytesPerOp}} | {{printf "%.1f" .AllocsPerOp}} | {{.GC.Cycles}} | {{.GC.Pause}} | {{bytes .GC.HeapInuse}} |
+{{- end}}
+
+## Memory against baseline
+
+| size | n | strategy | baseline | Δ B/op | ratio | Δ allocs/op | Δ GC cycles | Δ GC pause |
+|---:|---:|---|---|---:|---:|---:|---:|---:|
+{{- range .Diffs}}
+| {{.ElemSize}}B | {{.Count}} | {{.Strategy}} | {{.Baseline}} | {{signedBytes .Bytes}} | {{printf "%.2fx" .BytesRatio}} | {{printf "%+.1f" .Allocs}} | {{printf "%+d" .Cycles}} | {{.Pause}} |
+{{- end}}
+
+## Top allocation sites
+
+Profile files are cumulative for the whole run; pass a scenario's
+` + "`.base.pprof`" + ` file to ` + "`go tool pprof -base`" + ` to see only its allocations.
+{{range .Profiles}}
+### {{.ElemSize}}B, n={{.Count}}, {{.Strategy}}
+
+| function | location | bytes | objects |
+|---|---|---:|---:|
+{{- range top .Sites}}
+| ` + "`{{.Function}}`" + ` | {{.Location}} | {{bytes .Bytes}} | {{.Objects}} |
+{{- end}}
+{{- if .Files}}
+
+Profiles: {{range $i, $f := .Files}}{{if $i}}, {{end}}` + "`{{$f}}`" + `{{end}}
+{{- end}}
+{{end}}`
+
+const htmlReport = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Slice growth report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+</style>
+</head>
+<body>
+<h1>Slice growth report</h1>
+<p>Generated {{.Generated.Format "2006-01-02 15:04:05 MST"}} with {{.Config.Runs}} runs per scenario.
+Time deltas are against the first strategy of each scenario; "~" means the
+difference is not significant at p&lt;0.05 (Welch's t-test).</p>
+
+<h2>Time</h2>
+<table>
+<tr><th>size</th><th>n</th><th>strategy</th><th>time/op</th><th>±95%</th><th>allocs/op</th><th>B/op</th><th>reallocs</th><th>vs base</th></tr>
+{{- range .Results}}
+<tr><td class="num">{{.ElemSize}}B</td><td class="num">{{.Count}}</td><td>{{.Strategy}}</td><td class="num">{{duration .Time.Mean}}</td><td class="num">{{ci .Time}}</td><td class="num">{{printf "%.0f" .Allocs.Mean}}</td><td class="num">{{bytes .Bytes.Mean}}</td><td class="num">{{.Reallocs}}</td><td>{{delta .}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Memory</h2>
+<table>
+<tr><th>size</th><th>n</th><th>strategy</th><th>B/op</th><th>allocs/op</th><th>GC cycles</th><th>GC pause</th><th>heap in use</th></tr>
+{{- range .Profiles}}
+<tr><td class="num">{{.ElemSize}}B</td><td class="num">{{.Count}}</td><td>{{.Strategy}}</td><td class="num">{{bytes .BytesPerOp}}</td><td class="num">{{printf "%.1f" .AllocsPerOp}}</td><td class="num">{{.GC.Cycles}}</td><td class="num">{{.GC.Pause}}</td><td class="num">{{bytes .GC.HeapInuse}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Memory against baseline</h2>
+<table>
+<tr><th>size</th><th>n</th><th>strategy</th><th>baseline</th><th>Δ B/op</th><th>ratio</th><th>Δ allocs/op</th><th>Δ GC cycles</th><th>Δ GC pause</th></tr>
+{{- range .Diffs}}
+<tr><td class="num">{{.ElemSize}}B</td><td class="num">{{.Count}}</td><td>{{.Strategy}}</td><td>{{.Baseline}}</td><td class="num">{{signedBytes .Bytes}}</td><td class="num">{{printf "%.2fx" .BytesRatio}}</td><td class="num">{{printf "%+.1f" .Allocs}}</td><td class="num">{{printf "%+d" .Cycles}}</td><td class="num">{{.Pause}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Top allocation sites</h2>
+<p>Profile files are cumulative for the whole run; pass a scenario's
+<code>.base.pprof</code> file to <code>go tool pprof -base</code> to see only its allocations.</p>
+{{- range .Profiles}}
+<h3>{{.ElemSize}}B, n={{.Count}}, {{.Strategy}}</h3>
+<table>
+<tr><th>function</th><th>location</th><th>bytes</th><th>objects</th></tr>
+{{- range top .Sites}}
+<tr><td><code>{{.Function}}</code></td><td>{{.Location}}</td><td class="num">{{bytes .Bytes}}</td><td class="num">{{.Objects}}</td></tr>
+{{- end}}
+</table>
+{{- if .Files}}
+<p>Profiles: {{range $i, $f := .Files}}{{if $i}}, {{end}}<code>{{$f}}</code>{{end}}</p>
+{{- end}}
+{{- end}}
+</body>
+</html>
+`
+
+// writeReport renders r as Markdown or HTML.
+func writeReport(w io.Writer, r Report, format string) error {
+	switch format {
+	case "markdown":
+		t, err := template.New("report").Funcs(reportFuncs).Parse(markdownReport)
+		if err != nil {
+			return err
+		}
+		return t.Execute(w, r)
+	case "html":
+		t, err := htmltemplate.New("report").Funcs(reportFuncs).Parse(htmlReport)
+		if err != nil {
+			return err
+		}
+		return t.Execute(w, r)
+	}
+	return fmt.Errorf("unsupported report format %q", format)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+// maxSlabClass is the largest size class, 1<<maxSlabClass elements. Larger
+// requests are allocated directly and never pooled.
+const maxSlabClass = 30
+
+// PoolStats counts SlabPool traffic.
+type PoolStats struct {
+	Gets     uint64
+	Hits     uint64 // Gets served from a free list
+	Misses   uint64 // Gets that allocated a new buffer
+	Puts     uint64
+	Dropped  uint64 // Puts discarded: odd capacity or full free list
+	Oversize uint64 // Gets above the largest size class
+}
+
+// HitRate returns the fraction of Gets served without allocating.
+func (s PoolStats) HitRate() float64 {
+	if s.Gets == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Gets)
+}
+
+type slabClass[T any] struct {
+	mu   sync.Mutex
+	free [][]T
+}
+
+// SlabPool recycles []T buffers in power-of-two size classes. Get(n) returns
+// an empty buffer with capacity of at least n, rounded up to the next power
+// of two; Put returns it for reuse. Unlike sync.Pool, retained buffers
+// survive garbage collection, so each class keeps at most a fixed number of
+// them. A SlabPool is safe for concurrent use.
+//
+// Building with the slabdebug tag records the caller of every Get so that
+// buffers never returned can be listed with Leaks, and makes Put panic on a
+// buffer that is already back in the pool. A buffer resliced or grown by
+// append still counts as returning the one Get handed out.
+type SlabPool[T any] struct {
+	classes     [maxSlabClass + 1]slabClass[T]
+	maxPerClass int
+
+	gets, hits, misses, puts, dropped, oversize atomic.Uint64
+
+	debug slabDebug
+}
+
+// NewSlabPool returns a pool that keeps up to maxPerClass free buffers in
+// each size class.
+func NewSlabPool[T any](maxPerClass int) *SlabPool[T] {
+	return &SlabPool[T]{maxPerClass: max(maxPerClass, 1)}
+}
+
+// slabClassOf returns the size class that holds n elements.
+func slabClassOf(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	return bits.Len(uint(n - 1))
+}
+
+// Get returns a buffer with length 0 and capacity of at least n.
+func (p *SlabPool[T]) Get(n int) []T {
+	p.gets.Add(1)
+	class := slabClassOf(n)
+	if class > maxSlabClass {
+		p.oversize.Add(1)
+		p.misses.Add(1)
+		buf := make([]T, 0, n)
+		p.debug.get(buf)
+		return buf
+	}
+	c := &p.classes[class]
+	c.mu.Lock()
+	var buf []T
+	if last := len(c.free) - 1; last >= 0 {
+		buf = c.free[last]
+		c.free[last] = nil
+		c.free = c.free[:last]
+	}
+	c.mu.Unlock()
+
+	if buf != nil {
+		p.hits.Add(1)
+	} else {
+		p.misses.Add(1)
+		buf = make([]T, 0, 1<<class)
+	}
+	p.debug.get(buf)
+	return buf
+}
+
+// Put returns buf to the pool. The caller must not use buf afterwards.
+// Buffers whose capacity is not a pooled power of two are dropped.
+func (p *SlabPool[T]) Put(buf []T) {
+	p.puts.Add(1)
+	p.debug.put(buf)
+	size := cap(buf)
+	class := slabClassOf(size)
+	if size == 0 || size != 1<<class || class > maxSlabClass {
+		p.dropped.Add(1)
+		return
+	}
+	// Clear the contents so pooled buffers do not keep garbage reachable.
+	buf = buf[:size]
+	clear(buf)
+	c := &p.classes[class]
+	c.mu.Lock()
+	if len(c.free) < p.maxPerClass {
+		c.free = append(c.free, buf[:0])
+		p.debug.retain(buf)
+		buf = nil
+	}
+	c.mu.Unlock()
+	if buf != nil {
+		p.dropped.Add(1)
+	}
+}
+
+// Stats returns a snapshot of the pool counters.
+func (p *SlabPool[T]) Stats() PoolStats {
+	return PoolStats{
+		Gets:     p.gets.Load(),
+		Hits:     p.hits.Load(),
+		Misses:   p.misses.Load(),
+		Puts:     p.puts.Load(),
+		Dropped:  p.dropped.Load(),
+		Oversize: p.oversize.Load(),
+	}
+}
+
+// Leaks lists buffers handed out by Get and not yet returned. It is only
+// populated in builds with the slabdebug tag and returns nil otherwise.
+func (p *SlabPool[T]) Leaks() []Leak { return p.debug.leaks() }
+
+// Leak describes a buffer that was never returned to its pool.
+type Leak struct {
+	Cap   int
+	Stack string // call stack of the Get that handed it out
+}
@@ -0,0 +1,144 @@
+//go:build slabdebug
+
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// slabDebug tracks outstanding buffers by the address of their backing
+// array, and which of them are back in a free list. Buffers that occupy no
+// memory, such as those of a zero-size T, all share one address and are
+// not tracked.
+type slabDebug struct {
+	mu          sync.Mutex
+	outstanding map[uintptr]outstandingBuf
+	pooled      map[uintptr]bool
+}
+
+type outstandingBuf struct {
+	leak   Leak
+	end    uintptr // address just past the backing array
+	caller string  // function that called Get
+}
+
+func (d *slabDebug) get(buf any) {
+	v := reflect.ValueOf(buf)
+	if !tracked(v) {
+		return
+	}
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var sb strings.Builder
+	var caller string
+	for {
+		f, more := frames.Next()
+		if caller == "" {
+			caller = f.Function
+		}
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.outstanding == nil {
+		d.outstanding = make(map[uintptr]outstandingBuf)
+	}
+	delete(d.pooled, v.Pointer())
+	d.outstanding[v.Pointer()] = outstandingBuf{
+		leak:   Leak{Cap: v.Cap(), Stack: sb.String()},
+		end:    v.Pointer() + uintptr(v.Cap())*v.Type().Elem().Size(),
+		caller: caller,
+	}
+}
+
+// put releases the outstanding buffer that buf is, was resliced from or
+// was grown from by append. Only a buffer that is already back in a free
+// list is an error; anything else is accepted as Put accepts it.
+func (d *slabDebug) put(buf any) {
+	v := reflect.ValueOf(buf)
+	if !tracked(v) {
+		return
+	}
+	ptr := v.Pointer()
+	caller := callerOf(3)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pooled[ptr] {
+		panic(fmt.Sprintf("SlabPool: Put of buffer (cap %d) that is already in the pool: returned twice", v.Cap()))
+	}
+	if _, ok := d.outstanding[ptr]; ok {
+		delete(d.outstanding, ptr)
+		return
+	}
+	// A reslice points into an outstanding array.
+	for key, o := range d.outstanding {
+		if key < ptr && ptr < o.end {
+			delete(d.outstanding, key)
+			return
+		}
+	}
+	// A buffer appended past its capacity has a new array, so release the
+	// largest buffer it outgrew that was handed to the same function.
+	var grown uintptr
+	best := 0
+	for key, o := range d.outstanding {
+		if o.caller != caller || o.leak.Cap >= v.Cap() {
+			continue
+		}
+		if o.leak.Cap > best || o.leak.Cap == best && key < grown {
+			grown, best = key, o.leak.Cap
+		}
+	}
+	if best > 0 {
+		delete(d.outstanding, grown)
+	}
+}
+
+// retain records that buf was added to a free list.
+func (d *slabDebug) retain(buf any) {
+	v := reflect.ValueOf(buf)
+	if !tracked(v) {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.pooled == nil {
+		d.pooled = make(map[uintptr]bool)
+	}
+	d.pooled[v.Pointer()] = true
+}
+
+// tracked reports whether the slice v has a backing array of its own.
+func tracked(v reflect.Value) bool {
+	return v.Cap() > 0 && v.Type().Elem().Size() > 0
+}
+
+// callerOf returns the function skip frames above its caller.
+func callerOf(skip int) string {
+	pcs := make([]uintptr, 1)
+	if runtime.Callers(skip+1, pcs) == 0 {
+		return ""
+	}
+	f, _ := runtime.CallersFrames(pcs).Next()
+	return f.Function
+}
+
+func (d *slabDebug) leaks() []Leak {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	leaks := make([]Leak, 0, len(d.outstanding))
+	for _, o := range d.outstanding {
+		leaks = append(leaks, o.leak)
+	}
+	slices.SortFunc(leaks, func(a, b Leak) int { return strings.Compare(a.Stack, b.Stack) })
+	return leaks
+}
@@ -0,0 +1,60 @@
+//go:build slabdebug
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSlabPoolLeaks tests that outstanding buffers are reported with the
+// stack of their Get, and that returning a buffer twice panics.
+func TestSlabPoolLeaks(t *testing.T) {
+	p := NewSlabPool[int](4)
+	kept := p.Get(10)
+	returned := p.Get(10)
+	p.Put(returned)
+
+	leaks := p.Leaks()
+	if len(leaks) != 1 || leaks[0].Cap != cap(kept) {
+		t.Fatalf("leaks = %+v", leaks)
+	}
+	if !strings.Contains(leaks[0].Stack, "TestSlabPoolLeaks") {
+		t.Errorf("leak stack does not name the caller:\n%s", leaks[0].Stack)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("double Put did not panic")
+		}
+	}()
+	p.Put(returned)
+}
+
+// TestSlabPoolZeroSize tests that buffers of a zero-size type, which share
+// one address, are not reported as leaks or double Puts.
+func TestSlabPoolZeroSize(t *testing.T) {
+	p := NewSlabPool[struct{}](4)
+	a, b := p.Get(8), p.Get(8)
+	p.Put(a)
+	p.Put(b)
+	if leaks := p.Leaks(); len(leaks) != 0 {
+		t.Errorf("leaks = %+v", leaks)
+	}
+}
+
+// TestSlabPoolGrownPut tests that returning a buffer appended past its
+// class capacity, or resliced, neither panics nor leaves a leak.
+func TestSlabPoolGrownPut(t *testing.T) {
+	p := NewSlabPool[int](4)
+	grown := p.Get(8)
+	for i := 0; i < 20; i++ {
+		grown = append(grown, i)
+	}
+	p.Put(grown)
+	resliced := p.Get(8)
+	p.Put(resliced[:4][2:])
+	if leaks := p.Leaks(); len(leaks) != 0 {
+		t.Errorf("leaks = %+v", leaks)
+	}
+}
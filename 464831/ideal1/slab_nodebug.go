@@ -0,0 +1,11 @@
+//go:build !slabdebug
+
+package main
+
+// slabDebug is a no-op outside slabdebug builds.
+type slabDebug struct{}
+
+func (slabDebug) get(any)       {}
+func (slabDebug) put(any)       {}
+func (slabDebug) retain(any)    {}
+func (slabDebug) leaks() []Leak { return nil }
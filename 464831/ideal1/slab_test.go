@@ -0,0 +1,134 @@
+package main
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// TestSlabClassOf tests rounding to power-of-two size classes.
+func TestSlabClassOf(t *testing.T) {
+	for n, want := range map[int]int{-1: 0, 0: 0, 1: 0, 2: 1, 3: 2, 4: 2, 5: 3, 1000: 10, 1024: 10, 1025: 11} {
+		if got := slabClassOf(n); got != want {
+			t.Errorf("slabClassOf(%d) = %d, want %d", n, got, want)
+		}
+	}
+}
+
+// TestSlabPoolReuse tests that returned buffers are handed out again and
+// counted as hits.
+func TestSlabPoolReuse(t *testing.T) {
+	p := NewSlabPool[int](2)
+	a := p.Get(100)
+	if len(a) != 0 || cap(a) != 128 {
+		t.Fatalf("Get(100): len %d cap %d", len(a), cap(a))
+	}
+	a = append(a, 1, 2, 3)
+	p.Put(a)
+
+	b := p.Get(65)
+	if cap(b) != 128 || &b[:1][0] != &a[:1][0] {
+		t.Fatal("Get(65) did not reuse the returned buffer")
+	}
+	if b[:3][0] != 0 {
+		t.Error("reused buffer was not cleared")
+	}
+	c := p.Get(128)
+	if &c[:1][0] == &b[:1][0] {
+		t.Error("one buffer handed out twice")
+	}
+	p.Put(b)
+	p.Put(c)
+
+	stats := p.Stats()
+	want := PoolStats{Gets: 3, Hits: 1, Misses: 2, Puts: 3}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+	if got := stats.HitRate(); got != 1.0/3 {
+		t.Errorf("hit rate = %v", got)
+	}
+}
+
+// TestSlabPoolDrops tests that the pool bounds what it retains.
+func TestSlabPoolDrops(t *testing.T) {
+	p := NewSlabPool[byte](1)
+	a, b := p.Get(8), p.Get(8)
+	p.Put(a)
+	p.Put(b)
+	if s := p.Stats(); s.Dropped != 1 {
+		t.Errorf("full class: dropped %d, want 1", s.Dropped)
+	}
+	if s := p.Stats(); s.Oversize != 0 {
+		t.Errorf("oversize %d", s.Oversize)
+	}
+	if buf := p.Get(1<<maxSlabClass + 1); cap(buf) != 1<<maxSlabClass+1 {
+		t.Errorf("oversize Get: cap %d", cap(buf))
+	}
+	if s := p.Stats(); s.Oversize != 1 || s.Misses != 3 {
+		t.Errorf("oversize %d misses %d", s.Oversize, s.Misses)
+	}
+}
+
+// TestSlabPoolConcurrent tests concurrent Get and Put under the race
+// detector.
+func TestSlabPoolConcurrent(t *testing.T) {
+	p := NewSlabPool[int](8)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				buf := p.Get(i % 300)
+				for j := 0; j < i%300; j++ {
+					buf = append(buf, g)
+				}
+				for _, v := range buf {
+					if v != g {
+						t.Errorf("buffer shared between goroutines")
+						return
+					}
+				}
+				p.Put(buf)
+			}
+		}(g)
+	}
+	wg.Wait()
+	s := p.Stats()
+	if s.Gets != 8000 || s.Puts != 8000 || s.Hits+s.Misses != s.Gets {
+		t.Errorf("stats = %+v", s)
+	}
+	if s.HitRate() < 0.9 {
+		t.Errorf("hit rate %.2f", s.HitRate())
+	}
+}
+
+// BenchmarkSlabPool compares building an int buffer with append, with a
+// preallocated slice and with a pooled buffer.
+func BenchmarkSlabPool(b *testing.B) {
+	for _, n := range []int{1000, 100_000} {
+		b.Run("dynamicGrowth/n="+strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				s, _ := dynamicGrowth[int](n)
+				sinkLen += len(s)
+			}
+		})
+		b.Run("preallocate/n="+strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				s, _ := preallocate[int](n)
+				sinkLen += len(s)
+			}
+		})
+		b.Run("slabPool/n="+strconv.Itoa(n), func(b *testing.B) {
+			b.ReportAllocs()
+			p := NewSlabPool[int](4)
+			for i := 0; i < b.N; i++ {
+				pooledGrowth(n, p)
+			}
+			b.ReportMetric(p.Stats().HitRate(), "hit-rate")
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// GrowthPolicy decides how much capacity a Vec allocates when it runs out of
+// room. Grow receives the current capacity and the capacity that is needed
+// and returns the new capacity; results smaller than needed are raised to
+// needed, so a policy cannot lose elements.
+type GrowthPolicy interface {
+	Grow(capacity, needed int) int
+}
+
+// GrowthFunc adapts an ordinary function to a GrowthPolicy.
+type GrowthFunc func(capacity, needed int) int
+
+// Grow calls f.
+func (f GrowthFunc) Grow(capacity, needed int) int { return f(capacity, needed) }
+
+// Doubling doubles the capacity, starting from MinCap elements.
+type Doubling struct{ MinCap int }
+
+// Grow implements GrowthPolicy.
+func (d Doubling) Grow(capacity, needed int) int {
+	return max(2*capacity, d.MinCap, needed)
+}
+
+// Factor multiplies the capacity by a constant such as 1.25, always growing
+// by at least one element.
+type Factor float64
+
+// Grow implements GrowthPolicy.
+func (f Factor) Grow(capacity, needed int) int {
+	return max(int(math.Ceil(float64(capacity)*float64(f))), capacity+1, needed)
+}
+
+// FixedIncrement adds a constant number of elements to the capacity.
+type FixedIncrement int
+
+// Grow implements GrowthPolicy.
+func (inc FixedIncrement) Grow(capacity, needed int) int {
+	step := max(int(inc), 1)
+	// Round up to the next multiple of the step above needed.
+	steps := (needed - capacity + step - 1) / step
+	return capacity + max(steps, 1)*step
+}
+
+// Fibonacci grows the capacity through the Fibonacci numbers, a ratio that
+// tends to the golden ratio (about 1.618).
+type Fibonacci struct{}
+
+// Grow implements GrowthPolicy.
+func (Fibonacci) Grow(capacity, needed int) int {
+	a, b := 1, 2
+	for b <= capacity || b < needed {
+		a, b = b, a+b
+	}
+	return b
+}
+
+// Vec is a growable buffer whose reallocation strategy is set by a
+// GrowthPolicy. Unlike append, it never picks its own capacity, so the cost
+// of a policy can be measured in isolation. A Vec is not safe for concurrent
+// use.
+type Vec[T any] struct {
+	data     []T
+	policy   GrowthPolicy
+	reallocs int
+}
+
+// NewVec returns an empty Vec with the given policy and initial capacity. A
+// nil policy defaults to Doubling.
+func NewVec[T any](policy GrowthPolicy, capacity int) *Vec[T] {
+	if policy == nil {
+		policy = Doubling{}
+	}
+	v := &Vec[T]{policy: policy}
+	if capacity > 0 {
+		v.data = make([]T, 0, capacity)
+		v.reallocs = 1
+	}
+	return v
+}
+
+// Len returns the number of elements.
+func (v *Vec[T]) Len() int { return len(v.data) }
+
+// Cap returns the capacity of the backing array.
+func (v *Vec[T]) Cap() int { return cap(v.data) }
+
+// Reallocs returns how many backing arrays the Vec has allocated.
+func (v *Vec[T]) Reallocs() int { return v.reallocs }
+
+// At returns the element at index i. It panics if i is out of range.
+func (v *Vec[T]) At(i int) T { return v.data[i] }
+
+// Set replaces the element at index i. It panics if i is out of range.
+func (v *Vec[T]) Set(i int, x T) { v.data[i] = x }
+
+// Items returns the elements as a slice that shares the Vec's storage; it is
+// only valid until the next call that may reallocate.
+func (v *Vec[T]) Items() []T { return v.data }
+
+// Push appends x.
+func (v *Vec[T]) Push(x T) {
+	if len(v.data) == cap(v.data) {
+		v.grow(len(v.data) + 1)
+	}
+	v.data = append(v.data, x)
+}
+
+// Append appends xs, reallocating at most once.
+func (v *Vec[T]) Append(xs ...T) {
+	if needed := len(v.data) + len(xs); needed > cap(v.data) {
+		v.grow(needed)
+	}
+	v.data = append(v.data, xs...)
+}
+
+// Reserve makes room for at least n more elements without further
+// reallocation. Unlike growth triggered by Push, it allocates exactly the
+// capacity requested.
+func (v *Vec[T]) Reserve(n int) {
+	if n < 0 {
+		panic(fmt.Sprintf("Vec.Reserve: negative count %d", n))
+	}
+	if needed := len(v.data) + n; needed > cap(v.data) {
+		v.realloc(needed)
+	}
+}
+
+// Shrink releases unused capacity, reallocating to exactly Len elements.
+func (v *Vec[T]) Shrink() {
+	switch {
+	case len(v.data) == 0:
+		v.data = nil
+	case cap(v.data) > len(v.data):
+		v.realloc(len(v.data))
+	}
+}
+
+// Truncate drops all elements from index n onwards, keeping the capacity.
+func (v *Vec[T]) Truncate(n int) {
+	if n < 0 || n > len(v.data) {
+		panic(fmt.Sprintf("Vec.Truncate: length %d out of range [0, %d]", n, len(v.data)))
+	}
+	clear(v.data[n:])
+	v.data = v.data[:n]
+}
+
+func (v *Vec[T]) grow(needed int) {
+	v.realloc(max(v.policy.Grow(cap(v.data), needed), needed))
+}
+
+// realloc moves the contents to a new backing array of the given capacity.
+func (v *Vec[T]) realloc(capacity int) {
+	data := make([]T, len(v.data), capacity)
+	copy(data, v.data)
+	v.data = data
+	v.reallocs++
+}
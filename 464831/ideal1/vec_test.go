@@ -0,0 +1,137 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestVecPreservesContents tests that every policy keeps elements in order
+// across reallocations.
+func TestVecPreservesContents(t *testing.T) {
+	policies := map[string]GrowthPolicy{
+		"doubling":  Doubling{},
+		"factor":    Factor(1.25),
+		"fixed":     FixedIncrement(7),
+		"fibonacci": Fibonacci{},
+		"custom":    GrowthFunc(func(capacity, needed int) int { return capacity + 1 }),
+		"shrinking": GrowthFunc(func(capacity, needed int) int { return 0 }),
+	}
+	for name, policy := range policies {
+		v := NewVec[int](policy, 0)
+		for i := 0; i < 1000; i++ {
+			v.Push(i)
+		}
+		v.Append(1000, 1001, 1002)
+		if v.Len() != 1003 {
+			t.Fatalf("%s: len = %d", name, v.Len())
+		}
+		for i := 0; i < v.Len(); i++ {
+			if v.At(i) != i {
+				t.Fatalf("%s: element %d = %d", name, i, v.At(i))
+			}
+		}
+		if v.Reallocs() == 0 {
+			t.Errorf("%s: no reallocations recorded", name)
+		}
+	}
+}
+
+// TestGrowthPolicies tests the capacity sequences each policy produces.
+func TestGrowthPolicies(t *testing.T) {
+	capacities := func(policy GrowthPolicy) []int {
+		v := NewVec[byte](policy, 0)
+		var caps []int
+		for i := 0; i < 40; i++ {
+			v.Push(0)
+			if len(caps) == 0 || caps[len(caps)-1] != v.Cap() {
+				caps = append(caps, v.Cap())
+			}
+		}
+		return caps
+	}
+	tests := []struct {
+		name   string
+		policy GrowthPolicy
+		want   []int
+	}{
+		{"doubling", Doubling{MinCap: 4}, []int{4, 8, 16, 32, 64}},
+		{"factor", Factor(1.5), []int{1, 2, 3, 5, 8, 12, 18, 27, 41}},
+		{"fixed", FixedIncrement(16), []int{16, 32, 48}},
+		{"fibonacci", Fibonacci{}, []int{2, 3, 5, 8, 13, 21, 34, 55}},
+	}
+	for _, tt := range tests {
+		if got := capacities(tt.policy); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: capacities %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestVecReserveAndShrink tests explicit capacity management.
+func TestVecReserveAndShrink(t *testing.T) {
+	v := NewVec[int](FixedIncrement(1), 0)
+	v.Reserve(100)
+	if v.Cap() != 100 || v.Reallocs() != 1 {
+		t.Fatalf("after Reserve: cap %d reallocs %d", v.Cap(), v.Reallocs())
+	}
+	for i := 0; i < 100; i++ {
+		v.Push(i)
+	}
+	if v.Reallocs() != 1 {
+		t.Errorf("Push within reserved capacity reallocated: %d", v.Reallocs())
+	}
+	v.Reserve(0)
+	if v.Reallocs() != 1 {
+		t.Error("Reserve(0) reallocated")
+	}
+
+	v.Truncate(10)
+	v.Shrink()
+	if v.Cap() != 10 || v.Len() != 10 || v.At(9) != 9 {
+		t.Errorf("after Shrink: len %d cap %d", v.Len(), v.Cap())
+	}
+	v.Truncate(0)
+	v.Shrink()
+	if v.Cap() != 0 {
+		t.Errorf("empty Shrink left cap %d", v.Cap())
+	}
+}
+
+// TestVecPanics tests argument checks.
+func TestVecPanics(t *testing.T) {
+	expectPanic := func(name string, f func()) {
+		t.Helper()
+		defer func() {
+			if recover() == nil {
+				t.Errorf("%s did not panic", name)
+			}
+		}()
+		f()
+	}
+	v := NewVec[int](nil, 2)
+	v.Push(1)
+	expectPanic("Reserve(-1)", func() { v.Reserve(-1) })
+	expectPanic("Truncate(2)", func() { v.Truncate(2) })
+	expectPanic("At(1)", func() { v.At(1) })
+}
+
+// BenchmarkVecPush compares policies against append for 100k ints.
+func BenchmarkVecPush(b *testing.B) {
+	policies := []struct {
+		name   string
+		policy GrowthPolicy
+	}{
+		{"doubling", Doubling{MinCap: 8}},
+		{"1.25x", Factor(1.25)},
+		{"fixed1000", FixedIncrement(1000)},
+		{"fibonacci", Fibonacci{}},
+	}
+	for _, p := range policies {
+		b.Run(p.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				s, _ := vecGrowth[int](100_000, p.policy)
+				sinkLen += len(s)
+			}
+		})
+	}
+}
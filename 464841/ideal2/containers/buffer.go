@@ -0,0 +1,74 @@
+package containers
+
+import (
+	"context"
+	"runtime/trace"
+	"time"
+)
+
+// ThreadSafeBuffer is a thread-safe buffer using a channel
+type ThreadSafeBuffer struct {
+	buf     chan int
+	metrics *Metrics
+}
+
+// NewThreadSafeBuffer creates a new ThreadSafeBuffer with a specified capacity
+func NewThreadSafeBuffer(capacity int) *ThreadSafeBuffer {
+	return &ThreadSafeBuffer{buf: make(chan int, capacity)}
+}
+
+// Instrument records in m how often Write found the buffer full, how long it
+// waited, and the buffer length after each operation. It must be called
+// before the buffer is shared between goroutines.
+func (tsb *ThreadSafeBuffer) Instrument(m *Metrics) {
+	m.kind = "queue"
+	m.Occupancy = newHistogram(occupancyBounds(cap(tsb.buf)))
+	tsb.metrics = m
+}
+
+// Write appends an element to the buffer
+func (tsb *ThreadSafeBuffer) Write(val int) {
+	m := tsb.metrics
+	if m == nil {
+		tsb.buf <- val
+		return
+	}
+	m.Operations.Add(1)
+	select {
+	case tsb.buf <- val:
+		m.Wait.Observe(0)
+	default:
+		m.Contended.Add(1)
+		start := time.Now()
+		var region *trace.Region
+		if trace.IsEnabled() {
+			region = trace.StartRegion(context.Background(), m.waitRegion)
+		}
+		tsb.buf <- val
+		if region != nil {
+			region.End()
+		}
+		m.Wait.Observe(int64(time.Since(start)))
+	}
+	tsb.observeLen()
+}
+
+// Read retrieves an element from the buffer
+func (tsb *ThreadSafeBuffer) Read() (int, bool) {
+	select {
+	case val, ok := <-tsb.buf:
+		if tsb.metrics != nil && ok {
+			tsb.metrics.Operations.Add(1)
+			tsb.observeLen()
+		}
+		return val, ok
+	default:
+		return 0, false
+	}
+}
+
+func (tsb *ThreadSafeBuffer) observeLen() {
+	n := len(tsb.buf)
+	tsb.metrics.length.Store(int64(n))
+	tsb.metrics.Occupancy.Observe(int64(n))
+}
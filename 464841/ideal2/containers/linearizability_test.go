@@ -0,0 +1,185 @@
+package containers
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"example.com/464841/ideal2/lincheck"
+)
+
+// Each check runs linRounds short histories rather than one long one: the
+// search is exponential in the number of overlapping operations, and many
+// fresh starts explore more interleavings.
+const (
+	linRounds  = 50
+	linClients = 4
+	linOps     = 30
+)
+
+// checkHistory fails t with the checker's explanation if h is not
+// linearizable.
+func checkHistory[S, I, O any](t *testing.T, m lincheck.Model[S, I, O], h []lincheck.Operation[I, O]) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	r, err := lincheck.Check(ctx, m, h)
+	if err != nil {
+		t.Fatalf("checking %d operations: %v", len(h), err)
+	}
+	if !r.Linearizable {
+		t.Fatal(lincheck.Explain(m, h, r))
+	}
+}
+
+// basicSlice is implemented by every slice whose Append, Get and Len should
+// be linearizable.
+type basicSlice interface {
+	Append(val int)
+	Get(index int) (int, bool)
+	Len() int
+}
+
+func doBasic(s basicSlice) func(lincheck.SliceInput) lincheck.SliceOutput {
+	return func(in lincheck.SliceInput) lincheck.SliceOutput {
+		switch in.Op {
+		case lincheck.Append:
+			s.Append(in.Value)
+		case lincheck.Get:
+			v, ok := s.Get(in.Index)
+			return lincheck.SliceOutput{Value: v, OK: ok}
+		case lincheck.Len:
+			return lincheck.SliceOutput{Value: s.Len()}
+		}
+		return lincheck.SliceOutput{}
+	}
+}
+
+// TestSliceLinearizable tests Append, Get and Len on each slice variant.
+// ShardedSlice is left out: a reader may see index i unset while Len already
+// counts it, which its documentation allows.
+func TestSliceLinearizable(t *testing.T) {
+	variants := []struct {
+		name string
+		new  func() basicSlice
+	}{
+		{"MutexSlice", func() basicSlice { return NewMutexSlice(0) }},
+		{"SafeSlice", func() basicSlice { return NewSafeSlice(0) }},
+		{"SnapshotSlice", func() basicSlice { return NewSnapshotSlice(0) }},
+	}
+	for _, v := range variants {
+		t.Run(v.name, func(t *testing.T) {
+			for round := range linRounds {
+				do := doBasic(v.new())
+				rec := lincheck.NewRecorder[lincheck.SliceInput, lincheck.SliceOutput](linClients)
+				rec.Run(func(id int) {
+					rng := rand.New(rand.NewSource(int64(round*linClients + id)))
+					for i := range linOps {
+						in := lincheck.SliceInput{Op: lincheck.SliceOp(rng.Intn(3)), Index: rng.Intn(8), Value: id*1000 + i}
+						rec.Do(id, in, do)
+					}
+				})
+				checkHistory(t, lincheck.SliceModel(), rec.History())
+			}
+		})
+	}
+}
+
+// TestSafeSliceLinearizable tests SafeSlice's positional operations mixed
+// with appends and reads.
+func TestSafeSliceLinearizable(t *testing.T) {
+	for round := range linRounds {
+		ss := NewSafeSlice(0)
+		basic := doBasic(ss)
+		do := func(in lincheck.SliceInput) lincheck.SliceOutput {
+			switch in.Op {
+			case lincheck.Insert:
+				return lincheck.SliceOutput{OK: ss.Insert(in.Index, in.Value)}
+			case lincheck.RemoveAt:
+				v, ok := ss.RemoveAt(in.Index)
+				return lincheck.SliceOutput{Value: v, OK: ok}
+			}
+			return basic(in)
+		}
+		rec := lincheck.NewRecorder[lincheck.SliceInput, lincheck.SliceOutput](linClients)
+		rec.Run(func(id int) {
+			rng := rand.New(rand.NewSource(int64(round*linClients + id)))
+			for i := range linOps {
+				in := lincheck.SliceInput{Op: lincheck.SliceOp(rng.Intn(5)), Index: rng.Intn(6), Value: id*1000 + i}
+				rec.Do(id, in, do)
+			}
+		})
+		checkHistory(t, lincheck.SliceModel(), rec.History())
+	}
+}
+
+// TestThreadSafeBufferLinearizable tests blocking writes and non-blocking
+// reads. Each client keeps at most two of its writes unread by itself and the
+// buffer has room for all of them, so no write blocks forever.
+func TestThreadSafeBufferLinearizable(t *testing.T) {
+	const capacity = 2 * linClients
+	for round := range linRounds {
+		tsb := NewThreadSafeBuffer(capacity)
+		do := func(in lincheck.QueueInput) lincheck.QueueOutput {
+			if in.Op == lincheck.Enqueue {
+				tsb.Write(in.Value)
+				return lincheck.QueueOutput{OK: true}
+			}
+			v, ok := tsb.Read()
+			return lincheck.QueueOutput{Value: v, OK: ok}
+		}
+		rec := lincheck.NewRecorder[lincheck.QueueInput, lincheck.QueueOutput](linClients)
+		rec.Run(func(id int) {
+			rng := rand.New(rand.NewSource(int64(round*linClients + id)))
+			pending := 0
+			for i := range linOps {
+				if pending == 0 || pending < 2 && rng.Intn(2) == 0 {
+					rec.Do(id, lincheck.QueueInput{Op: lincheck.Enqueue, Value: id*1000 + i}, do)
+					pending++
+					continue
+				}
+				if rec.Do(id, lincheck.QueueInput{Op: lincheck.Dequeue}, do).OK {
+					pending--
+				}
+			}
+		})
+		checkHistory(t, lincheck.QueueModel(capacity), rec.History())
+	}
+}
+
+// TestRingBufferLinearizable tests TryWrite and the blocking Read. TryRead
+// and TryWrite's ErrFull are left out: they can report empty or full while
+// an overlapping operation on an earlier slot is still in progress, even
+// after a later one has completed, which no sequential queue explains.
+func TestRingBufferLinearizable(t *testing.T) {
+	for round := range linRounds {
+		rb := NewRingBuffer[int](linClients * linOps)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		do := func(in lincheck.QueueInput) lincheck.QueueOutput {
+			if in.Op == lincheck.Enqueue {
+				return lincheck.QueueOutput{OK: rb.TryWrite(in.Value) == nil}
+			}
+			v, err := rb.Read(ctx)
+			return lincheck.QueueOutput{Value: v, OK: err == nil}
+		}
+		rec := lincheck.NewRecorder[lincheck.QueueInput, lincheck.QueueOutput](linClients)
+		rec.Run(func(id int) {
+			rng := rand.New(rand.NewSource(int64(round*linClients + id)))
+			// Every client reads no more than it has written, so each
+			// blocking Read is eventually satisfied.
+			pending := 0
+			for i := range linOps {
+				if pending == 0 || rng.Intn(2) == 0 {
+					rec.Do(id, lincheck.QueueInput{Op: lincheck.Enqueue, Value: id*1000 + i}, do)
+					pending++
+					continue
+				}
+				rec.Do(id, lincheck.QueueInput{Op: lincheck.Dequeue}, do)
+				pending--
+			}
+		})
+		cancel()
+		checkHistory(t, lincheck.QueueModel(0), rec.History())
+	}
+}
@@ -0,0 +1,214 @@
+package containers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"runtime/trace"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// durationBounds are the upper bounds, in nanoseconds, of the wait and hold
+// time histogram buckets.
+var durationBounds = []int64{
+	100, 1_000, 10_000, 100_000, 1_000_000, 10_000_000, 100_000_000, 1_000_000_000,
+}
+
+// Histogram counts observations into cumulative buckets, Prometheus style.
+// Values are integers in the histogram's unit (nanoseconds or elements).
+type Histogram struct {
+	bounds []int64
+	counts []atomic.Uint64 // counts[i] observations <= bounds[i]; last is +Inf
+	sum    atomic.Int64
+	count  atomic.Uint64
+}
+
+func newHistogram(bounds []int64) *Histogram {
+	return &Histogram{bounds: bounds, counts: make([]atomic.Uint64, len(bounds)+1)}
+}
+
+// Observe records v.
+func (h *Histogram) Observe(v int64) {
+	i := 0
+	for i < len(h.bounds) && v > h.bounds[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.sum.Add(v)
+	h.count.Add(1)
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 { return h.count.Load() }
+
+// Sum returns the total of all observations.
+func (h *Histogram) Sum() int64 { return h.sum.Load() }
+
+// Metrics records how a container behaves under contention. Attach one with a
+// container's Instrument method; containers without Metrics skip all of this
+// bookkeeping.
+type Metrics struct {
+	name string
+	kind string // "lock" or "queue"
+
+	// Wait is the time callers spent blocked: waiting for a lock, or for room
+	// in a full queue.
+	Wait *Histogram
+	// Hold is how long locks were held. Only locks record it.
+	Hold *Histogram
+	// Occupancy is the queue length seen after each operation. Only queues
+	// record it.
+	Occupancy *Histogram
+
+	Operations atomic.Uint64 // lock acquisitions or queue operations
+	Contended  atomic.Uint64 // operations that could not proceed at once
+	length     atomic.Int64  // current queue length
+
+	waitRegion, holdRegion string
+}
+
+// NewMetrics returns empty metrics labelled with name.
+func NewMetrics(name string) *Metrics {
+	return &Metrics{
+		name:       name,
+		Wait:       newHistogram(durationBounds),
+		Hold:       newHistogram(durationBounds),
+		waitRegion: name + ".wait",
+		holdRegion: name + ".hold",
+	}
+}
+
+// Name returns the label the metrics are exported under.
+func (m *Metrics) Name() string { return m.name }
+
+// tryLocker is satisfied by *sync.Mutex, *sync.RWMutex and readLocker.
+type tryLocker interface {
+	TryLock() bool
+	Lock()
+}
+
+// readLocker exposes the read side of an RWMutex as a tryLocker.
+type readLocker sync.RWMutex
+
+func (l *readLocker) TryLock() bool { return (*sync.RWMutex)(l).TryRLock() }
+func (l *readLocker) Lock()         { (*sync.RWMutex)(l).RLock() }
+
+// lockToken carries what an instrumented unlock needs from the lock.
+type lockToken struct {
+	acquired time.Time
+	region   *trace.Region
+}
+
+// acquire locks l, recording whether it was contended and how long the wait
+// took. While an execution trace is running, the wait and the critical
+// section appear as regions named after the metrics.
+func (m *Metrics) acquire(l tryLocker) lockToken {
+	m.Operations.Add(1)
+	tracing := trace.IsEnabled()
+	if !l.TryLock() {
+		m.Contended.Add(1)
+		start := time.Now()
+		var wait *trace.Region
+		if tracing {
+			wait = trace.StartRegion(context.Background(), m.waitRegion)
+		}
+		l.Lock()
+		if wait != nil {
+			wait.End()
+		}
+		m.Wait.Observe(int64(time.Since(start)))
+	} else {
+		m.Wait.Observe(0)
+	}
+	t := lockToken{acquired: time.Now()}
+	if tracing {
+		t.region = trace.StartRegion(context.Background(), m.holdRegion)
+	}
+	return t
+}
+
+// release records the hold time of a lock about to be unlocked.
+func (m *Metrics) release(t lockToken) {
+	if t.region != nil {
+		t.region.End()
+	}
+	m.Hold.Observe(int64(time.Since(t.acquired)))
+}
+
+// occupancyBounds returns bucket bounds 0, 1, 2, 4, ... up to capacity.
+func occupancyBounds(capacity int) []int64 {
+	bounds := []int64{0}
+	for b := int64(1); b < int64(capacity); b *= 2 {
+		bounds = append(bounds, b)
+	}
+	return append(bounds, int64(capacity))
+}
+
+// WritePrometheus writes metrics in the Prometheus text exposition format,
+// one series per container labelled container="<name>".
+func WritePrometheus(w io.Writer, metrics ...*Metrics) error {
+	bw := bufio.NewWriter(w)
+	family := func(name, typ, help string, each func(m *Metrics, labels string)) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+		for _, m := range metrics {
+			each(m, `container="`+escapeLabel(m.name)+`"`)
+		}
+	}
+	// Values are divided by perUnit, e.g. 1e9 to turn nanoseconds into seconds.
+	histogram := func(name string, h *Histogram, labels string, perUnit float64) {
+		var cumulative uint64
+		for i := range h.counts {
+			cumulative += h.counts[i].Load()
+			le := "+Inf"
+			if i < len(h.bounds) {
+				le = formatFloat(float64(h.bounds[i]) / perUnit)
+			}
+			fmt.Fprintf(bw, "%s_bucket{%s,le=%q} %d\n", name, labels, le, cumulative)
+		}
+		fmt.Fprintf(bw, "%s_sum{%s} %s\n", name, labels, formatFloat(float64(h.Sum())/perUnit))
+		fmt.Fprintf(bw, "%s_count{%s} %d\n", name, labels, h.Count())
+	}
+
+	family("containers_operations_total", "counter", "Lock acquisitions or queue operations.", func(m *Metrics, l string) {
+		fmt.Fprintf(bw, "containers_operations_total{%s} %d\n", l, m.Operations.Load())
+	})
+	family("containers_contended_total", "counter", "Operations that had to wait for a lock or for queue space.", func(m *Metrics, l string) {
+		fmt.Fprintf(bw, "containers_contended_total{%s} %d\n", l, m.Contended.Load())
+	})
+	family("containers_wait_seconds", "histogram", "Time spent blocked before an operation could proceed.", func(m *Metrics, l string) {
+		histogram("containers_wait_seconds", m.Wait, l, 1e9)
+	})
+	family("containers_lock_hold_seconds", "histogram", "Time a lock was held.", func(m *Metrics, l string) {
+		if m.kind == "lock" {
+			histogram("containers_lock_hold_seconds", m.Hold, l, 1e9)
+		}
+	})
+	family("containers_queue_length", "gauge", "Current number of queued elements.", func(m *Metrics, l string) {
+		if m.kind == "queue" {
+			fmt.Fprintf(bw, "containers_queue_length{%s} %d\n", l, m.length.Load())
+		}
+	})
+	family("containers_queue_occupancy", "histogram", "Queue length observed after each operation.", func(m *Metrics, l string) {
+		if m.kind == "queue" {
+			histogram("containers_queue_occupancy", m.Occupancy, l, 1)
+		}
+	})
+	return bw.Flush()
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
@@ -0,0 +1,137 @@
+package containers
+
+import (
+	"bytes"
+	"runtime/trace"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestHistogram tests bucket placement and totals.
+func TestHistogram(t *testing.T) {
+	h := newHistogram([]int64{10, 100})
+	for _, v := range []int64{0, 10, 11, 100, 1000} {
+		h.Observe(v)
+	}
+	want := []uint64{2, 2, 1}
+	for i := range want {
+		if got := h.counts[i].Load(); got != want[i] {
+			t.Errorf("bucket %d = %d, want %d", i, got, want[i])
+		}
+	}
+	if h.Count() != 5 || h.Sum() != 1121 {
+		t.Errorf("count %d sum %d", h.Count(), h.Sum())
+	}
+}
+
+// TestSafeSliceMetrics tests that a contended Append is counted and its wait
+// and hold times recorded, also while an execution trace is running.
+func TestSafeSliceMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Fatal(err)
+	}
+	defer trace.Stop()
+
+	m := NewMetrics("slice")
+	ss := NewSafeSlice(0)
+	ss.Instrument(m)
+	ss.Append(1)
+
+	ss.mu.Lock()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ss.Append(2)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	ss.mu.Unlock()
+	wg.Wait()
+	if v, ok := ss.Get(1); !ok || v != 2 {
+		t.Fatalf("Get(1) = %d, %v", v, ok)
+	}
+
+	if got := m.Operations.Load(); got != 3 {
+		t.Errorf("operations = %d, want 3", got)
+	}
+	if got := m.Contended.Load(); got != 1 {
+		t.Errorf("contended = %d, want 1", got)
+	}
+	if m.Wait.Count() != 3 || time.Duration(m.Wait.Sum()) < 10*time.Millisecond {
+		t.Errorf("wait: count %d sum %v", m.Wait.Count(), time.Duration(m.Wait.Sum()))
+	}
+	if m.Hold.Count() != 3 {
+		t.Errorf("hold count = %d, want 3", m.Hold.Count())
+	}
+}
+
+// TestThreadSafeBufferMetrics tests full-buffer waits and occupancy.
+func TestThreadSafeBufferMetrics(t *testing.T) {
+	m := NewMetrics("buffer")
+	tsb := NewThreadSafeBuffer(2)
+	tsb.Instrument(m)
+	tsb.Write(1)
+	tsb.Write(2)
+	done := make(chan struct{})
+	go func() {
+		tsb.Write(3) // blocks until a read makes room
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	tsb.Read()
+	<-done
+	tsb.Read()
+
+	if got := m.Contended.Load(); got != 1 {
+		t.Errorf("contended = %d, want 1", got)
+	}
+	if got := m.Operations.Load(); got != 5 {
+		t.Errorf("operations = %d, want 5", got)
+	}
+	if m.Occupancy.Count() != 5 || m.length.Load() != 1 {
+		t.Errorf("occupancy count %d, length %d", m.Occupancy.Count(), m.length.Load())
+	}
+	if time.Duration(m.Wait.Sum()) < 10*time.Millisecond {
+		t.Errorf("wait sum %v", time.Duration(m.Wait.Sum()))
+	}
+}
+
+// TestWritePrometheus tests the exposition format.
+func TestWritePrometheus(t *testing.T) {
+	lock := NewMetrics(`slice "a"`)
+	NewSafeSlice(0).Instrument(lock)
+	lock.Operations.Add(3)
+	lock.Wait.Observe(int64(50 * time.Microsecond))
+	queue := NewMetrics("buffer")
+	NewThreadSafeBuffer(4).Instrument(queue)
+	queue.Occupancy.Observe(3)
+
+	var sb strings.Builder
+	if err := WritePrometheus(&sb, lock, queue); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	for _, want := range []string{
+		"# TYPE containers_wait_seconds histogram\n",
+		`containers_operations_total{container="slice \"a\""} 3`,
+		`containers_wait_seconds_bucket{container="slice \"a\"",le="1e-05"} 0`,
+		`containers_wait_seconds_bucket{container="slice \"a\"",le="0.0001"} 1`,
+		`containers_wait_seconds_sum{container="slice \"a\""} 5e-05`,
+		`containers_queue_occupancy_bucket{container="buffer",le="2"} 0`,
+		`containers_queue_occupancy_bucket{container="buffer",le="4"} 1`,
+		`containers_queue_occupancy_bucket{container="buffer",le="+Inf"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q", want)
+		}
+	}
+	if strings.Contains(out, `containers_lock_hold_seconds_count{container="buffer"}`) {
+		t.Error("queue exported lock hold times")
+	}
+	if strings.Contains(out, `containers_queue_length{container="slice`) {
+		t.Error("lock exported a queue length")
+	}
+}
@@ -0,0 +1,334 @@
+package containers
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RejectPolicy decides what a bounded PriorityQueue does with a Push when it
+// is full.
+type RejectPolicy int
+
+const (
+	// RejectNew fails the Push with ErrFull.
+	RejectNew RejectPolicy = iota
+	// DropLowest evicts the lowest-priority element to make room, or rejects
+	// the new element with ErrFull if it is the lowest itself.
+	DropLowest
+	// BlockWhenFull makes Push wait for room; TryPush still fails with
+	// ErrFull.
+	BlockWhenFull
+)
+
+func (p RejectPolicy) String() string {
+	switch p {
+	case RejectNew:
+		return "reject-new"
+	case DropLowest:
+		return "drop-lowest"
+	case BlockWhenFull:
+		return "block"
+	}
+	return fmt.Sprintf("RejectPolicy(%d)", int(p))
+}
+
+// PriorityQueueOptions configures a PriorityQueue.
+type PriorityQueueOptions[T any] struct {
+	// Capacity bounds the number of queued elements; zero means unbounded.
+	Capacity int
+	Policy   RejectPolicy
+	// MaxWait enables aging: an element queued for longer than MaxWait is
+	// served before any element that is not, oldest first, so low-priority
+	// work cannot starve. Zero disables aging.
+	MaxWait time.Duration
+	// OnDrop, if set, receives elements evicted by DropLowest. It is called
+	// without the queue's lock held.
+	OnDrop func(T)
+
+	now func() time.Time // for tests
+}
+
+type pqItem[T any] struct {
+	val      T
+	seq      uint64
+	enqueued time.Time
+	index    int // position in the heap, -1 once removed
+}
+
+// pqHeap orders items by the comparator, then by arrival.
+type pqHeap[T any] struct {
+	items []*pqItem[T]
+	less  func(a, b T) bool
+}
+
+func (h *pqHeap[T]) Len() int { return len(h.items) }
+func (h *pqHeap[T]) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if h.less(a.val, b.val) {
+		return true
+	}
+	if h.less(b.val, a.val) {
+		return false
+	}
+	return a.seq < b.seq
+}
+func (h *pqHeap[T]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+func (h *pqHeap[T]) Push(x any) {
+	it := x.(*pqItem[T])
+	it.index = len(h.items)
+	h.items = append(h.items, it)
+}
+func (h *pqHeap[T]) Pop() any {
+	last := len(h.items) - 1
+	it := h.items[last]
+	h.items[last] = nil
+	h.items = h.items[:last]
+	it.index = -1
+	return it
+}
+
+// PriorityQueue is a concurrent priority queue. Pop returns the element that
+// sorts first under the comparator, with ties and aged elements served in
+// arrival order. Push and Pop block, subject to a context, when the queue is
+// full (with BlockWhenFull) or empty. A PriorityQueue is safe for concurrent
+// use.
+type PriorityQueue[T any] struct {
+	mu     sync.Mutex
+	heap   pqHeap[T]
+	fifo   []*pqItem[T] // arrival order, with removed items skipped lazily
+	seq    uint64
+	opts   PriorityQueueOptions[T]
+	closed bool
+
+	done        chan struct{}
+	notEmpty    chan struct{}
+	notFull     chan struct{}
+	popWaiters  int
+	pushWaiters int
+}
+
+// NewPriorityQueue returns an empty queue in which a is served before b when
+// less(a, b).
+func NewPriorityQueue[T any](less func(a, b T) bool, opts PriorityQueueOptions[T]) *PriorityQueue[T] {
+	if opts.now == nil {
+		opts.now = time.Now
+	}
+	return &PriorityQueue[T]{
+		heap:     pqHeap[T]{less: less},
+		opts:     opts,
+		done:     make(chan struct{}),
+		notEmpty: make(chan struct{}, 1),
+		notFull:  make(chan struct{}, 1),
+	}
+}
+
+// Len returns the number of queued elements.
+func (q *PriorityQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.heap.Len()
+}
+
+// Cap returns the capacity, or 0 if the queue is unbounded.
+func (q *PriorityQueue[T]) Cap() int { return q.opts.Capacity }
+
+// TryPush adds val without blocking. On a full queue it applies the reject
+// policy, treating BlockWhenFull like RejectNew.
+func (q *PriorityQueue[T]) TryPush(val T) error {
+	dropped, ok, err := q.push(val, false)
+	if ok && q.opts.OnDrop != nil {
+		q.opts.OnDrop(dropped)
+	}
+	return err
+}
+
+// Push adds val. On a full queue with BlockWhenFull it waits for room until
+// ctx is done; the other policies behave as in TryPush.
+func (q *PriorityQueue[T]) Push(ctx context.Context, val T) error {
+	for {
+		dropped, ok, err := q.push(val, true)
+		if ok && q.opts.OnDrop != nil {
+			q.opts.OnDrop(dropped)
+		}
+		if err != errMustWait {
+			return err
+		}
+		// Leave any wake-up token for another waiter when giving up.
+		var ctxErr error
+		select {
+		case <-q.notFull:
+		case <-q.done:
+		case <-ctx.Done():
+			ctxErr = ctx.Err()
+		}
+		q.mu.Lock()
+		q.pushWaiters--
+		q.mu.Unlock()
+		if ctxErr != nil {
+			return ctxErr
+		}
+	}
+}
+
+// errMustWait tells Push and Pop to wait; it never escapes the package.
+var errMustWait = errors.New("must wait")
+
+// push adds val under the lock. If the caller may wait and must, it is
+// registered as a waiter before the lock is released.
+func (q *PriorityQueue[T]) push(val T, wait bool) (dropped T, didDrop bool, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return dropped, false, ErrClosed
+	}
+	if q.opts.Capacity > 0 && q.heap.Len() >= q.opts.Capacity {
+		switch {
+		case q.opts.Policy == BlockWhenFull && wait:
+			q.pushWaiters++
+			return dropped, false, errMustWait
+		case q.opts.Policy == DropLowest:
+			lowest := q.lowest()
+			if !q.heap.less(val, q.heap.items[lowest].val) {
+				return dropped, false, ErrFull
+			}
+			dropped = heap.Remove(&q.heap, lowest).(*pqItem[T]).val
+			didDrop = true
+		default:
+			return dropped, false, ErrFull
+		}
+	}
+	q.seq++
+	it := &pqItem[T]{val: val, seq: q.seq, enqueued: q.opts.now()}
+	heap.Push(&q.heap, it)
+	if q.opts.MaxWait > 0 {
+		q.fifo = append(q.fifo, it)
+	}
+	if q.popWaiters > 0 {
+		signal(q.notEmpty)
+	}
+	if q.pushWaiters > 0 && (q.opts.Capacity == 0 || q.heap.Len() < q.opts.Capacity) {
+		signal(q.notFull)
+	}
+	return dropped, didDrop, nil
+}
+
+// lowest returns the heap index of the element served last. It is always a
+// leaf, so only the second half of the heap is scanned.
+func (q *PriorityQueue[T]) lowest() int {
+	n := q.heap.Len()
+	worst := n / 2
+	for i := worst + 1; i < n; i++ {
+		if q.heap.Less(worst, i) {
+			worst = i
+		}
+	}
+	return worst
+}
+
+// TryPop removes the next element without blocking. It returns ErrEmpty if
+// there is none, or ErrClosed if the queue is closed and drained.
+func (q *PriorityQueue[T]) TryPop() (T, error) {
+	return q.pop(false)
+}
+
+// Pop removes the next element, waiting until one is available or ctx is
+// done. After Close it keeps returning queued elements, then ErrClosed.
+func (q *PriorityQueue[T]) Pop(ctx context.Context) (T, error) {
+	for {
+		val, err := q.pop(true)
+		if err != errMustWait {
+			return val, err
+		}
+		var ctxErr error
+		select {
+		case <-q.notEmpty:
+		case <-q.done:
+		case <-ctx.Done():
+			ctxErr = ctx.Err()
+		}
+		q.mu.Lock()
+		q.popWaiters--
+		q.mu.Unlock()
+		if ctxErr != nil {
+			return val, ctxErr
+		}
+	}
+}
+
+func (q *PriorityQueue[T]) pop(wait bool) (T, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var zero T
+	if q.heap.Len() == 0 {
+		switch {
+		case q.closed:
+			return zero, ErrClosed
+		case wait:
+			q.popWaiters++
+			return zero, errMustWait
+		}
+		return zero, ErrEmpty
+	}
+	it := q.next()
+	heap.Remove(&q.heap, it.index)
+	if q.pushWaiters > 0 {
+		signal(q.notFull)
+	}
+	// Pass the wake-up on if another waiter can make progress.
+	if q.popWaiters > 0 && q.heap.Len() > 0 {
+		signal(q.notEmpty)
+	}
+	return it.val, nil
+}
+
+// next returns the item to serve: the oldest one if it has waited longer
+// than MaxWait, otherwise the heap's first.
+func (q *PriorityQueue[T]) next() *pqItem[T] {
+	if q.opts.MaxWait <= 0 {
+		return q.heap.items[0]
+	}
+	// Drop items already served by priority from the front of the FIFO.
+	i := 0
+	for i < len(q.fifo) && q.fifo[i].index < 0 {
+		i++
+	}
+	clear(q.fifo[:i])
+	q.fifo = q.fifo[i:]
+	if len(q.fifo) > 2*q.heap.Len()+32 {
+		q.compactFIFO()
+	}
+	if oldest := q.fifo[0]; q.opts.now().Sub(oldest.enqueued) > q.opts.MaxWait {
+		return oldest
+	}
+	return q.heap.items[0]
+}
+
+func (q *PriorityQueue[T]) compactFIFO() {
+	live := q.fifo[:0]
+	for _, it := range q.fifo {
+		if it.index >= 0 {
+			live = append(live, it)
+		}
+	}
+	clear(q.fifo[len(live):])
+	q.fifo = live
+}
+
+// Close stops further pushes and wakes all blocked callers. Queued elements
+// can still be popped. Close is idempotent.
+func (q *PriorityQueue[T]) Close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.closed {
+		q.closed = true
+		close(q.done)
+	}
+}
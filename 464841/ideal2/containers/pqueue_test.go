@@ -0,0 +1,262 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+type job struct {
+	name     string
+	priority int
+}
+
+func byPriority(a, b job) bool { return a.priority > b.priority }
+
+func popAll(t *testing.T, q *PriorityQueue[job]) []string {
+	t.Helper()
+	var names []string
+	for {
+		j, err := q.TryPop()
+		if err == ErrEmpty {
+			return names
+		}
+		if err != nil {
+			t.Fatalf("TryPop: %v", err)
+		}
+		names = append(names, j.name)
+	}
+}
+
+// TestPriorityQueueOrder tests comparator order with ties served in arrival
+// order.
+func TestPriorityQueueOrder(t *testing.T) {
+	q := NewPriorityQueue(byPriority, PriorityQueueOptions[job]{})
+	for _, j := range []job{{"a", 1}, {"b", 5}, {"c", 1}, {"d", 3}, {"e", 5}} {
+		if err := q.TryPush(j); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if q.Len() != 5 || q.Cap() != 0 {
+		t.Errorf("Len %d Cap %d", q.Len(), q.Cap())
+	}
+	if got := popAll(t, q); !slices.Equal(got, []string{"b", "e", "d", "a", "c"}) {
+		t.Errorf("order = %v", got)
+	}
+}
+
+// TestPriorityQueuePolicies tests the reject policies of a full queue.
+func TestPriorityQueuePolicies(t *testing.T) {
+	q := NewPriorityQueue(byPriority, PriorityQueueOptions[job]{Capacity: 2, Policy: RejectNew})
+	q.TryPush(job{"a", 1})
+	q.TryPush(job{"b", 2})
+	if err := q.TryPush(job{"c", 9}); err != ErrFull {
+		t.Errorf("RejectNew: %v", err)
+	}
+
+	var dropped []string
+	q = NewPriorityQueue(byPriority, PriorityQueueOptions[job]{
+		Capacity: 3, Policy: DropLowest,
+		OnDrop: func(j job) { dropped = append(dropped, j.name) },
+	})
+	for _, j := range []job{{"a", 2}, {"b", 1}, {"c", 3}} {
+		q.TryPush(j)
+	}
+	if err := q.TryPush(job{"d", 4}); err != nil {
+		t.Errorf("DropLowest push: %v", err)
+	}
+	if err := q.TryPush(job{"e", 0}); err != ErrFull {
+		t.Errorf("DropLowest push of the lowest: %v", err)
+	}
+	if !slices.Equal(dropped, []string{"b"}) {
+		t.Errorf("dropped %v", dropped)
+	}
+	if got := popAll(t, q); !slices.Equal(got, []string{"d", "c", "a"}) {
+		t.Errorf("order = %v", got)
+	}
+
+	q = NewPriorityQueue(byPriority, PriorityQueueOptions[job]{Capacity: 1, Policy: BlockWhenFull})
+	q.TryPush(job{"a", 1})
+	if err := q.TryPush(job{"b", 1}); err != ErrFull {
+		t.Errorf("BlockWhenFull TryPush: %v", err)
+	}
+	errc := make(chan error)
+	go func() { errc <- q.Push(context.Background(), job{"b", 1}) }()
+	time.Sleep(10 * time.Millisecond)
+	if j, _ := q.TryPop(); j.name != "a" {
+		t.Errorf("popped %q", j.name)
+	}
+	if err := <-errc; err != nil {
+		t.Errorf("blocked Push: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := q.Push(ctx, job{"c", 1}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Push on full queue: %v", err)
+	}
+}
+
+// TestPriorityQueueAging tests that an element waiting longer than MaxWait
+// overtakes higher-priority ones.
+func TestPriorityQueueAging(t *testing.T) {
+	now := time.Unix(0, 0)
+	q := NewPriorityQueue(byPriority, PriorityQueueOptions[job]{
+		MaxWait: time.Second,
+		now:     func() time.Time { return now },
+	})
+	q.TryPush(job{"low", 0})
+	now = now.Add(500 * time.Millisecond)
+	q.TryPush(job{"high1", 9})
+	q.TryPush(job{"high2", 9})
+	if j, _ := q.TryPop(); j.name != "high1" {
+		t.Errorf("before MaxWait popped %q", j.name)
+	}
+	now = now.Add(time.Second)
+	// low has waited 1.5s and high2 1s: only low is overdue.
+	q.TryPush(job{"high3", 9})
+	if got := popAll(t, q); !slices.Equal(got, []string{"low", "high2", "high3"}) {
+		t.Errorf("order = %v", got)
+	}
+
+	// Many elements served by priority must not pile up in the FIFO.
+	for i := 0; i < 1000; i++ {
+		q.TryPush(job{"x", i})
+		q.TryPop()
+	}
+	q.TryPush(job{"y", 0})
+	q.TryPop()
+	if len(q.fifo) > 40 {
+		t.Errorf("fifo holds %d removed items", len(q.fifo))
+	}
+}
+
+// TestPriorityQueueClose tests that Close rejects pushes, wakes blocked Pops
+// and lets queued elements drain.
+func TestPriorityQueueClose(t *testing.T) {
+	q := NewPriorityQueue(byPriority, PriorityQueueOptions[job]{})
+	errc := make(chan error)
+	go func() {
+		_, err := q.Pop(context.Background())
+		errc <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	q.Close()
+	if err := <-errc; err != ErrClosed {
+		t.Errorf("blocked Pop after Close: %v", err)
+	}
+
+	q = NewPriorityQueue(byPriority, PriorityQueueOptions[job]{})
+	q.TryPush(job{"a", 1})
+	q.Close()
+	q.Close()
+	if err := q.TryPush(job{"b", 1}); err != ErrClosed {
+		t.Errorf("TryPush after Close: %v", err)
+	}
+	if j, err := q.Pop(context.Background()); err != nil || j.name != "a" {
+		t.Errorf("Pop = %v, %v", j, err)
+	}
+	if _, err := q.Pop(context.Background()); err != ErrClosed {
+		t.Errorf("Pop after drain: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	empty := NewPriorityQueue(byPriority, PriorityQueueOptions[job]{})
+	if _, err := empty.Pop(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Pop with canceled context: %v", err)
+	}
+}
+
+// TestPriorityQueueConcurrent runs blocking producers and consumers through a
+// small bounded queue under the race detector.
+func TestPriorityQueueConcurrent(t *testing.T) {
+	const producers, consumers, perProducer = 8, 8, 1000
+	q := NewPriorityQueue(func(a, b int) bool { return a < b }, PriorityQueueOptions[int]{
+		Capacity: 16, Policy: BlockWhenFull, MaxWait: time.Millisecond,
+	})
+	ctx := context.Background()
+	var pwg, cwg sync.WaitGroup
+	got := make([][]int, consumers)
+	for p := 0; p < producers; p++ {
+		pwg.Add(1)
+		go func(p int) {
+			defer pwg.Done()
+			for i := 0; i < perProducer; i++ {
+				if err := q.Push(ctx, p*perProducer+i); err != nil {
+					t.Errorf("Push: %v", err)
+					return
+				}
+			}
+		}(p)
+	}
+	for c := 0; c < consumers; c++ {
+		cwg.Add(1)
+		go func(c int) {
+			defer cwg.Done()
+			for {
+				v, err := q.Pop(ctx)
+				if err == ErrClosed {
+					return
+				}
+				got[c] = append(got[c], v)
+			}
+		}(c)
+	}
+	pwg.Wait()
+	q.Close()
+	cwg.Wait()
+	var all []int
+	for _, g := range got {
+		all = append(all, g...)
+	}
+	slices.Sort(all)
+	for i, v := range all {
+		if v != i {
+			t.Fatalf("value %d missing or duplicated", i)
+		}
+	}
+	if len(all) != producers*perProducer {
+		t.Fatalf("received %d values", len(all))
+	}
+}
+
+// BenchmarkPriorityQueue compares paired push/pop through the priority queue
+// with the channel-backed ThreadSafeBuffer.
+func BenchmarkPriorityQueue(b *testing.B) {
+	less := func(a, b int) bool { return a < b }
+	b.Run("PriorityQueue", func(b *testing.B) {
+		q := NewPriorityQueue(less, PriorityQueueOptions[int]{Capacity: 1024, Policy: BlockWhenFull})
+		ctx := context.Background()
+		b.RunParallel(func(pb *testing.PB) {
+			rng := rand.New(rand.NewSource(rand.Int63()))
+			for pb.Next() {
+				q.Push(ctx, rng.Intn(100))
+				q.Pop(ctx)
+			}
+		})
+	})
+	b.Run("PriorityQueue/aging", func(b *testing.B) {
+		q := NewPriorityQueue(less, PriorityQueueOptions[int]{Capacity: 1024, Policy: BlockWhenFull, MaxWait: time.Millisecond})
+		ctx := context.Background()
+		b.RunParallel(func(pb *testing.PB) {
+			rng := rand.New(rand.NewSource(rand.Int63()))
+			for pb.Next() {
+				q.Push(ctx, rng.Intn(100))
+				q.Pop(ctx)
+			}
+		})
+	})
+	b.Run("ThreadSafeBuffer", func(b *testing.B) {
+		tsb := NewThreadSafeBuffer(1024)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				tsb.Write(1)
+				tsb.Read()
+			}
+		})
+	})
+}
@@ -0,0 +1,245 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"math/bits"
+	"runtime"
+	"sync/atomic"
+)
+
+var (
+	// ErrFull is returned by TryWrite when the buffer has no free slot.
+	ErrFull = errors.New("ring buffer full")
+	// ErrEmpty is returned by TryRead when the buffer holds no element.
+	ErrEmpty = errors.New("ring buffer empty")
+	// ErrClosed is returned by writes after Close, and by reads once the
+	// buffer is closed and drained.
+	ErrClosed = errors.New("ring buffer closed")
+)
+
+type ringCell[T any] struct {
+	// seq is the position this cell expects next: pos when free for the
+	// write at pos, pos+1 once that write is readable.
+	seq atomic.Uint64
+	val T
+}
+
+// cacheLinePad separates hot atomics so producers and consumers do not
+// invalidate each other's cache lines.
+type cacheLinePad [64]byte
+
+// RingBuffer is a bounded multi-producer multi-consumer FIFO queue. The fast
+// paths, TryWrite and TryRead, are lock-free: each slot carries a sequence
+// number and producers and consumers claim positions with compare-and-swap
+// (Vyukov's bounded queue). Write and Read block until they succeed, the
+// context is done or the buffer is closed.
+//
+// Successful operations are linearizable, but ErrEmpty and ErrFull are not
+// quite: TryRead reports empty while the write to the oldest slot is still
+// landing, even if a later write has already returned, and TryWrite reports
+// full in the mirror case. Callers that need an exact answer should block.
+type RingBuffer[T any] struct {
+	_     cacheLinePad
+	tail  atomic.Uint64 // next position to write
+	_     cacheLinePad
+	head  atomic.Uint64 // next position to read
+	_     cacheLinePad
+	mask  uint64
+	cells []ringCell[T]
+
+	closed  atomic.Bool
+	writers atomic.Int64 // TryWrite calls in progress, for Close
+	done    chan struct{}
+	// notEmpty and notFull each hold at most one wake-up token and are only
+	// signalled while a waiter is registered. A woken waiter that succeeds
+	// passes the token on if more work remains, so a dropped token never
+	// strands a waiter.
+	notEmpty     chan struct{}
+	notFull      chan struct{}
+	readWaiters  atomic.Int32
+	writeWaiters atomic.Int32
+}
+
+// NewRingBuffer returns an empty buffer holding at least capacity elements,
+// rounded up to a power of two. The smallest buffer has two slots, since with
+// one a filled slot's sequence number would equal that of a free one.
+func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
+	size := 1 << bits.Len(uint(max(capacity, 2)-1))
+	r := &RingBuffer[T]{
+		mask:     uint64(size - 1),
+		cells:    make([]ringCell[T], size),
+		done:     make(chan struct{}),
+		notEmpty: make(chan struct{}, 1),
+		notFull:  make(chan struct{}, 1),
+	}
+	for i := range r.cells {
+		r.cells[i].seq.Store(uint64(i))
+	}
+	return r
+}
+
+// Cap returns the number of elements the buffer can hold.
+func (r *RingBuffer[T]) Cap() int { return len(r.cells) }
+
+// Len returns the number of elements in the buffer. Under concurrent use it
+// is a momentary estimate.
+func (r *RingBuffer[T]) Len() int {
+	head := r.head.Load()
+	tail := r.tail.Load()
+	if tail <= head {
+		return 0
+	}
+	return min(int(tail-head), len(r.cells))
+}
+
+// TryWrite adds val without blocking. It returns ErrFull if there is no room
+// and ErrClosed after Close.
+func (r *RingBuffer[T]) TryWrite(val T) error {
+	r.writers.Add(1)
+	defer r.writers.Add(-1)
+	if r.closed.Load() {
+		return ErrClosed
+	}
+	pos := r.tail.Load()
+	for {
+		cell := &r.cells[pos&r.mask]
+		seq := cell.seq.Load()
+		switch dif := int64(seq - pos); {
+		case dif == 0:
+			if r.tail.CompareAndSwap(pos, pos+1) {
+				cell.val = val
+				cell.seq.Store(pos + 1)
+				if r.readWaiters.Load() > 0 {
+					signal(r.notEmpty)
+				}
+				return nil
+			}
+			pos = r.tail.Load()
+		case dif < 0:
+			return ErrFull
+		default:
+			pos = r.tail.Load()
+		}
+	}
+}
+
+// TryRead removes the oldest element without blocking. It returns ErrEmpty if
+// there is none, or ErrClosed if the buffer is closed and drained.
+func (r *RingBuffer[T]) TryRead() (T, error) {
+	var zero T
+	for {
+		val, ok := r.dequeue()
+		if ok {
+			if r.writeWaiters.Load() > 0 {
+				signal(r.notFull)
+			}
+			return val, nil
+		}
+		if !r.closed.Load() {
+			return zero, ErrEmpty
+		}
+		// Closed: a write that started before Close may still be landing.
+		if r.writers.Load() == 0 {
+			if val, ok := r.dequeue(); ok {
+				return val, nil
+			}
+			return zero, ErrClosed
+		}
+		runtime.Gosched()
+	}
+}
+
+func (r *RingBuffer[T]) dequeue() (T, bool) {
+	var zero T
+	pos := r.head.Load()
+	for {
+		cell := &r.cells[pos&r.mask]
+		seq := cell.seq.Load()
+		switch dif := int64(seq - (pos + 1)); {
+		case dif == 0:
+			if r.head.CompareAndSwap(pos, pos+1) {
+				val := cell.val
+				cell.val = zero
+				cell.seq.Store(pos + r.mask + 1)
+				return val, true
+			}
+			pos = r.head.Load()
+		case dif < 0:
+			return zero, false
+		default:
+			pos = r.head.Load()
+		}
+	}
+}
+
+// Write adds val, waiting for room until ctx is done. It returns ErrClosed if
+// the buffer is closed before val could be added.
+func (r *RingBuffer[T]) Write(ctx context.Context, val T) error {
+	err := r.TryWrite(val)
+	if err != ErrFull {
+		return err
+	}
+	// Register before retrying: a reader that frees a slot after our retry
+	// fails is then guaranteed to see the registration and signal.
+	r.writeWaiters.Add(1)
+	defer r.writeWaiters.Add(-1)
+	for {
+		err := r.TryWrite(val)
+		if err != ErrFull {
+			if err == nil && r.Len() < r.Cap() {
+				signal(r.notFull)
+			}
+			return err
+		}
+		select {
+		case <-r.notFull:
+		case <-r.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Read removes the oldest element, waiting until one is available or ctx is
+// done. After Close it keeps returning buffered elements, then ErrClosed.
+func (r *RingBuffer[T]) Read(ctx context.Context) (T, error) {
+	val, err := r.TryRead()
+	if err != ErrEmpty {
+		return val, err
+	}
+	r.readWaiters.Add(1)
+	defer r.readWaiters.Add(-1)
+	for {
+		val, err := r.TryRead()
+		if err != ErrEmpty {
+			if err == nil && r.Len() > 0 {
+				signal(r.notEmpty)
+			}
+			return val, err
+		}
+		select {
+		case <-r.notEmpty:
+		case <-r.done:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+	}
+}
+
+// Close stops further writes and wakes all blocked callers. Elements already
+// in the buffer can still be read. Close is idempotent.
+func (r *RingBuffer[T]) Close() {
+	if r.closed.CompareAndSwap(false, true) {
+		close(r.done)
+	}
+}
+
+// signal leaves a wake-up token in ch unless one is already pending.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
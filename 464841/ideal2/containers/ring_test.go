@@ -0,0 +1,209 @@
+package containers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestRingBufferFIFO tests ordering, capacity rounding and the full and
+// empty conditions.
+func TestRingBufferFIFO(t *testing.T) {
+	r := NewRingBuffer[int](3)
+	if r.Cap() != 4 {
+		t.Fatalf("Cap = %d, want 4", r.Cap())
+	}
+	if _, err := r.TryRead(); err != ErrEmpty {
+		t.Errorf("TryRead on empty buffer: %v", err)
+	}
+	// Wrap around the ring several times.
+	next := 0
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 4; i++ {
+			if err := r.TryWrite(next + i); err != nil {
+				t.Fatalf("TryWrite: %v", err)
+			}
+		}
+		if err := r.TryWrite(99); err != ErrFull {
+			t.Errorf("TryWrite on full buffer: %v", err)
+		}
+		if r.Len() != 4 {
+			t.Errorf("Len = %d, want 4", r.Len())
+		}
+		for i := 0; i < 4; i++ {
+			v, err := r.TryRead()
+			if err != nil || v != next+i {
+				t.Fatalf("TryRead = %d, %v, want %d", v, err, next+i)
+			}
+		}
+		next += 4
+	}
+	if r.Len() != 0 {
+		t.Errorf("Len = %d, want 0", r.Len())
+	}
+}
+
+// TestRingBufferClose tests that Close rejects writes but lets readers drain
+// what was buffered.
+func TestRingBufferClose(t *testing.T) {
+	r := NewRingBuffer[string](4)
+	r.TryWrite("a")
+	r.TryWrite("b")
+	r.Close()
+	r.Close()
+	if err := r.TryWrite("c"); err != ErrClosed {
+		t.Errorf("TryWrite after Close: %v", err)
+	}
+	if err := r.Write(context.Background(), "c"); err != ErrClosed {
+		t.Errorf("Write after Close: %v", err)
+	}
+	for _, want := range []string{"a", "b"} {
+		if v, err := r.Read(context.Background()); err != nil || v != want {
+			t.Errorf("Read = %q, %v, want %q", v, err, want)
+		}
+	}
+	if _, err := r.Read(context.Background()); err != ErrClosed {
+		t.Errorf("Read after drain: %v", err)
+	}
+}
+
+// TestRingBufferBlocking tests that blocked calls wake on the opposite
+// operation, on Close and on context cancellation.
+func TestRingBufferBlocking(t *testing.T) {
+	if r := NewRingBuffer[int](1); r.Cap() != 2 {
+		t.Fatalf("Cap = %d, want 2", r.Cap())
+	}
+	r := NewRingBuffer[int](2)
+	got := make(chan int)
+	go func() {
+		v, _ := r.Read(context.Background())
+		got <- v
+	}()
+	time.Sleep(10 * time.Millisecond)
+	r.TryWrite(7)
+	if v := <-got; v != 7 {
+		t.Errorf("blocked Read got %d", v)
+	}
+
+	r.TryWrite(1)
+	r.TryWrite(2)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := r.Write(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Write on full buffer: %v", err)
+	}
+
+	errc := make(chan error)
+	go func() { errc <- r.Write(context.Background(), 3) }()
+	time.Sleep(10 * time.Millisecond)
+	r.Close()
+	if err := <-errc; err != ErrClosed {
+		t.Errorf("Write blocked across Close: %v", err)
+	}
+
+	empty := NewRingBuffer[int](1)
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := empty.Read(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Read on empty buffer: %v", err)
+	}
+}
+
+// TestRingBufferStress runs many producers and consumers through a small
+// buffer and checks that every value is delivered exactly once. Run with
+// -race.
+func TestRingBufferStress(t *testing.T) {
+	const producers, consumers, perProducer = 8, 8, 5000
+	r := NewRingBuffer[int](16)
+	ctx := context.Background()
+	var pwg, cwg sync.WaitGroup
+	seen := make([][]int, consumers)
+
+	for p := 0; p < producers; p++ {
+		pwg.Add(1)
+		go func(p int) {
+			defer pwg.Done()
+			for i := 0; i < perProducer; i++ {
+				v := p*perProducer + i
+				// Mix blocking and non-blocking writes.
+				if i%2 == 0 {
+					if err := r.Write(ctx, v); err != nil {
+						t.Errorf("Write: %v", err)
+						return
+					}
+					continue
+				}
+				for r.TryWrite(v) == ErrFull {
+				}
+			}
+		}(p)
+	}
+	for c := 0; c < consumers; c++ {
+		cwg.Add(1)
+		go func(c int) {
+			defer cwg.Done()
+			for {
+				v, err := r.Read(ctx)
+				if err == ErrClosed {
+					return
+				}
+				if err != nil {
+					t.Errorf("Read: %v", err)
+					return
+				}
+				seen[c] = append(seen[c], v)
+			}
+		}(c)
+	}
+	pwg.Wait()
+	r.Close()
+	cwg.Wait()
+
+	count := make([]int, producers*perProducer)
+	for c, vals := range seen {
+		last := make(map[int]int)
+		for _, v := range vals {
+			count[v]++
+			// Values from one producer reach one consumer in order.
+			p := v / perProducer
+			if prev, ok := last[p]; ok && v <= prev {
+				t.Errorf("consumer %d saw %d after %d", c, v, prev)
+			}
+			last[p] = v
+		}
+	}
+	for v, n := range count {
+		if n != 1 {
+			t.Fatalf("value %d delivered %d times", v, n)
+		}
+	}
+}
+
+// BenchmarkRingBuffer compares the ring buffer with the channel-backed
+// ThreadSafeBuffer under paired producers and consumers.
+func BenchmarkRingBuffer(b *testing.B) {
+	b.Run("RingBuffer", func(b *testing.B) {
+		r := NewRingBuffer[int](1024)
+		ctx := context.Background()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				r.Write(ctx, 1)
+				r.Read(ctx)
+			}
+		})
+	})
+	b.Run("ThreadSafeBuffer", func(b *testing.B) {
+		tsb := NewThreadSafeBuffer(1024)
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				tsb.Write(1)
+				tsb.Read()
+			}
+		})
+	})
+}
@@ -0,0 +1,121 @@
+package containers
+
+import (
+	"math/bits"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+type shardSlot[T any] struct {
+	val T
+	set bool
+}
+
+// logShard holds every element whose global index i satisfies
+// i&mask == shard number, at position i>>shift. It is padded to a cache line
+// so neighbouring shard locks do not share one.
+type logShard[T any] struct {
+	mu    sync.RWMutex
+	slots []shardSlot[T]
+	_     [64]byte
+}
+
+// ShardedSlice is an append-only log whose elements keep the index they were
+// given by Append. Indexes are handed out by an atomic counter and consecutive
+// indexes live in different shards, each guarded by its own lock, so
+// concurrent appends rarely contend on the same mutex.
+//
+// Because an index is reserved before its element is stored, a concurrent
+// reader may briefly see index i unset while index i+1 is already set.
+type ShardedSlice[T any] struct {
+	next   atomic.Int64
+	shift  uint
+	mask   int
+	shards []logShard[T]
+}
+
+// NewShardedSlice returns an empty log with the given number of shards,
+// rounded up to a power of two. A non-positive count uses four shards per
+// processor.
+func NewShardedSlice[T any](shards int) *ShardedSlice[T] {
+	if shards <= 0 {
+		shards = 4 * runtime.GOMAXPROCS(0)
+	}
+	shift := uint(bits.Len(uint(shards - 1)))
+	return &ShardedSlice[T]{
+		shift:  shift,
+		mask:   1<<shift - 1,
+		shards: make([]logShard[T], 1<<shift),
+	}
+}
+
+// Append stores val and returns its index.
+func (s *ShardedSlice[T]) Append(val T) int {
+	i := int(s.next.Add(1) - 1)
+	sh := &s.shards[i&s.mask]
+	pos := i >> s.shift
+	sh.mu.Lock()
+	// Appends to one shard can arrive out of order; grow over the gap.
+	if pos >= len(sh.slots) {
+		sh.slots = append(sh.slots, make([]shardSlot[T], pos+1-len(sh.slots))...)
+	}
+	sh.slots[pos] = shardSlot[T]{val: val, set: true}
+	sh.mu.Unlock()
+	return i
+}
+
+// Get returns the element at index, or false if it has not been stored yet.
+func (s *ShardedSlice[T]) Get(index int) (T, bool) {
+	var zero T
+	if index < 0 {
+		return zero, false
+	}
+	sh := &s.shards[index&s.mask]
+	pos := index >> s.shift
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	if pos >= len(sh.slots) || !sh.slots[pos].set {
+		return zero, false
+	}
+	return sh.slots[pos].val, true
+}
+
+// Len returns the number of indexes handed out, including appends that are
+// still in progress.
+func (s *ShardedSlice[T]) Len() int { return int(s.next.Load()) }
+
+// Snapshot returns a copy of the longest prefix of the log whose elements have
+// all been stored. It read-locks every shard, so the result is a consistent
+// view in which element i of the copy is the element at index i.
+func (s *ShardedSlice[T]) Snapshot() []T {
+	for i := range s.shards {
+		s.shards[i].mu.RLock()
+	}
+	defer func() {
+		for i := range s.shards {
+			s.shards[i].mu.RUnlock()
+		}
+	}()
+	n := s.Len()
+	out := make([]T, 0, n)
+	for i := 0; i < n; i++ {
+		sh := &s.shards[i&s.mask]
+		pos := i >> s.shift
+		if pos >= len(sh.slots) || !sh.slots[pos].set {
+			break
+		}
+		out = append(out, sh.slots[pos].val)
+	}
+	return out
+}
+
+// Range calls fn for each element of a Snapshot in index order until fn
+// returns false. fn runs without any lock held.
+func (s *ShardedSlice[T]) Range(fn func(index int, val T) bool) {
+	for i, v := range s.Snapshot() {
+		if !fn(i, v) {
+			return
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package containers
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// TestShardedSliceIndexes tests that Append hands out consecutive indexes and
+// Get returns what was stored there.
+func TestShardedSliceIndexes(t *testing.T) {
+	s := NewShardedSlice[string](3)
+	if len(s.shards) != 4 {
+		t.Fatalf("shards = %d, want 4", len(s.shards))
+	}
+	for i := 0; i < 10; i++ {
+		if idx := s.Append(fmt.Sprint(i)); idx != i {
+			t.Fatalf("Append returned %d, want %d", idx, i)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		if v, ok := s.Get(i); !ok || v != fmt.Sprint(i) {
+			t.Errorf("Get(%d) = %q, %v", i, v, ok)
+		}
+	}
+	for _, i := range []int{-1, 10, 1 << 20} {
+		if _, ok := s.Get(i); ok {
+			t.Errorf("Get(%d) succeeded", i)
+		}
+	}
+	if s.Len() != 10 || !slices.Equal(s.Snapshot(), []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}) {
+		t.Errorf("Len %d, Snapshot %v", s.Len(), s.Snapshot())
+	}
+}
+
+// TestShardedSliceConcurrent tests concurrent appends under the race detector:
+// every value ends up at the index Append returned, exactly once.
+func TestShardedSliceConcurrent(t *testing.T) {
+	const goroutines, perGoroutine = 16, 2000
+	s := NewShardedSlice[int](8)
+	indexes := make([][]int, goroutines)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				indexes[g] = append(indexes[g], s.Append(g*perGoroutine+j))
+				// Readers and snapshots run alongside the writers.
+				if j%500 == 0 {
+					snap := s.Snapshot()
+					for i, v := range snap {
+						if got, ok := s.Get(i); !ok || got != v {
+							t.Errorf("snapshot[%d] = %d, Get = %d, %v", i, v, got, ok)
+							return
+						}
+					}
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for g, idxs := range indexes {
+		for j, idx := range idxs {
+			if v, _ := s.Get(idx); v != g*perGoroutine+j {
+				t.Fatalf("index %d holds %d, want %d", idx, v, g*perGoroutine+j)
+			}
+		}
+	}
+	snap := s.Snapshot()
+	if len(snap) != goroutines*perGoroutine {
+		t.Fatalf("snapshot has %d elements, want %d", len(snap), goroutines*perGoroutine)
+	}
+	slices.Sort(snap)
+	for i, v := range snap {
+		if v != i {
+			t.Fatalf("value %d missing or duplicated", i)
+		}
+	}
+}
+
+// TestShardedSliceSnapshotStopsAtGap tests that Snapshot returns only the
+// fully stored prefix when an index is reserved but not yet written.
+func TestShardedSliceSnapshotStopsAtGap(t *testing.T) {
+	s := NewShardedSlice[int](2)
+	s.Append(0)
+	s.next.Add(1) // index 1 reserved by an in-flight Append
+	s.Append(2)
+	if snap := s.Snapshot(); !slices.Equal(snap, []int{0}) {
+		t.Errorf("Snapshot = %v, want [0]", snap)
+	}
+	if v, ok := s.Get(2); !ok || v != 2 {
+		t.Errorf("Get(2) = %d, %v", v, ok)
+	}
+	var seen []int
+	s.Range(func(i, v int) bool {
+		seen = append(seen, i)
+		return true
+	})
+	if !slices.Equal(seen, []int{0}) {
+		t.Errorf("Range visited %v", seen)
+	}
+}
+
+// goroutineCounts are the concurrency levels the slice benchmarks sweep.
+var goroutineCounts = []int{1, 2, 4, 8, 16, 32, 64}
+
+// runConcurrent splits b.N operations across g goroutines.
+func runConcurrent(b *testing.B, g int, op func(i int)) {
+	var wg sync.WaitGroup
+	per := b.N / g
+	b.ResetTimer()
+	for w := 0; w < g; w++ {
+		n := per
+		if w == 0 {
+			n += b.N % g
+		}
+		wg.Add(1)
+		go func(w, n int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				op(w*per + i)
+			}
+		}(w, n)
+	}
+	wg.Wait()
+}
+
+// BenchmarkConcurrentAppend compares SafeSlice with ShardedSlice for appends
+// from 1 to 64 goroutines.
+func BenchmarkConcurrentAppend(b *testing.B) {
+	for _, g := range goroutineCounts {
+		b.Run(fmt.Sprintf("SafeSlice/goroutines=%d", g), func(b *testing.B) {
+			ss := NewSafeSlice(0)
+			runConcurrent(b, g, func(i int) { ss.Append(i) })
+		})
+		b.Run(fmt.Sprintf("ShardedSlice/goroutines=%d", g), func(b *testing.B) {
+			s := NewShardedSlice[int](0)
+			runConcurrent(b, g, func(i int) { s.Append(i) })
+		})
+	}
+}
+
+// BenchmarkConcurrentGet compares random reads of a 1M-element log.
+func BenchmarkConcurrentGet(b *testing.B) {
+	const size = 1 << 20
+	ss := NewSafeSlice(0)
+	s := NewShardedSlice[int](0)
+	for i := 0; i < size; i++ {
+		ss.Append(i)
+		s.Append(i)
+	}
+	for _, g := range goroutineCounts {
+		b.Run(fmt.Sprintf("SafeSlice/goroutines=%d", g), func(b *testing.B) {
+			runConcurrent(b, g, func(i int) { ss.Get(i * 7919 % size) })
+		})
+		b.Run(fmt.Sprintf("ShardedSlice/goroutines=%d", g), func(b *testing.B) {
+			runConcurrent(b, g, func(i int) { s.Get(i * 7919 % size) })
+		})
+	}
+}
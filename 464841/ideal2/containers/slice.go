@@ -0,0 +1,262 @@
+// Package containers provides concurrency-safe slices and buffers: mutex,
+// read-write-mutex, lock-striped and atomic-snapshot slices, a channel-backed
+// buffer and a lock-free ring buffer.
+package containers
+
+import (
+	"iter"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// SafeSlice is a thread-safe wrapper for a slice
+//
+// Every method is linearizable: it runs entirely while holding the slice's
+// lock (the write lock for mutations, the read lock for Get, Len and
+// Snapshot), so it takes effect at a single instant between its call and
+// return, and concurrent calls behave as if executed in some sequential order
+// consistent with real time. Range, All and Values iterate over a Snapshot,
+// whose linearization point is the copy; later changes are not observed.
+type SafeSlice struct {
+	mu      sync.RWMutex
+	slice   []int
+	metrics *Metrics
+}
+
+// NewSafeSlice returns an empty SafeSlice with room for capacity elements.
+func NewSafeSlice(capacity int) *SafeSlice {
+	return &SafeSlice{slice: make([]int, 0, capacity)}
+}
+
+// Instrument records lock contention, wait and hold times in m. It must be
+// called before the slice is shared between goroutines.
+func (ss *SafeSlice) Instrument(m *Metrics) {
+	m.kind = "lock"
+	ss.metrics = m
+}
+
+func (ss *SafeSlice) lock() lockToken {
+	if ss.metrics == nil {
+		ss.mu.Lock()
+		return lockToken{}
+	}
+	return ss.metrics.acquire(&ss.mu)
+}
+
+func (ss *SafeSlice) unlock(t lockToken) {
+	if ss.metrics != nil {
+		ss.metrics.release(t)
+	}
+	ss.mu.Unlock()
+}
+
+func (ss *SafeSlice) rlock() lockToken {
+	if ss.metrics == nil {
+		ss.mu.RLock()
+		return lockToken{}
+	}
+	return ss.metrics.acquire((*readLocker)(&ss.mu))
+}
+
+func (ss *SafeSlice) runlock(t lockToken) {
+	if ss.metrics != nil {
+		ss.metrics.release(t)
+	}
+	ss.mu.RUnlock()
+}
+
+// Append adds an element to the slice in a thread-safe manner
+func (ss *SafeSlice) Append(val int) {
+	defer ss.unlock(ss.lock())
+	ss.slice = append(ss.slice, val)
+}
+
+// Get retrieves an element at a specific index in a thread-safe manner
+func (ss *SafeSlice) Get(index int) (int, bool) {
+	defer ss.runlock(ss.rlock())
+	if index < 0 || index >= len(ss.slice) {
+		return 0, false
+	}
+	return ss.slice[index], true
+}
+
+// Len returns the number of elements.
+func (ss *SafeSlice) Len() int {
+	defer ss.runlock(ss.rlock())
+	return len(ss.slice)
+}
+
+// AppendAll adds vals to the end of the slice as one atomic step: no reader
+// sees only some of them.
+func (ss *SafeSlice) AppendAll(vals ...int) {
+	defer ss.unlock(ss.lock())
+	ss.slice = append(ss.slice, vals...)
+}
+
+// Insert inserts vals before index, shifting later elements up. It reports
+// false, changing nothing, if index is outside [0, Len()].
+func (ss *SafeSlice) Insert(index int, vals ...int) bool {
+	defer ss.unlock(ss.lock())
+	if index < 0 || index > len(ss.slice) {
+		return false
+	}
+	ss.slice = slices.Insert(ss.slice, index, vals...)
+	return true
+}
+
+// RemoveAt removes and returns the element at index, shifting later elements
+// down.
+func (ss *SafeSlice) RemoveAt(index int) (int, bool) {
+	defer ss.unlock(ss.lock())
+	if index < 0 || index >= len(ss.slice) {
+		return 0, false
+	}
+	val := ss.slice[index]
+	ss.slice = slices.Delete(ss.slice, index, index+1)
+	return val, true
+}
+
+// Swap exchanges the elements at i and j. It reports false if either index is
+// out of range.
+func (ss *SafeSlice) Swap(i, j int) bool {
+	defer ss.unlock(ss.lock())
+	if i < 0 || j < 0 || i >= len(ss.slice) || j >= len(ss.slice) {
+		return false
+	}
+	ss.slice[i], ss.slice[j] = ss.slice[j], ss.slice[i]
+	return true
+}
+
+// Filter removes, in place, every element for which keep returns false and
+// returns how many were removed. keep runs with the write lock held, so it
+// must not call methods of ss.
+func (ss *SafeSlice) Filter(keep func(val int) bool) int {
+	defer ss.unlock(ss.lock())
+	before := len(ss.slice)
+	ss.slice = slices.DeleteFunc(ss.slice, func(v int) bool { return !keep(v) })
+	return before - len(ss.slice)
+}
+
+// Clear removes all elements, keeping the allocated capacity.
+func (ss *SafeSlice) Clear() {
+	defer ss.unlock(ss.lock())
+	ss.slice = ss.slice[:0]
+}
+
+// Snapshot returns a copy of the elements.
+func (ss *SafeSlice) Snapshot() []int {
+	defer ss.runlock(ss.rlock())
+	return slices.Clone(ss.slice)
+}
+
+// Range calls fn for each element of a snapshot, in order, until fn returns
+// false. fn runs without any lock held, so it may modify ss; such changes are
+// not reflected in the ongoing iteration.
+func (ss *SafeSlice) Range(fn func(index, val int) bool) {
+	for i, v := range ss.Snapshot() {
+		if !fn(i, v) {
+			return
+		}
+	}
+}
+
+// All returns an iterator over the index-value pairs of a snapshot taken when
+// iteration starts, for use with range-over-func.
+func (ss *SafeSlice) All() iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		ss.Range(yield)
+	}
+}
+
+// Values returns an iterator over the values of a snapshot taken when
+// iteration starts.
+func (ss *SafeSlice) Values() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for _, v := range ss.Snapshot() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// MutexSlice is SafeSlice guarded by a plain Mutex, so reads exclude each
+// other as well as writes.
+type MutexSlice struct {
+	mu    sync.Mutex
+	slice []int
+}
+
+// NewMutexSlice returns an empty MutexSlice with room for capacity elements.
+func NewMutexSlice(capacity int) *MutexSlice {
+	return &MutexSlice{slice: make([]int, 0, capacity)}
+}
+
+// Append safely appends an element to the slice.
+func (ms *MutexSlice) Append(val int) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.slice = append(ms.slice, val)
+}
+
+// Get safely retrieves an element from the slice.
+func (ms *MutexSlice) Get(index int) (int, bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if index < 0 || index >= len(ms.slice) {
+		return 0, false
+	}
+	return ms.slice[index], true
+}
+
+// Len returns the number of elements.
+func (ms *MutexSlice) Len() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return len(ms.slice)
+}
+
+// SnapshotSlice is an append-only slice whose readers never lock. The current
+// contents are published through an atomic pointer; writers serialize on a
+// mutex, store the new element past the published length and then publish a
+// header one element longer. Readers holding an older header never look past
+// its length, so the element being written is invisible to them, and when
+// append moves to a new array the old one stays valid for them.
+type SnapshotSlice struct {
+	mu   sync.Mutex
+	data atomic.Pointer[[]int]
+}
+
+// NewSnapshotSlice returns an empty SnapshotSlice with room for capacity
+// elements.
+func NewSnapshotSlice(capacity int) *SnapshotSlice {
+	s := &SnapshotSlice{}
+	data := make([]int, 0, capacity)
+	s.data.Store(&data)
+	return s
+}
+
+// Append adds val to the end of the slice.
+func (s *SnapshotSlice) Append(val int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := append(*s.data.Load(), val)
+	s.data.Store(&data)
+}
+
+// Get returns the element at index without locking.
+func (s *SnapshotSlice) Get(index int) (int, bool) {
+	data := *s.data.Load()
+	if index < 0 || index >= len(data) {
+		return 0, false
+	}
+	return data[index], true
+}
+
+// Len returns the number of elements.
+func (s *SnapshotSlice) Len() int { return len(*s.data.Load()) }
+
+// Snapshot returns the current contents. The result shares storage with the
+// slice and must not be modified.
+func (s *SnapshotSlice) Snapshot() []int { return *s.data.Load() }
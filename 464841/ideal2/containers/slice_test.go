@@ -0,0 +1,330 @@
+package containers
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// intLog is the append/get surface shared by the slice variants.
+type intLog interface {
+	Append(val int)
+	Get(index int) (int, bool)
+	Len() int
+}
+
+// shardedLog adapts ShardedSlice, whose Append also returns the index.
+type shardedLog struct{ *ShardedSlice[int] }
+
+func (s shardedLog) Append(val int) { s.ShardedSlice.Append(val) }
+
+var sliceVariants = []struct {
+	name string
+	new  func(capacity int) intLog
+}{
+	{"Mutex", func(c int) intLog { return NewMutexSlice(c) }},
+	{"RWMutex", func(c int) intLog { return NewSafeSlice(c) }},
+	{"Sharded", func(int) intLog { return shardedLog{NewShardedSlice[int](0)} }},
+	{"Snapshot", func(c int) intLog { return NewSnapshotSlice(c) }},
+}
+
+// TestSliceVariants tests that every variant appends, indexes and bounds
+// checks alike, with concurrent writers and readers under the race detector.
+func TestSliceVariants(t *testing.T) {
+	for _, v := range sliceVariants {
+		t.Run(v.name, func(t *testing.T) {
+			s := v.new(0)
+			if _, ok := s.Get(0); ok {
+				t.Error("Get on empty slice succeeded")
+			}
+			const writers, perWriter = 8, 1000
+			var wg sync.WaitGroup
+			for w := 0; w < writers; w++ {
+				wg.Add(2)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < perWriter; i++ {
+						s.Append(w*perWriter + i)
+					}
+				}(w)
+				go func() {
+					defer wg.Done()
+					for i := 0; i < perWriter; i++ {
+						s.Get(i)
+						s.Len()
+					}
+				}()
+			}
+			wg.Wait()
+			if s.Len() != writers*perWriter {
+				t.Fatalf("Len = %d, want %d", s.Len(), writers*perWriter)
+			}
+			seen := make([]bool, writers*perWriter)
+			for i := 0; i < s.Len(); i++ {
+				val, ok := s.Get(i)
+				if !ok || seen[val] {
+					t.Fatalf("Get(%d) = %d, %v (duplicate or missing)", i, val, ok)
+				}
+				seen[val] = true
+			}
+			if _, ok := s.Get(-1); ok {
+				t.Error("Get(-1) succeeded")
+			}
+		})
+	}
+}
+
+// TestSnapshotSliceIsStable tests that a snapshot is unaffected by later
+// appends.
+func TestSnapshotSliceIsStable(t *testing.T) {
+	s := NewSnapshotSlice(2)
+	s.Append(1)
+	snap := s.Snapshot()
+	s.Append(2)
+	s.Append(3) // reallocates
+	if len(snap) != 1 || snap[0] != 1 {
+		t.Errorf("snapshot changed to %v", snap)
+	}
+	if got := s.Snapshot(); len(got) != 3 {
+		t.Errorf("Snapshot = %v", got)
+	}
+}
+
+// TestSafeSliceSequenceOps tests the sequence API sequentially.
+func TestSafeSliceSequenceOps(t *testing.T) {
+	ss := NewSafeSlice(0)
+	ss.AppendAll(1, 2, 3)
+	check := func(step string, want ...int) {
+		t.Helper()
+		if got := ss.Snapshot(); !slices.Equal(got, want) {
+			t.Errorf("after %s: %v, want %v", step, got, want)
+		}
+	}
+	check("AppendAll", 1, 2, 3)
+
+	if !ss.Insert(0, 0) || !ss.Insert(4, 4, 5) || !ss.Insert(2, 10) {
+		t.Fatal("Insert within range failed")
+	}
+	check("Insert", 0, 1, 10, 2, 3, 4, 5)
+	if ss.Insert(-1, 9) || ss.Insert(8, 9) {
+		t.Error("Insert out of range succeeded")
+	}
+
+	if v, ok := ss.RemoveAt(2); !ok || v != 10 {
+		t.Errorf("RemoveAt(2) = %d, %v", v, ok)
+	}
+	if _, ok := ss.RemoveAt(6); ok {
+		t.Error("RemoveAt out of range succeeded")
+	}
+	check("RemoveAt", 0, 1, 2, 3, 4, 5)
+
+	if !ss.Swap(0, 5) || ss.Swap(0, 6) || ss.Swap(-1, 0) {
+		t.Error("Swap bounds checking")
+	}
+	check("Swap", 5, 1, 2, 3, 4, 0)
+
+	if n := ss.Filter(func(v int) bool { return v%2 == 1 }); n != 3 {
+		t.Errorf("Filter removed %d, want 3", n)
+	}
+	check("Filter", 5, 1, 3)
+
+	var indexes, values []int
+	ss.Range(func(i, v int) bool {
+		indexes = append(indexes, i)
+		values = append(values, v)
+		return i < 1
+	})
+	if !slices.Equal(indexes, []int{0, 1}) || !slices.Equal(values, []int{5, 1}) {
+		t.Errorf("Range visited %v %v", indexes, values)
+	}
+
+	values = values[:0]
+	for i, v := range ss.All() {
+		// Mutating inside the loop does not deadlock or affect the snapshot.
+		ss.Append(v * 10)
+		values = append(values, i, v)
+	}
+	if !slices.Equal(values, []int{0, 5, 1, 1, 2, 3}) {
+		t.Errorf("All yielded %v", values)
+	}
+	if got := slices.Collect(ss.Values()); !slices.Equal(got, []int{5, 1, 3, 50, 10, 30}) {
+		t.Errorf("Values = %v", got)
+	}
+	for v := range ss.Values() {
+		if v != 5 {
+			t.Errorf("break did not stop Values at the first element: %d", v)
+		}
+		break
+	}
+
+	ss.Clear()
+	check("Clear")
+}
+
+// TestSafeSliceSequenceOpsConcurrent runs every mutation concurrently under
+// the race detector and checks that no value is lost or duplicated: each
+// inserted value ends up in the slice, removed by RemoveAt, or dropped by
+// Filter, exactly once.
+func TestSafeSliceSequenceOpsConcurrent(t *testing.T) {
+	const workers, perWorker = 8, 500
+	ss := NewSafeSlice(0)
+	var mu sync.Mutex
+	removed := make(map[int]int)
+	filtered := 0
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				v := w*perWorker + i + 1
+				switch i % 5 {
+				case 0:
+					ss.Insert(0, v)
+				case 1:
+					ss.AppendAll(v, -v) // negatives are filtered out below
+				case 2:
+					// The length may shrink between Len and Insert.
+					if !ss.Insert(ss.Len()/2, v) {
+						ss.Insert(0, v)
+					}
+					ss.Swap(0, ss.Len()-1)
+				case 3:
+					ss.Append(v)
+					if r, ok := ss.RemoveAt(0); ok {
+						mu.Lock()
+						removed[r]++
+						mu.Unlock()
+					}
+				case 4:
+					ss.Append(v)
+					n := ss.Filter(func(x int) bool { return x > 0 })
+					mu.Lock()
+					filtered += n
+					mu.Unlock()
+				}
+				for i, x := range ss.All() {
+					if x == 0 {
+						t.Errorf("zero value at %d in snapshot", i)
+						return
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	filtered += ss.Filter(func(x int) bool { return x > 0 })
+
+	seen := make(map[int]int)
+	for _, v := range ss.Snapshot() {
+		seen[v]++
+	}
+	for v, n := range removed {
+		seen[v] += n
+	}
+	negatives := 0
+	for v, n := range removed {
+		if v < 0 {
+			filtered += n // RemoveAt can take a negative before Filter does
+		}
+	}
+	for w := 0; w < workers; w++ {
+		for i := 0; i < perWorker; i++ {
+			v := w*perWorker + i + 1
+			if seen[v] != 1 {
+				t.Fatalf("value %d accounted for %d times", v, seen[v])
+			}
+			if i%5 == 1 {
+				negatives++
+			}
+		}
+	}
+	if filtered != negatives {
+		t.Errorf("filtered %d values, want %d negatives", filtered, negatives)
+	}
+}
+
+// BenchmarkSliceAppend benchmarks appending to a plain slice, the baseline
+// for the concurrent variants.
+func BenchmarkSliceAppend(b *testing.B) {
+	b.ReportAllocs()
+	var slice []int
+	for i := 0; i < b.N; i++ {
+		slice = append(slice, i)
+	}
+}
+
+// benchSize is the length of the slices read by the slicing and iteration
+// benchmarks; unlike b.N it keeps memory use independent of run time.
+const benchSize = 1 << 16
+
+// BenchmarkSliceSlicing benchmarks re-slicing a fixed-size slice.
+func BenchmarkSliceSlicing(b *testing.B) {
+	slice := make([]int, benchSize)
+	var total int
+	for i := 0; i < b.N; i++ {
+		total += len(slice[:i&(benchSize-1)])
+	}
+	_ = total
+}
+
+// BenchmarkSliceIteration benchmarks ranging over a fixed-size slice; ns/op is
+// per element.
+func BenchmarkSliceIteration(b *testing.B) {
+	slice := make([]int, benchSize)
+	for i := range slice {
+		slice[i] = i
+	}
+	b.ResetTimer()
+	var total int
+	for i := 0; i < b.N; i += benchSize {
+		for _, val := range slice {
+			total += val
+		}
+	}
+	_ = total
+}
+
+// BenchmarkAppendParallel compares the variants under parallel appends.
+func BenchmarkAppendParallel(b *testing.B) {
+	for _, v := range sliceVariants {
+		b.Run(v.name, func(b *testing.B) {
+			s := v.new(0)
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					s.Append(i)
+				}
+			})
+		})
+	}
+}
+
+// readPercents are the read shares of the mixed workloads, in percent.
+var readPercents = []int{0, 50, 90, 99, 100}
+
+// BenchmarkMixed compares the variants under parallel workloads mixing Get
+// and Append. Each goroutine reads readPercent of the time, at spread-out
+// indexes of a prefilled slice.
+func BenchmarkMixed(b *testing.B) {
+	for _, v := range sliceVariants {
+		for _, reads := range readPercents {
+			b.Run(fmt.Sprintf("%s/reads=%d%%", v.name, reads), func(b *testing.B) {
+				s := v.new(benchSize)
+				for i := 0; i < benchSize; i++ {
+					s.Append(i)
+				}
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					for i := 0; pb.Next(); i++ {
+						if i%100 < reads {
+							s.Get(i * 7919 & (benchSize - 1))
+						} else {
+							s.Append(i)
+						}
+					}
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,3 @@
+module example.com/464841/ideal2
+
+go 1.23.4
@@ -1,98 +1,42 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log"
 	"math/rand"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
 	"sync"
-	"testing"
+	"sync/atomic"
 	"time"
-)
-
-// SafeSlice is a thread-safe wrapper for a slice
-type SafeSlice struct {
-	mu    sync.RWMutex
-	slice []int
-}
 
-// Append adds an element to the slice in a thread-safe manner
-func (ss *SafeSlice) Append(val int) {
-	ss.mu.Lock()
-	defer ss.mu.Unlock()
-	ss.slice = append(ss.slice, val)
-}
-
-// Get retrieves an element at a specific index in a thread-safe manner
-func (ss *SafeSlice) Get(index int) (int, bool) {
-	ss.mu.RLock()
-	defer ss.mu.RUnlock()
-	if index < 0 || index >= len(ss.slice) {
-		return 0, false
-	}
-	return ss.slice[index], true
-}
-
-// ThreadSafeBuffer is a thread-safe buffer using a channel
-type ThreadSafeBuffer struct {
-	buf chan int
-}
-
-// NewThreadSafeBuffer creates a new ThreadSafeBuffer with a specified capacity
-func NewThreadSafeBuffer(capacity int) *ThreadSafeBuffer {
-	return &ThreadSafeBuffer{buf: make(chan int, capacity)}
-}
-
-// Write appends an element to the buffer
-func (tsb *ThreadSafeBuffer) Write(val int) {
-	tsb.buf <- val
-}
-
-// Read retrieves an element from the buffer
-func (tsb *ThreadSafeBuffer) Read() (int, bool) {
-	select {
-	case val, ok := <-tsb.buf:
-		return val, ok
-	default:
-		return 0, false
-	}
-}
+	"example.com/464841/ideal2/containers"
+	"example.com/464841/ideal2/workload"
+)
 
-// BenchmarkSliceAppend benchmarks the append operation on a slice
-func BenchmarkSliceAppend(b *testing.B) {
-	slice := make([]int, 0, b.N)
-	for i := 0; i < b.N; i++ {
-		slice = append(slice, i)
+// ProfileSliceOperations profiles concurrent append and read operations on
+// SafeSlice and ShardedSlice. If metrics is not nil, the SafeSlice records its
+// lock contention there.
+func ProfileSliceOperations(sliceSize int, concurrencyLevel int, metrics *containers.Metrics) {
+	ss := containers.NewSafeSlice(sliceSize)
+	if metrics != nil {
+		ss.Instrument(metrics)
 	}
-}
+	profileSlice("SafeSlice", ss.Append, ss.Get, sliceSize, concurrencyLevel)
 
-// BenchmarkSliceSlicing benchmarks the slicing operation on a slice
-func BenchmarkSliceSlicing(b *testing.B) {
-	slice := make([]int, b.N)
-	for i := 0; i < b.N; i++ {
-		slice[i] = i
-	}
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		_ = slice[:i] // Use the slice to avoid unused value error
-	}
+	sharded := containers.NewShardedSlice[int](0)
+	shardedAppend := func(val int) { sharded.Append(val) }
+	profileSlice("ShardedSlice", shardedAppend, sharded.Get, sliceSize, concurrencyLevel)
 }
 
-// BenchmarkSliceIteration benchmarks the iteration operation on a slice
-func BenchmarkSliceIteration(b *testing.B) {
-	slice := make([]int, b.N)
-	for i := 0; i < b.N; i++ {
-		slice[i] = i
-	}
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		for _, val := range slice {
-			_ = val // Simulate processing the value
-		}
-	}
-}
-
-// ProfileSliceOperations profiles slice append and iteration operations
-func ProfileSliceOperations(sliceSize int, concurrencyLevel int) {
-	ss := SafeSlice{slice: make([]int, 0, sliceSize)}
+// profileSlice times concurrent appends followed by concurrent random reads.
+func profileSlice(name string, appendFn func(int), get func(int) (int, bool), sliceSize, concurrencyLevel int) {
 	var wg sync.WaitGroup
 
 	// Start concurrent appends
@@ -102,13 +46,13 @@ func ProfileSliceOperations(sliceSize int, concurrencyLevel int) {
 		go func(id int) {
 			defer wg.Done()
 			for j := 0; j < sliceSize/concurrencyLevel; j++ {
-				ss.Append(rand.Intn(1000))
+				appendFn(rand.Intn(1000))
 			}
 		}(i)
 	}
 	wg.Wait()
 	elapsed := time.Since(start)
-	fmt.Printf("Time taken for concurrent appends: %v\n", elapsed)
+	fmt.Printf("%s: time taken for concurrent appends: %v\n", name, elapsed)
 
 	// Start concurrent reads
 	start = time.Now()
@@ -117,7 +61,7 @@ func ProfileSliceOperations(sliceSize int, concurrencyLevel int) {
 		go func(id int) {
 			defer wg.Done()
 			for j := 0; j < sliceSize/concurrencyLevel; j++ {
-				if val, ok := ss.Get(rand.Intn(sliceSize)); ok {
+				if val, ok := get(rand.Intn(sliceSize)); ok {
 					_ = val // Simulate processing the value
 				}
 			}
@@ -125,45 +69,203 @@ func ProfileSliceOperations(sliceSize int, concurrencyLevel int) {
 	}
 	wg.Wait()
 	elapsed = time.Since(start)
-	fmt.Printf("Time taken for concurrent reads: %v\n", elapsed)
+	fmt.Printf("%s: time taken for concurrent reads: %v\n", name, elapsed)
 }
 
-func main() {
-	// Run benchmarks and display results
-	fmt.Println("Running benchmarks...")
-	fmt.Println("BenchmarkSliceAppend:", testing.Benchmark(BenchmarkSliceAppend))
-	fmt.Println("BenchmarkSliceSlicing:", testing.Benchmark(BenchmarkSliceSlicing))
-	fmt.Println("BenchmarkSliceIteration:", testing.Benchmark(BenchmarkSliceIteration))
-
-	// Profile operations
-	fmt.Println("\nProfiling slice operations...")
-	ProfileSliceOperations(1000000, 10)
-
-	// Demonstrate thread-safe buffer with concurrent access
-	fmt.Println("\nDemonstrating thread-safe buffer...")
-	tsb := NewThreadSafeBuffer(1000)
+// ProfileBufferOperations moves values through a ThreadSafeBuffer with
+// concurrencyLevel producers and as many consumers. If metrics is not nil,
+// the buffer records how often producers found it full and its occupancy.
+func ProfileBufferOperations(capacity, total, concurrencyLevel int, metrics *containers.Metrics) {
+	tsb := containers.NewThreadSafeBuffer(capacity)
+	if metrics != nil {
+		tsb.Instrument(metrics)
+	}
 	var wg sync.WaitGroup
+	var received atomic.Int64
+	perProducer := total / concurrencyLevel
+	want := int64(perProducer * concurrencyLevel)
 
 	start := time.Now()
-	wg.Add(10)
+	wg.Add(2 * concurrencyLevel)
+	for i := 0; i < concurrencyLevel; i++ {
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perProducer; j++ {
+				tsb.Write(rand.Intn(1000))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for received.Load() < want {
+				if _, ok := tsb.Read(); ok {
+					received.Add(1)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	fmt.Printf("ThreadSafeBuffer: time taken for %d values: %v\n", received.Load(), time.Since(start))
+}
+
+// startProfiles enables the requested profiles and returns a function that
+// writes them out.
+func startProfiles(mutexProfile, blockProfile, traceFile string) (stop func() error, err error) {
+	var stops []func() error
+	if mutexProfile != "" {
+		runtime.SetMutexProfileFraction(1)
+		stops = append(stops, func() error { return writeProfile("mutex", mutexProfile) })
+	}
+	if blockProfile != "" {
+		runtime.SetBlockProfileRate(1)
+		stops = append(stops, func() error { return writeProfile("block", blockProfile) })
+	}
+	if traceFile != "" {
+		f, err := os.Create(traceFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			return nil, err
+		}
+		stops = append(stops, func() error {
+			trace.Stop()
+			return f.Close()
+		})
+	}
+	return func() error {
+		var errs []error
+		for _, stop := range stops {
+			errs = append(errs, stop())
+		}
+		return errors.Join(errs...)
+	}, nil
+}
+
+func writeProfile(name, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := pprof.Lookup(name).WriteTo(f, 0); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s profile: %w", name, err)
+	}
+	return f.Close()
+}
+
+// runScenarios runs every scenario in path and prints the results.
+func runScenarios(path, format string) error {
+	var write func(io.Writer, []workload.Result) error
+	switch format {
+	case "table":
+		write = workload.WriteResults
+	case "json":
+		write = workload.WriteJSON
+	default:
+		return fmt.Errorf("unknown format %q (want table or json)", format)
+	}
+	scenarios, err := workload.LoadScenarios(path)
+	if err != nil {
+		return err
+	}
+	results, err := workload.RunAll(scenarios)
+	if err != nil {
+		return err
+	}
+	return write(os.Stdout, results)
+}
+
+func main() {
+	size := flag.Int("size", 1000000, "elements appended and read by the slice profile")
+	concurrency := flag.Int("concurrency", 10, "goroutines per operation")
+	printMetrics := flag.Bool("metrics", false, "instrument the containers and print Prometheus metrics")
+	mutexProfile := flag.String("mutexprofile", "", "write a mutex contention profile to this file")
+	blockProfile := flag.String("blockprofile", "", "write a goroutine blocking profile to this file")
+	traceFile := flag.String("trace", "", "write an execution trace, with lock wait and hold regions, to this file")
+	scenarioFile := flag.String("scenario", "", "run the workloads in this JSON scenario file instead of the fixed profiles")
+	format := flag.String("format", "table", "scenario result format: table or json")
+	flag.Parse()
+	if *concurrency < 1 {
+		log.Fatalf("-concurrency must be at least 1, got %d", *concurrency)
+	}
+
+	stop, err := startProfiles(*mutexProfile, *blockProfile, *traceFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *scenarioFile != "" {
+		if err := runScenarios(*scenarioFile, *format); err != nil {
+			log.Fatal(err)
+		}
+		if err := stop(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	var sliceMetrics, bufferMetrics *containers.Metrics
+	if *printMetrics {
+		sliceMetrics = containers.NewMetrics("SafeSlice")
+		bufferMetrics = containers.NewMetrics("ThreadSafeBuffer")
+	}
+
+	// Benchmarks live in the containers package: go test -bench . ./containers
+	fmt.Println("Profiling slice operations...")
+	ProfileSliceOperations(*size, *concurrency, sliceMetrics)
+
+	fmt.Println("\nProfiling buffer operations...")
+	ProfileBufferOperations(1000, 100000, *concurrency, bufferMetrics)
+
+	// Demonstrate the ring buffer with concurrent producers and consumers.
+	// Producers block while the buffer is full and consumers drain it until
+	// it is closed, so no value is lost.
+	fmt.Println("\nDemonstrating ring buffer...")
+	rb := containers.NewRingBuffer[int](1000)
+	ctx := context.Background()
+	var producers, consumers sync.WaitGroup
+	var received atomic.Int64
+
+	start := time.Now()
+	producers.Add(10)
 	for i := 0; i < 10; i++ {
 		go func(id int) {
-			defer wg.Done()
+			defer producers.Done()
 			for j := 0; j < 10000; j++ {
-				tsb.Write(rand.Intn(1000))
+				if err := rb.Write(ctx, rand.Intn(1000)); err != nil {
+					fmt.Println("write failed:", err)
+					return
+				}
 			}
 		}(i)
 	}
-	wg.Add(10)
+	consumers.Add(10)
 	for i := 0; i < 10; i++ {
 		go func(id int) {
-			defer wg.Done()
-			for j := 0; j < 10000; j++ {
-				_, _ = tsb.Read()
+			defer consumers.Done()
+			for {
+				if _, err := rb.Read(ctx); err != nil {
+					return
+				}
+				received.Add(1)
 			}
 		}(i)
 	}
-	wg.Wait()
+	producers.Wait()
+	rb.Close()
+	consumers.Wait()
 	elapsed := time.Since(start)
-	fmt.Printf("Time taken for concurrent buffer operations: %v\n", elapsed)
+	fmt.Printf("Time taken for concurrent buffer operations: %v (%d of %d values received)\n",
+		elapsed, received.Load(), 10*10000)
+
+	if err := stop(); err != nil {
+		log.Fatal(err)
+	}
+	if *printMetrics {
+		fmt.Println()
+		if err := containers.WritePrometheus(os.Stdout, sliceMetrics, bufferMetrics); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
@@ -0,0 +1,297 @@
+// Package lincheck records concurrent operation histories and checks that
+// they are linearizable with respect to a sequential model: that every
+// operation can be given a single instant between its call and return such
+// that, replayed in that order, the model produces the observed results.
+//
+// The search follows Wing and Gong with Lowe's memoisation of visited
+// (linearized set, state) pairs, the same approach as Porcupine. It is
+// exponential in the worst case, so keep histories to a few hundred
+// operations.
+package lincheck
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Operation is one completed call in a history.
+type Operation[I, O any] struct {
+	Client int
+	Input  I
+	Output O
+	// Call and Return are nanoseconds since the recorder was created.
+	Call, Return int64
+}
+
+// Model is a sequential specification with states of type S.
+type Model[S, I, O any] struct {
+	Init func() S
+	// Step reports whether applying input to state may produce output, and
+	// the resulting state. It must not modify state in place.
+	Step func(state S, input I, output O) (bool, S)
+	// Key identifies a state for memoisation. Equal states must have equal
+	// keys. If nil, fmt.Sprint is used.
+	Key func(state S) string
+	// Describe formats an operation for failure reports. If nil, the input
+	// and output are printed with %v.
+	Describe func(input I, output O) string
+}
+
+// Recorder collects the operations of a fixed number of clients. Each client
+// must be driven by a single goroutine; different clients may run
+// concurrently. Clients keep separate logs so recording adds no
+// synchronisation between them.
+type Recorder[I, O any] struct {
+	start time.Time
+	logs  [][]Operation[I, O]
+}
+
+// NewRecorder returns a recorder for clients numbered 0 to clients-1.
+func NewRecorder[I, O any](clients int) *Recorder[I, O] {
+	return &Recorder[I, O]{start: time.Now(), logs: make([][]Operation[I, O], clients)}
+}
+
+// Do calls fn(input) as client and records the call with its result.
+func (r *Recorder[I, O]) Do(client int, input I, fn func(I) O) O {
+	call := time.Since(r.start).Nanoseconds()
+	out := fn(input)
+	ret := time.Since(r.start).Nanoseconds()
+	r.logs[client] = append(r.logs[client], Operation[I, O]{
+		Client: client, Input: input, Output: out, Call: call, Return: ret,
+	})
+	return out
+}
+
+// History returns every recorded operation. It must only be called once all
+// clients have finished.
+func (r *Recorder[I, O]) History() []Operation[I, O] {
+	var h []Operation[I, O]
+	for _, log := range r.logs {
+		h = append(h, log...)
+	}
+	return h
+}
+
+// Result is the outcome of Check.
+type Result struct {
+	Linearizable bool
+	// Order lists history indexes in a valid linearization when the history
+	// is linearizable. Otherwise it is the longest prefix the search could
+	// build, and Blocked is an operation that could not follow it.
+	Order   []int
+	Blocked int
+}
+
+// entry is a call or return event in the doubly linked event list.
+type entry struct {
+	id         int
+	isCall     bool
+	match      *entry // the return entry of a call
+	prev, next *entry
+}
+
+// bitset tracks linearized operations.
+type bitset []uint64
+
+func (b bitset) set(i int)   { b[i/64] |= 1 << (i % 64) }
+func (b bitset) clear(i int) { b[i/64] &^= 1 << (i % 64) }
+
+func (b bitset) key() string {
+	var sb strings.Builder
+	for _, w := range b {
+		for shift := 0; shift < 64; shift += 8 {
+			sb.WriteByte(byte(w >> shift))
+		}
+	}
+	return sb.String()
+}
+
+// events builds the sorted event list for h with a sentinel head. Calls sort
+// before returns at the same instant, so touching operations are treated as
+// concurrent.
+func events[I, O any](h []Operation[I, O]) *entry {
+	type event struct {
+		at     int64
+		id     int
+		isCall bool
+	}
+	evs := make([]event, 0, 2*len(h))
+	for i, op := range h {
+		evs = append(evs, event{op.Call, i, true}, event{op.Return, i, false})
+	}
+	slices.SortStableFunc(evs, func(a, b event) int {
+		if a.at != b.at {
+			if a.at < b.at {
+				return -1
+			}
+			return 1
+		}
+		if a.isCall != b.isCall {
+			if a.isCall {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	})
+
+	head := &entry{id: -1}
+	calls := make([]*entry, len(h))
+	last := head
+	for _, ev := range evs {
+		e := &entry{id: ev.id, isCall: ev.isCall, prev: last}
+		if ev.isCall {
+			calls[ev.id] = e
+		} else {
+			calls[ev.id].match = e
+		}
+		last.next = e
+		last = e
+	}
+	return head
+}
+
+// lift unlinks a call and its return.
+func lift(e *entry) {
+	e.prev.next = e.next
+	e.next.prev = e.prev
+	m := e.match
+	m.prev.next = m.next
+	if m.next != nil {
+		m.next.prev = m.prev
+	}
+}
+
+// unlift relinks a call and its return removed by lift.
+func unlift(e *entry) {
+	m := e.match
+	m.prev.next = m
+	if m.next != nil {
+		m.next.prev = m
+	}
+	e.prev.next = e
+	e.next.prev = e
+}
+
+// Check reports whether h is linearizable with respect to m. It returns
+// ctx.Err() if ctx is done before the search finishes.
+func Check[S, I, O any](ctx context.Context, m Model[S, I, O], h []Operation[I, O]) (Result, error) {
+	key := m.Key
+	if key == nil {
+		key = func(s S) string { return fmt.Sprint(s) }
+	}
+	head := events(h)
+
+	type frame struct {
+		call  *entry
+		state S
+	}
+	var (
+		state      = m.Init()
+		linearized = make(bitset, (len(h)+63)/64)
+		seen       = make(map[string]struct{})
+		stack      []frame
+		best       = Result{Blocked: -1}
+		steps      int
+	)
+	e := head.next
+	for head.next != nil {
+		if steps++; steps%4096 == 0 {
+			if err := ctx.Err(); err != nil {
+				return Result{}, err
+			}
+		}
+		if e.isCall {
+			op := h[e.id]
+			if ok, next := m.Step(state, op.Input, op.Output); ok {
+				linearized.set(e.id)
+				k := linearized.key() + "\x00" + key(next)
+				if _, dup := seen[k]; !dup {
+					seen[k] = struct{}{}
+					stack = append(stack, frame{e, state})
+					state = next
+					lift(e)
+					e = head.next
+					continue
+				}
+				linearized.clear(e.id)
+			}
+			e = e.next
+			continue
+		}
+
+		// An operation returned before it could be linearized: undo the
+		// most recent choice and try the next candidate.
+		if len(stack) >= len(best.Order) {
+			best.Order = best.Order[:0]
+			for _, f := range stack {
+				best.Order = append(best.Order, f.call.id)
+			}
+			best.Blocked = e.id
+		}
+		if len(stack) == 0 {
+			return best, nil
+		}
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		state = top.state
+		linearized.clear(top.call.id)
+		unlift(top.call)
+		e = top.call.next
+	}
+
+	order := make([]int, len(stack))
+	for i, f := range stack {
+		order[i] = f.call.id
+	}
+	return Result{Linearizable: true, Order: order, Blocked: -1}, nil
+}
+
+// Explain formats r for a test failure: the longest valid prefix and the
+// operation that could not follow it, with each operation's client and
+// timing.
+func Explain[S, I, O any](m Model[S, I, O], h []Operation[I, O], r Result) string {
+	describe := m.Describe
+	if describe == nil {
+		describe = func(in I, out O) string { return fmt.Sprintf("%v -> %v", in, out) }
+	}
+	line := func(sb *strings.Builder, i int) {
+		op := h[i]
+		fmt.Fprintf(sb, "  [client %d, %d..%dns] %s\n", op.Client, op.Call, op.Return, describe(op.Input, op.Output))
+	}
+	var sb strings.Builder
+	if r.Linearizable {
+		sb.WriteString("history is linearizable:\n")
+	} else {
+		fmt.Fprintf(&sb, "history of %d operations is not linearizable; longest valid prefix:\n", len(h))
+	}
+	for _, i := range r.Order {
+		line(&sb, i)
+	}
+	if r.Blocked >= 0 {
+		sb.WriteString("which leaves no way to linearize this operation before it returns:\n")
+		line(&sb, r.Blocked)
+	}
+	return sb.String()
+}
+
+// Run starts one goroutine per client, releases them together so their
+// operations overlap, and waits for all of them to return.
+func (r *Recorder[I, O]) Run(client func(id int)) {
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for id := range r.logs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			client(id)
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
@@ -0,0 +1,217 @@
+package lincheck
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func sliceOp(in SliceInput, out SliceOutput, call, ret int64) Operation[SliceInput, SliceOutput] {
+	return Operation[SliceInput, SliceOutput]{Input: in, Output: out, Call: call, Return: ret}
+}
+
+func queueOp(in QueueInput, out QueueOutput, call, ret int64) Operation[QueueInput, QueueOutput] {
+	return Operation[QueueInput, QueueOutput]{Input: in, Output: out, Call: call, Return: ret}
+}
+
+// TestCheckSlice tests handcrafted slice histories.
+func TestCheckSlice(t *testing.T) {
+	tests := []struct {
+		name string
+		h    []Operation[SliceInput, SliceOutput]
+		want bool
+	}{
+		{"empty", nil, true},
+		{"sequential", []Operation[SliceInput, SliceOutput]{
+			sliceOp(SliceInput{Op: Append, Value: 7}, SliceOutput{}, 0, 10),
+			sliceOp(SliceInput{Op: Get, Index: 0}, SliceOutput{7, true}, 20, 30),
+			sliceOp(SliceInput{Op: Len}, SliceOutput{Value: 1}, 40, 50),
+		}, true},
+		{"stale read", []Operation[SliceInput, SliceOutput]{
+			sliceOp(SliceInput{Op: Append, Value: 7}, SliceOutput{}, 0, 10),
+			sliceOp(SliceInput{Op: Get, Index: 0}, SliceOutput{}, 20, 30),
+		}, false},
+		{"overlapping read sees append", []Operation[SliceInput, SliceOutput]{
+			sliceOp(SliceInput{Op: Append, Value: 7}, SliceOutput{}, 0, 10),
+			sliceOp(SliceInput{Op: Get, Index: 0}, SliceOutput{7, true}, 5, 15),
+		}, true},
+		{"overlapping read misses append", []Operation[SliceInput, SliceOutput]{
+			sliceOp(SliceInput{Op: Append, Value: 7}, SliceOutput{}, 0, 10),
+			sliceOp(SliceInput{Op: Get, Index: 0}, SliceOutput{}, 5, 15),
+		}, true},
+		{"touching operations are concurrent", []Operation[SliceInput, SliceOutput]{
+			sliceOp(SliceInput{Op: Append, Value: 7}, SliceOutput{}, 0, 10),
+			sliceOp(SliceInput{Op: Get, Index: 0}, SliceOutput{}, 10, 20),
+		}, true},
+		{"reads disagree on order", []Operation[SliceInput, SliceOutput]{
+			sliceOp(SliceInput{Op: Append, Value: 1}, SliceOutput{}, 0, 100),
+			sliceOp(SliceInput{Op: Append, Value: 2}, SliceOutput{}, 0, 100),
+			sliceOp(SliceInput{Op: Get, Index: 0}, SliceOutput{1, true}, 110, 120),
+			sliceOp(SliceInput{Op: Get, Index: 0}, SliceOutput{2, true}, 130, 140),
+		}, false},
+		{"insert and remove", []Operation[SliceInput, SliceOutput]{
+			sliceOp(SliceInput{Op: Append, Value: 1}, SliceOutput{}, 0, 10),
+			sliceOp(SliceInput{Op: Insert, Index: 0, Value: 0}, SliceOutput{OK: true}, 20, 30),
+			sliceOp(SliceInput{Op: Insert, Index: 5, Value: 9}, SliceOutput{}, 40, 50),
+			sliceOp(SliceInput{Op: RemoveAt, Index: 1}, SliceOutput{1, true}, 60, 70),
+			sliceOp(SliceInput{Op: Len}, SliceOutput{Value: 1}, 80, 90),
+		}, true},
+	}
+	m := SliceModel()
+	for _, tt := range tests {
+		r, err := Check(context.Background(), m, tt.h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Linearizable != tt.want {
+			t.Errorf("%s: linearizable = %t, want %t\n%s", tt.name, r.Linearizable, tt.want, Explain(m, tt.h, r))
+		}
+		if r.Linearizable && len(r.Order) != len(tt.h) {
+			t.Errorf("%s: order %v does not cover the history", tt.name, r.Order)
+		}
+	}
+}
+
+// TestCheckQueue tests handcrafted queue histories.
+func TestCheckQueue(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		h        []Operation[QueueInput, QueueOutput]
+		want     bool
+	}{
+		{"fifo", 0, []Operation[QueueInput, QueueOutput]{
+			queueOp(QueueInput{Enqueue, 1}, QueueOutput{OK: true}, 0, 1),
+			queueOp(QueueInput{Enqueue, 2}, QueueOutput{OK: true}, 2, 3),
+			queueOp(QueueInput{Op: Dequeue}, QueueOutput{1, true}, 4, 5),
+		}, true},
+		{"lifo", 0, []Operation[QueueInput, QueueOutput]{
+			queueOp(QueueInput{Enqueue, 1}, QueueOutput{OK: true}, 0, 1),
+			queueOp(QueueInput{Enqueue, 2}, QueueOutput{OK: true}, 2, 3),
+			queueOp(QueueInput{Op: Dequeue}, QueueOutput{2, true}, 4, 5),
+		}, false},
+		{"concurrent enqueues either order", 0, []Operation[QueueInput, QueueOutput]{
+			queueOp(QueueInput{Enqueue, 1}, QueueOutput{OK: true}, 0, 3),
+			queueOp(QueueInput{Enqueue, 2}, QueueOutput{OK: true}, 1, 3),
+			queueOp(QueueInput{Op: Dequeue}, QueueOutput{2, true}, 4, 5),
+		}, true},
+		{"empty while holding a value", 0, []Operation[QueueInput, QueueOutput]{
+			queueOp(QueueInput{Enqueue, 1}, QueueOutput{OK: true}, 0, 1),
+			queueOp(QueueInput{Op: Dequeue}, QueueOutput{}, 2, 3),
+		}, false},
+		{"full rejects", 1, []Operation[QueueInput, QueueOutput]{
+			queueOp(QueueInput{Enqueue, 1}, QueueOutput{OK: true}, 0, 1),
+			queueOp(QueueInput{Enqueue, 2}, QueueOutput{}, 2, 3),
+			queueOp(QueueInput{Op: Dequeue}, QueueOutput{1, true}, 4, 5),
+		}, true},
+		{"spurious full", 2, []Operation[QueueInput, QueueOutput]{
+			queueOp(QueueInput{Enqueue, 1}, QueueOutput{OK: true}, 0, 1),
+			queueOp(QueueInput{Enqueue, 2}, QueueOutput{}, 2, 3),
+		}, false},
+	}
+	for _, tt := range tests {
+		m := QueueModel(tt.capacity)
+		r, err := Check(context.Background(), m, tt.h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Linearizable != tt.want {
+			t.Errorf("%s: linearizable = %t, want %t\n%s", tt.name, r.Linearizable, tt.want, Explain(m, tt.h, r))
+		}
+	}
+}
+
+// TestExplain tests that a failure report names the offending operation.
+func TestExplain(t *testing.T) {
+	m := SliceModel()
+	h := []Operation[SliceInput, SliceOutput]{
+		sliceOp(SliceInput{Op: Append, Value: 7}, SliceOutput{}, 0, 10),
+		sliceOp(SliceInput{Op: Len}, SliceOutput{Value: 2}, 20, 30),
+	}
+	r, err := Check(context.Background(), m, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Explain(m, h, r)
+	for _, want := range []string{"not linearizable", "Append(7)", "Len() -> 2"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("explanation does not mention %q:\n%s", want, got)
+		}
+	}
+	if r.Blocked != 1 {
+		t.Errorf("blocked = %d, want 1", r.Blocked)
+	}
+}
+
+// TestCheckCanceled tests that a canceled search returns the context error.
+func TestCheckCanceled(t *testing.T) {
+	var h []Operation[SliceInput, SliceOutput]
+	for i := range 10000 {
+		h = append(h, sliceOp(SliceInput{Op: Len}, SliceOutput{}, int64(2*i), int64(2*i+1)))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Check(ctx, SliceModel(), h); err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+// mutexQueue is a correct queue for exercising the recorder.
+type mutexQueue struct {
+	mu    sync.Mutex
+	items []int
+	lifo  bool
+}
+
+func (q *mutexQueue) do(in QueueInput) QueueOutput {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if in.Op == Enqueue {
+		q.items = append(q.items, in.Value)
+		return QueueOutput{OK: true}
+	}
+	if len(q.items) == 0 {
+		return QueueOutput{}
+	}
+	i := 0
+	if q.lifo {
+		i = len(q.items) - 1
+	}
+	v := q.items[i]
+	q.items = append(q.items[:i], q.items[i+1:]...)
+	return QueueOutput{v, true}
+}
+
+// TestRecorder tests recording concurrent clients against a correct queue,
+// and that a broken one is caught.
+func TestRecorder(t *testing.T) {
+	for _, lifo := range []bool{false, true} {
+		q := &mutexQueue{lifo: lifo}
+		clients := 4
+		if lifo {
+			// A single client makes the LIFO failure deterministic.
+			clients = 1
+		}
+		rec := NewRecorder[QueueInput, QueueOutput](clients)
+		rec.Run(func(id int) {
+			for i := range 25 {
+				rec.Do(id, QueueInput{Enqueue, id*100 + i}, q.do)
+				rec.Do(id, QueueInput{Enqueue, id*100 + i + 50}, q.do)
+				rec.Do(id, QueueInput{Op: Dequeue}, q.do)
+			}
+		})
+		h := rec.History()
+		if len(h) != clients*75 {
+			t.Fatalf("recorded %d operations, want %d", len(h), clients*75)
+		}
+		m := QueueModel(0)
+		r, err := Check(context.Background(), m, h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Linearizable == lifo {
+			t.Errorf("lifo=%t: linearizable = %t\n%s", lifo, r.Linearizable, Explain(m, h, r))
+		}
+	}
+}
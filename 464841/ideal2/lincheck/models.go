@@ -0,0 +1,161 @@
+package lincheck
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SliceOp selects a slice operation.
+type SliceOp int
+
+const (
+	Append SliceOp = iota
+	Get
+	Len
+	Insert
+	RemoveAt
+)
+
+func (op SliceOp) String() string {
+	switch op {
+	case Append:
+		return "Append"
+	case Get:
+		return "Get"
+	case Len:
+		return "Len"
+	case Insert:
+		return "Insert"
+	case RemoveAt:
+		return "RemoveAt"
+	}
+	return fmt.Sprintf("SliceOp(%d)", int(op))
+}
+
+// SliceInput is a call on an indexable slice of ints.
+type SliceInput struct {
+	Op    SliceOp
+	Index int
+	Value int
+}
+
+// SliceOutput is the result of a slice call. Len reports its result in
+// Value; Append ignores both fields.
+type SliceOutput struct {
+	Value int
+	OK    bool
+}
+
+// SliceModel specifies a slice with the semantics of containers.SafeSlice:
+// Get and RemoveAt fail out of range, and Insert accepts indexes up to and
+// including the length.
+func SliceModel() Model[[]int, SliceInput, SliceOutput] {
+	return Model[[]int, SliceInput, SliceOutput]{
+		Init: func() []int { return nil },
+		Step: func(s []int, in SliceInput, out SliceOutput) (bool, []int) {
+			inRange := in.Index >= 0 && in.Index < len(s)
+			switch in.Op {
+			case Append:
+				return true, append(slices.Clip(s), in.Value)
+			case Get:
+				if !inRange {
+					return !out.OK, s
+				}
+				return out.OK && out.Value == s[in.Index], s
+			case Len:
+				return out.Value == len(s), s
+			case Insert:
+				if in.Index < 0 || in.Index > len(s) {
+					return !out.OK, s
+				}
+				return out.OK, slices.Insert(slices.Clone(s), in.Index, in.Value)
+			case RemoveAt:
+				if !inRange {
+					return !out.OK, s
+				}
+				return out.OK && out.Value == s[in.Index], slices.Delete(slices.Clone(s), in.Index, in.Index+1)
+			}
+			return false, s
+		},
+		Key:      intsKey,
+		Describe: describeSlice,
+	}
+}
+
+func describeSlice(in SliceInput, out SliceOutput) string {
+	switch in.Op {
+	case Append:
+		return fmt.Sprintf("Append(%d)", in.Value)
+	case Get, RemoveAt:
+		return fmt.Sprintf("%v(%d) -> %d, %t", in.Op, in.Index, out.Value, out.OK)
+	case Len:
+		return fmt.Sprintf("Len() -> %d", out.Value)
+	case Insert:
+		return fmt.Sprintf("Insert(%d, %d) -> %t", in.Index, in.Value, out.OK)
+	}
+	return fmt.Sprintf("%v -> %v", in, out)
+}
+
+// QueueOp selects a queue operation.
+type QueueOp int
+
+const (
+	Enqueue QueueOp = iota
+	Dequeue
+)
+
+// QueueInput is a call on a FIFO queue of ints.
+type QueueInput struct {
+	Op    QueueOp
+	Value int
+}
+
+// QueueOutput is the result of a queue call. OK is false when an enqueue was
+// rejected because the queue was full or a dequeue found it empty.
+type QueueOutput struct {
+	Value int
+	OK    bool
+}
+
+// QueueModel specifies a FIFO queue holding at most capacity elements; a
+// non-positive capacity means unbounded. Blocking operations should record
+// OK as true once they complete.
+func QueueModel(capacity int) Model[[]int, QueueInput, QueueOutput] {
+	return Model[[]int, QueueInput, QueueOutput]{
+		Init: func() []int { return nil },
+		Step: func(s []int, in QueueInput, out QueueOutput) (bool, []int) {
+			switch in.Op {
+			case Enqueue:
+				full := capacity > 0 && len(s) >= capacity
+				if !out.OK {
+					return full, s
+				}
+				return !full, append(slices.Clip(s), in.Value)
+			case Dequeue:
+				if !out.OK {
+					return len(s) == 0, s
+				}
+				return len(s) > 0 && s[0] == out.Value, s[1:]
+			}
+			return false, s
+		},
+		Key: intsKey,
+		Describe: func(in QueueInput, out QueueOutput) string {
+			if in.Op == Enqueue {
+				return fmt.Sprintf("Enqueue(%d) -> %t", in.Value, out.OK)
+			}
+			return fmt.Sprintf("Dequeue() -> %d, %t", out.Value, out.OK)
+		},
+	}
+}
+
+func intsKey(s []int) string {
+	var sb strings.Builder
+	for _, v := range s {
+		sb.WriteString(strconv.Itoa(v))
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
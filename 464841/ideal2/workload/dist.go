@@ -0,0 +1,51 @@
+package workload
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// keyGen picks the index of the next read.
+type keyGen interface {
+	// Next returns an index in [0, n). n is always positive.
+	Next(n int) int
+}
+
+type uniformKeys struct{ rng *rand.Rand }
+
+func (u uniformKeys) Next(n int) int { return u.rng.Intn(n) }
+
+// sequentialKeys walks the indexes in order, wrapping at n. Each goroutine
+// starts at its own offset so they do not all read the same element.
+type sequentialKeys struct{ pos int }
+
+func (s *sequentialKeys) Next(n int) int {
+	i := s.pos % n
+	s.pos = i + 1
+	return i
+}
+
+// zipfKeys favours low indexes. rand.Zipf has a fixed upper bound, so draws
+// are taken over the prefilled range and folded into n if the container is
+// smaller.
+type zipfKeys struct{ z *rand.Zipf }
+
+func (z zipfKeys) Next(n int) int { return int(z.z.Uint64() % uint64(n)) }
+
+// newKeyGen returns the generator for d. keys is the number of indexes
+// readers are expected to target, normally the scenario's prefill.
+func newKeyGen(d Distribution, rng *rand.Rand, keys, goroutine, goroutines int) (keyGen, error) {
+	switch d.Kind {
+	case "uniform", "":
+		return uniformKeys{rng}, nil
+	case "sequential":
+		return &sequentialKeys{pos: goroutine * keys / goroutines}, nil
+	case "zipf":
+		z := rand.NewZipf(rng, d.S, d.V, uint64(max(keys-1, 0)))
+		if z == nil {
+			return nil, fmt.Errorf("invalid zipf parameters s=%v v=%v", d.S, d.V)
+		}
+		return zipfKeys{z}, nil
+	}
+	return nil, fmt.Errorf("unknown distribution %q", d.Kind)
+}
@@ -0,0 +1,87 @@
+package workload
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// subBuckets is the number of linear buckets per power of two, giving a
+// relative error of at most 1/subBuckets.
+const (
+	subBucketBits = 4
+	subBuckets    = 1 << subBucketBits
+)
+
+// latencyHistogram records durations in log-linear buckets so a run of
+// millions of operations costs a fixed few kilobytes. It is not safe for
+// concurrent use; each goroutine keeps its own and they are merged.
+type latencyHistogram struct {
+	counts [64 * subBuckets]uint64
+	total  uint64
+	sum    time.Duration
+	max    time.Duration
+}
+
+func bucketOf(ns uint64) int {
+	if ns < subBuckets {
+		return int(ns)
+	}
+	exp := bits.Len64(ns) - 1 - subBucketBits
+	mantissa := ns >> uint(exp) & (subBuckets - 1)
+	return (exp+1)*subBuckets + int(mantissa)
+}
+
+// bucketUpper returns the largest value that falls in bucket b.
+func bucketUpper(b int) uint64 {
+	if b < subBuckets {
+		return uint64(b)
+	}
+	exp := b/subBuckets - 1
+	mantissa := uint64(b % subBuckets)
+	return (subBuckets+mantissa+1)<<uint(exp) - 1
+}
+
+func (h *latencyHistogram) record(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	h.counts[bucketOf(uint64(d))]++
+	h.total++
+	h.sum += d
+	h.max = max(h.max, d)
+}
+
+func (h *latencyHistogram) merge(o *latencyHistogram) {
+	for i, c := range o.counts {
+		h.counts[i] += c
+	}
+	h.total += o.total
+	h.sum += o.sum
+	h.max = max(h.max, o.max)
+}
+
+// quantile returns an upper bound for the q-th quantile, 0 <= q <= 1,
+// capped at the largest recorded value.
+func (h *latencyHistogram) quantile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q * float64(h.total)))
+	rank = max(rank, 1)
+	var seen uint64
+	for b, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			return min(time.Duration(bucketUpper(b)), h.max)
+		}
+	}
+	return h.max
+}
+
+func (h *latencyHistogram) mean() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.total)
+}
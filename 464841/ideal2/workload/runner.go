@@ -0,0 +1,223 @@
+package workload
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"example.com/464841/ideal2/containers"
+)
+
+// Container is the indexable log interface every scenario drives.
+type Container interface {
+	Append(val int)
+	Get(index int) (int, bool)
+	Len() int
+}
+
+// shardedContainer adapts ShardedSlice, whose Append also returns the index.
+type shardedContainer struct{ *containers.ShardedSlice[int] }
+
+func (s shardedContainer) Append(val int) { s.ShardedSlice.Append(val) }
+
+// Containers maps the names used in scenario files to constructors. The
+// argument is a capacity hint.
+var Containers = map[string]func(capacity int) Container{
+	"mutex":    func(c int) Container { return containers.NewMutexSlice(c) },
+	"rwmutex":  func(c int) Container { return containers.NewSafeSlice(c) },
+	"sharded":  func(int) Container { return shardedContainer{containers.NewShardedSlice[int](0)} },
+	"snapshot": func(c int) Container { return containers.NewSnapshotSlice(c) },
+}
+
+// ContainerNames returns the registered container names in sorted order.
+func ContainerNames() []string {
+	names := make([]string, 0, len(Containers))
+	for name := range Containers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Latency summarises one operation type.
+type Latency struct {
+	Count uint64        `json:"count"`
+	Mean  time.Duration `json:"mean_ns"`
+	P50   time.Duration `json:"p50_ns"`
+	P90   time.Duration `json:"p90_ns"`
+	P99   time.Duration `json:"p99_ns"`
+	P999  time.Duration `json:"p999_ns"`
+	Max   time.Duration `json:"max_ns"`
+}
+
+func summarizeLatency(h *latencyHistogram) Latency {
+	return Latency{
+		Count: h.total,
+		Mean:  h.mean(),
+		P50:   h.quantile(0.50),
+		P90:   h.quantile(0.90),
+		P99:   h.quantile(0.99),
+		P999:  h.quantile(0.999),
+		Max:   h.max,
+	}
+}
+
+// Result is the outcome of one scenario against one container.
+type Result struct {
+	Scenario   string        `json:"scenario"`
+	Container  string        `json:"container"`
+	Goroutines int           `json:"goroutines"`
+	Elapsed    time.Duration `json:"elapsed_ns"`
+	Ops        uint64        `json:"ops"`
+	// Throughput is operations per second across all goroutines.
+	Throughput float64 `json:"ops_per_sec"`
+	// Misses counts reads whose index was not (yet) set.
+	Misses uint64  `json:"misses"`
+	Reads  Latency `json:"reads"`
+	Writes Latency `json:"writes"`
+}
+
+// worker is one goroutine's private state, merged after the run.
+type worker struct {
+	reads, writes latencyHistogram
+	misses        uint64
+}
+
+// Run executes s against a fresh container built by newContainer.
+func Run(s Scenario, name string, newContainer func(capacity int) Container) (Result, error) {
+	if err := s.Validate(); err != nil {
+		return Result{}, err
+	}
+	c := newContainer(s.Prefill)
+	for i := range s.Prefill {
+		c.Append(i)
+	}
+
+	workers := make([]worker, s.Goroutines)
+	gens := make([]keyGen, s.Goroutines)
+	rngs := make([]*rand.Rand, s.Goroutines)
+	for g := range s.Goroutines {
+		rngs[g] = rand.New(rand.NewSource(s.Seed + int64(g)))
+		gen, err := newKeyGen(s.Distribution, rngs[g], s.Prefill, g, s.Goroutines)
+		if err != nil {
+			return Result{}, err
+		}
+		gens[g] = gen
+	}
+
+	var (
+		wg    sync.WaitGroup
+		ready sync.WaitGroup
+		start = make(chan struct{})
+	)
+	ready.Add(s.Goroutines)
+	var deadline time.Time
+	for g := range s.Goroutines {
+		wg.Add(1)
+		go func(w *worker, rng *rand.Rand, keys keyGen) {
+			defer wg.Done()
+			ready.Done()
+			<-start
+			for n := 0; s.OpsPerGoroutine == 0 || n < s.OpsPerGoroutine; n++ {
+				read := rng.Float64() < s.ReadRatio
+				var index int
+				if read {
+					index = keys.Next(max(c.Len(), 1))
+				}
+				t0 := time.Now()
+				if read {
+					if _, ok := c.Get(index); !ok {
+						w.misses++
+					}
+				} else {
+					c.Append(n)
+				}
+				t1 := time.Now()
+				if read {
+					w.reads.record(t1.Sub(t0))
+				} else {
+					w.writes.record(t1.Sub(t0))
+				}
+				if s.Duration > 0 && !t1.Before(deadline) {
+					return
+				}
+			}
+		}(&workers[g], rngs[g], gens[g])
+	}
+	ready.Wait()
+	begin := time.Now()
+	deadline = begin.Add(time.Duration(s.Duration))
+	close(start)
+	wg.Wait()
+	elapsed := time.Since(begin)
+
+	var reads, writes latencyHistogram
+	var misses uint64
+	for i := range workers {
+		reads.merge(&workers[i].reads)
+		writes.merge(&workers[i].writes)
+		misses += workers[i].misses
+	}
+	ops := reads.total + writes.total
+	return Result{
+		Scenario:   s.Name,
+		Container:  name,
+		Goroutines: s.Goroutines,
+		Elapsed:    elapsed,
+		Ops:        ops,
+		Throughput: float64(ops) / elapsed.Seconds(),
+		Misses:     misses,
+		Reads:      summarizeLatency(&reads),
+		Writes:     summarizeLatency(&writes),
+	}, nil
+}
+
+// RunAll runs every scenario against each of its containers, or against all
+// registered containers when a scenario names none.
+func RunAll(scenarios []Scenario) ([]Result, error) {
+	var results []Result
+	for _, s := range scenarios {
+		names := s.Containers
+		if len(names) == 0 {
+			names = ContainerNames()
+		}
+		for _, name := range names {
+			newContainer, ok := Containers[name]
+			if !ok {
+				return results, fmt.Errorf("scenario %q: unknown container %q", s.Name, name)
+			}
+			r, err := Run(s, name, newContainer)
+			if err != nil {
+				return results, err
+			}
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+// WriteResults prints results as an aligned table.
+func WriteResults(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "scenario\tcontainer\tgoroutines\tops/s\t"+
+		"read p50\tread p99\tread p99.9\twrite p50\twrite p99\twrite p99.9\tmisses\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%.0f\t%v\t%v\t%v\t%v\t%v\t%v\t%d\t\n",
+			r.Scenario, r.Container, r.Goroutines, r.Throughput,
+			r.Reads.P50, r.Reads.P99, r.Reads.P999,
+			r.Writes.P50, r.Writes.P99, r.Writes.P999, r.Misses)
+	}
+	return tw.Flush()
+}
+
+// WriteJSON writes results as an indented JSON array.
+func WriteJSON(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
@@ -0,0 +1,175 @@
+package workload
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLatencyHistogram tests that quantiles are upper bounds within the
+// bucket resolution.
+func TestLatencyHistogram(t *testing.T) {
+	var h latencyHistogram
+	for i := 1; i <= 1000; i++ {
+		h.record(time.Duration(i) * time.Microsecond)
+	}
+	for _, q := range []float64{0.5, 0.9, 0.99, 1} {
+		want := time.Duration(q*1000) * time.Microsecond
+		got := h.quantile(q)
+		if got < want || float64(got) > float64(want)*(1+1.0/subBuckets) {
+			t.Errorf("quantile(%v) = %v, want within one bucket above %v", q, got, want)
+		}
+	}
+	if h.max != time.Millisecond {
+		t.Errorf("max = %v, want 1ms", h.max)
+	}
+	if m := h.mean(); m != 500500*time.Nanosecond {
+		t.Errorf("mean = %v, want 500.5µs", m)
+	}
+
+	var other latencyHistogram
+	other.record(time.Second)
+	h.merge(&other)
+	if h.total != 1001 || h.quantile(1) != time.Second {
+		t.Errorf("after merge total = %d, max quantile = %v", h.total, h.quantile(1))
+	}
+}
+
+// TestBucketBounds tests that every value falls at or below its bucket's
+// upper bound and above the previous bucket's.
+func TestBucketBounds(t *testing.T) {
+	for _, ns := range []uint64{0, 1, 15, 16, 17, 31, 32, 33, 1000, 1 << 40, 1<<63 - 1} {
+		b := bucketOf(ns)
+		if ns > bucketUpper(b) || (b > 0 && ns <= bucketUpper(b-1)) {
+			t.Errorf("%d in bucket %d with bounds (%d, %d]", ns, b, bucketUpper(b-1), bucketUpper(b))
+		}
+	}
+}
+
+// TestKeyDistributions tests the range and shape of each distribution.
+func TestKeyDistributions(t *testing.T) {
+	const keys, draws = 1000, 100000
+	counts := func(d Distribution) []int {
+		gen, err := newKeyGen(d, rand.New(rand.NewSource(1)), keys, 0, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := make([]int, keys)
+		for range draws {
+			i := gen.Next(keys)
+			if i < 0 || i >= keys {
+				t.Fatalf("%s: index %d out of range", d.Kind, i)
+			}
+			c[i]++
+		}
+		return c
+	}
+
+	seq := counts(Distribution{Kind: "sequential"})
+	for i, c := range seq {
+		if c != draws/keys {
+			t.Fatalf("sequential: index %d drawn %d times, want %d", i, c, draws/keys)
+		}
+	}
+
+	uni := counts(Distribution{Kind: "uniform"})
+	if uni[0] > 3*draws/keys {
+		t.Errorf("uniform: index 0 drawn %d times, want about %d", uni[0], draws/keys)
+	}
+
+	zipf := counts(Distribution{Kind: "zipf", S: 1.2, V: 1})
+	if zipf[0] < 10*zipf[keys-1] || zipf[0] < 10*draws/keys {
+		t.Errorf("zipf: index 0 drawn %d times, last %d; want a heavy head", zipf[0], zipf[keys-1])
+	}
+}
+
+// TestSequentialOffsets tests that sequential readers start spread out.
+func TestSequentialOffsets(t *testing.T) {
+	a, _ := newKeyGen(Distribution{Kind: "sequential"}, nil, 100, 0, 4)
+	b, _ := newKeyGen(Distribution{Kind: "sequential"}, nil, 100, 1, 4)
+	if a.Next(100) != 0 || b.Next(100) != 25 {
+		t.Error("sequential generators do not start at their goroutine's offset")
+	}
+}
+
+// TestRunOps tests a fixed-count run against every registered container.
+func TestRunOps(t *testing.T) {
+	s := Scenario{
+		Name:            "ops",
+		Prefill:         100,
+		Goroutines:      4,
+		OpsPerGoroutine: 500,
+		ReadRatio:       0.5,
+		Distribution:    Distribution{Kind: "uniform"},
+	}
+	for _, name := range ContainerNames() {
+		r, err := Run(s, name, Containers[name])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Ops != 2000 || r.Reads.Count+r.Writes.Count != 2000 {
+			t.Errorf("%s: ops = %d (%d reads, %d writes), want 2000", name, r.Ops, r.Reads.Count, r.Writes.Count)
+		}
+		if r.Reads.Count < 800 || r.Reads.Count > 1200 {
+			t.Errorf("%s: %d reads, want about 1000", name, r.Reads.Count)
+		}
+		if r.Throughput <= 0 || r.Reads.P50 > r.Reads.P99 || r.Reads.P99 > r.Reads.Max {
+			t.Errorf("%s: inconsistent result %+v", name, r)
+		}
+	}
+}
+
+// TestRunDuration tests that a timed run stops near its deadline.
+func TestRunDuration(t *testing.T) {
+	s := Scenario{
+		Name:         "timed",
+		Prefill:      10,
+		Goroutines:   2,
+		Duration:     Duration(50 * time.Millisecond),
+		ReadRatio:    0.9,
+		Distribution: Distribution{Kind: "zipf", S: 1.1, V: 1},
+	}
+	r, err := Run(s, "rwmutex", Containers["rwmutex"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Elapsed < 50*time.Millisecond || r.Elapsed > 2*time.Second {
+		t.Errorf("elapsed = %v, want just over 50ms", r.Elapsed)
+	}
+	if r.Ops == 0 || r.Misses != 0 {
+		t.Errorf("ops = %d, misses = %d", r.Ops, r.Misses)
+	}
+}
+
+// TestWriteResults tests both output formats.
+func TestWriteResults(t *testing.T) {
+	results, err := RunAll([]Scenario{{
+		Name: "w", Containers: []string{"mutex", "sharded"}, Goroutines: 1,
+		OpsPerGoroutine: 10, Distribution: Distribution{Kind: "uniform"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var table bytes.Buffer
+	if err := WriteResults(&table, results); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(table.String(), "\n"); lines != 3 {
+		t.Errorf("table has %d lines, want header and 2 rows:\n%s", lines, table.String())
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []Result
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 2 || decoded[1].Container != "sharded" || decoded[0].Writes.Count != 10 {
+		t.Errorf("decoded %+v", decoded)
+	}
+}
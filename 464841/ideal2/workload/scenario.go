@@ -0,0 +1,155 @@
+// Package workload runs configurable read/write scenarios against the
+// concurrent containers and reports throughput and latency percentiles.
+package workload
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Duration is a time.Duration that reads and writes JSON strings such as
+// "1.5s".
+type Duration time.Duration
+
+// UnmarshalJSON accepts a duration string or a number of nanoseconds.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var ns int64
+		if err := json.Unmarshal(data, &ns); err != nil {
+			return fmt.Errorf("duration must be a string like \"2s\" or nanoseconds: %s", data)
+		}
+		*d = Duration(ns)
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Distribution selects which indexes reads target.
+type Distribution struct {
+	// Kind is "uniform", "zipf" or "sequential".
+	Kind string `json:"kind"`
+	// S and V are the Zipf parameters: P(k) is proportional to (V+k)^-S.
+	// S must be greater than 1 and V at least 1.
+	S float64 `json:"s,omitempty"`
+	V float64 `json:"v,omitempty"`
+}
+
+// Scenario describes one workload.
+type Scenario struct {
+	Name string `json:"name"`
+	// Containers lists the containers to run against; empty means all.
+	Containers []string `json:"containers,omitempty"`
+	// Prefill elements are appended before measuring, so reads have
+	// something to hit.
+	Prefill    int `json:"prefill"`
+	Goroutines int `json:"goroutines"`
+	// The run stops after Duration, or after OpsPerGoroutine operations in
+	// each goroutine, whichever comes first. At least one must be set.
+	Duration        Duration `json:"duration,omitempty"`
+	OpsPerGoroutine int      `json:"ops_per_goroutine,omitempty"`
+	// ReadRatio is the fraction of operations that are reads (Get); the rest
+	// are writes (Append).
+	ReadRatio    float64      `json:"read_ratio"`
+	Distribution Distribution `json:"distribution"`
+	Seed         int64        `json:"seed,omitempty"`
+}
+
+// Validate reports every problem with s.
+func (s Scenario) Validate() error {
+	var errs []error
+	if s.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+	if s.Goroutines <= 0 {
+		errs = append(errs, fmt.Errorf("goroutines must be positive, got %d", s.Goroutines))
+	}
+	if s.Prefill < 0 {
+		errs = append(errs, fmt.Errorf("prefill must not be negative, got %d", s.Prefill))
+	}
+	if s.Duration <= 0 && s.OpsPerGoroutine <= 0 {
+		errs = append(errs, errors.New("one of duration or ops_per_goroutine must be positive"))
+	}
+	if s.Duration < 0 || s.OpsPerGoroutine < 0 {
+		errs = append(errs, errors.New("duration and ops_per_goroutine must not be negative"))
+	}
+	if !(s.ReadRatio >= 0 && s.ReadRatio <= 1) {
+		errs = append(errs, fmt.Errorf("read_ratio must be in [0, 1], got %v", s.ReadRatio))
+	}
+	switch s.Distribution.Kind {
+	case "uniform", "sequential":
+	case "zipf":
+		if s.Distribution.S <= 1 || s.Distribution.V < 1 {
+			errs = append(errs, fmt.Errorf("zipf needs s > 1 and v >= 1, got s=%v v=%v", s.Distribution.S, s.Distribution.V))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown distribution %q (want uniform, zipf or sequential)", s.Distribution.Kind))
+	}
+	if s.ReadRatio > 0 && s.Prefill == 0 {
+		errs = append(errs, errors.New("reads need a positive prefill"))
+	}
+	for _, c := range s.Containers {
+		if _, ok := Containers[c]; !ok {
+			errs = append(errs, fmt.Errorf("unknown container %q", c))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("scenario %q: %w", s.Name, err)
+	}
+	return nil
+}
+
+// File is the top-level layout of a scenario file.
+type File struct {
+	Scenarios []Scenario `json:"scenarios"`
+}
+
+// ReadScenarios decodes and validates a scenario file. Unknown fields are
+// rejected so typos do not silently fall back to defaults.
+func ReadScenarios(r io.Reader) ([]Scenario, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var f File
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("decoding scenarios: %w", err)
+	}
+	if len(f.Scenarios) == 0 {
+		return nil, errors.New("no scenarios defined")
+	}
+	var errs []error
+	for i := range f.Scenarios {
+		s := &f.Scenarios[i]
+		if s.Distribution.Kind == "" {
+			s.Distribution.Kind = "uniform"
+		}
+		errs = append(errs, s.Validate())
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return f.Scenarios, nil
+}
+
+// LoadScenarios reads a scenario file from disk.
+func LoadScenarios(path string) ([]Scenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadScenarios(f)
+}
@@ -0,0 +1,67 @@
+package workload
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestReadScenarios tests decoding, defaults and duration parsing.
+func TestReadScenarios(t *testing.T) {
+	in := `{"scenarios": [
+		{"name": "a", "prefill": 10, "goroutines": 2, "duration": "250ms", "read_ratio": 0.5},
+		{"name": "b", "prefill": 10, "goroutines": 1, "ops_per_goroutine": 5, "read_ratio": 1,
+		 "distribution": {"kind": "zipf", "s": 1.5, "v": 1}, "containers": ["sharded"]}
+	]}`
+	got, err := ReadScenarios(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d scenarios, want 2", len(got))
+	}
+	if time.Duration(got[0].Duration) != 250*time.Millisecond {
+		t.Errorf("duration = %v, want 250ms", time.Duration(got[0].Duration))
+	}
+	if got[0].Distribution.Kind != "uniform" {
+		t.Errorf("default distribution = %q, want uniform", got[0].Distribution.Kind)
+	}
+	if got[1].Distribution.S != 1.5 || got[1].Containers[0] != "sharded" {
+		t.Errorf("second scenario decoded as %+v", got[1])
+	}
+}
+
+// TestReadScenariosErrors tests that invalid files are rejected with a
+// message naming the problem.
+func TestReadScenariosErrors(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"empty", `{"scenarios": []}`, "no scenarios"},
+		{"unknown field", `{"scenarios": [{"name": "a", "readratio": 1}]}`, "unknown field"},
+		{"bad duration", `{"scenarios": [{"name": "a", "duration": "soon"}]}`, "duration"},
+		{"no stop", `{"scenarios": [{"name": "a", "goroutines": 1}]}`, "ops_per_goroutine"},
+		{"ratio", `{"scenarios": [{"name": "a", "goroutines": 1, "duration": "1s", "read_ratio": 2}]}`, "read_ratio"},
+		{"no prefill", `{"scenarios": [{"name": "a", "goroutines": 1, "duration": "1s", "read_ratio": 0.5}]}`, "prefill"},
+		{"zipf", `{"scenarios": [{"name": "a", "goroutines": 1, "duration": "1s", "distribution": {"kind": "zipf", "s": 1}}]}`, "zipf"},
+		{"kind", `{"scenarios": [{"name": "a", "goroutines": 1, "duration": "1s", "distribution": {"kind": "gauss"}}]}`, "gauss"},
+		{"container", `{"scenarios": [{"name": "a", "goroutines": 1, "duration": "1s", "containers": ["tree"]}]}`, "tree"},
+	}
+	for _, tt := range tests {
+		_, err := ReadScenarios(strings.NewReader(tt.in))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: err = %v, want it to mention %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+// TestLoadExampleScenarios tests that the shipped example file is valid.
+func TestLoadExampleScenarios(t *testing.T) {
+	scenarios, err := LoadScenarios("../scenarios/example.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scenarios) == 0 {
+		t.Error("example file has no scenarios")
+	}
+}